@@ -0,0 +1,134 @@
+// Command catalog imports products into the shop database from CSV or JSON
+// files and exports them back, sharing the logic of the admin API.
+//
+//	catalog import [-format csv|json] [-dry-run] products.csv
+//	catalog export [-format csv|json] [-status published] [-o products.csv]
+package main
+
+import (
+	"OnlineShop/config"
+	"OnlineShop/internal/catalog"
+	"OnlineShop/internal/database"
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gorm.io/gorm/logger"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = runImport(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "catalog:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: catalog import [-format csv|json] [-dry-run] FILE")
+	fmt.Fprintln(os.Stderr, "       catalog export [-format csv|json] [-status STATUS] [-o FILE]")
+	os.Exit(2)
+}
+
+// openDB connects to the database and sends GORM's log to standard error,
+// keeping standard output clean for reports and exported files.
+func openDB() {
+	database.InitDB(config.Load())
+	database.DB.Logger = logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      logger.Warn,
+		Colorful:      false,
+	})
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	formatName := flags.String("format", "", "file format: csv or json (default: from the file extension)")
+	dryRun := flags.Bool("dry-run", false, "validate the file without saving changes")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+	path := flags.Arg(0)
+
+	if *formatName == "" {
+		*formatName = path
+	}
+	format, err := catalog.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := catalog.Parse(file, format)
+	if err != nil {
+		return err
+	}
+
+	openDB()
+	report, err := catalog.Import(database.DB, records, catalog.ImportOptions{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows are invalid, nothing was saved", report.Failed, report.Total)
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	formatName := flags.String("format", "csv", "file format: csv or json")
+	status := flags.String("status", "", "export only products in this status")
+	output := flags.String("o", "", "output file (default: standard output)")
+	flags.Parse(args)
+
+	format, err := catalog.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffered := bufio.NewWriter(w)
+	openDB()
+	if err := catalog.Export(database.DB, buffered, format, catalog.ExportOptions{Status: *status}); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
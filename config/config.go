@@ -4,10 +4,15 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// maxWebhookAttempts bounds WEBHOOK_MAX_ATTEMPTS: with retries a day apart,
+// a delivery is given up on after about seven weeks.
+const maxWebhookAttempts = 50
+
 type Config struct {
 	AppPort      string
 	JWTSecretKey []byte
@@ -20,6 +25,49 @@ type Config struct {
 
 	InitialAdminEmail    string
 	InitialAdminPassword string
+
+	StorageDriver   string
+	StorageLocalDir string
+	StorageBaseURL  string
+	MaxImageSize    int64
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+
+	ShopName     string
+	ShopCompany  string
+	ShopURL      string
+	ShopCurrency string
+
+	PriceSchedulerInterval time.Duration
+
+	TaxPricesIncludeTax bool
+	TaxRounding         string
+
+	PaymentProvider      string
+	PaymentWebhookSecret string
+	PaymentFakeConfirm   bool
+
+	IdempotencyKeyTTL time.Duration
+
+	MailDriver    string
+	MailFrom      string
+	MailOutboxDir string
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+
+	NotificationInterval time.Duration
+
+	WebhookInterval     time.Duration
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
+	WebhookDisableAfter int
 }
 
 func Load() *Config {
@@ -33,6 +81,71 @@ func Load() *Config {
 		log.Fatalf("Invalid DB_PORT: %v", err)
 	}
 
+	maxImageSize, err := strconv.ParseInt(getEnv("MAX_IMAGE_SIZE", "10485760"), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid MAX_IMAGE_SIZE: %v", err)
+	}
+
+	s3UseSSL, err := strconv.ParseBool(getEnv("S3_USE_SSL", "false"))
+	if err != nil {
+		log.Fatalf("Invalid S3_USE_SSL: %v", err)
+	}
+
+	priceSchedulerInterval, err := time.ParseDuration(getEnv("PRICE_SCHEDULER_INTERVAL", "1m"))
+	if err != nil {
+		log.Fatalf("Invalid PRICE_SCHEDULER_INTERVAL: %v", err)
+	}
+
+	taxPricesIncludeTax, err := strconv.ParseBool(getEnv("TAX_PRICES_INCLUDE_TAX", "true"))
+	if err != nil {
+		log.Fatalf("Invalid TAX_PRICES_INCLUDE_TAX: %v", err)
+	}
+
+	taxRounding := getEnv("TAX_ROUNDING", "line")
+	if taxRounding != "line" && taxRounding != "order" {
+		log.Fatalf("Invalid TAX_ROUNDING: %q, expected line or order", taxRounding)
+	}
+
+	paymentFakeConfirm, err := strconv.ParseBool(getEnv("PAYMENT_FAKE_CONFIRM", "false"))
+	if err != nil {
+		log.Fatalf("Invalid PAYMENT_FAKE_CONFIRM: %v", err)
+	}
+
+	idempotencyKeyTTL, err := time.ParseDuration(getEnv("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid IDEMPOTENCY_KEY_TTL: %v", err)
+	}
+
+	smtpPort, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil {
+		log.Fatalf("Invalid SMTP_PORT: %v", err)
+	}
+
+	notificationInterval, err := time.ParseDuration(getEnv("NOTIFICATION_INTERVAL", "10s"))
+	if err != nil {
+		log.Fatalf("Invalid NOTIFICATION_INTERVAL: %v", err)
+	}
+
+	webhookInterval, err := time.ParseDuration(getEnv("WEBHOOK_INTERVAL", "5s"))
+	if err != nil {
+		log.Fatalf("Invalid WEBHOOK_INTERVAL: %v", err)
+	}
+
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		log.Fatalf("Invalid WEBHOOK_TIMEOUT: %v", err)
+	}
+
+	webhookMaxAttempts, err := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "10"))
+	if err != nil || webhookMaxAttempts < 1 || webhookMaxAttempts > maxWebhookAttempts {
+		log.Fatalf("Invalid WEBHOOK_MAX_ATTEMPTS: %q", getEnv("WEBHOOK_MAX_ATTEMPTS", "10"))
+	}
+
+	webhookDisableAfter, err := strconv.Atoi(getEnv("WEBHOOK_DISABLE_AFTER", "20"))
+	if err != nil || webhookDisableAfter < 1 {
+		log.Fatalf("Invalid WEBHOOK_DISABLE_AFTER: %q", getEnv("WEBHOOK_DISABLE_AFTER", "20"))
+	}
+
 	return &Config{
 		AppPort:      getEnv("APP_PORT", "8080"),
 		JWTSecretKey: []byte(getEnv("JWT_SECRET_KEY", "default_secret")),
@@ -45,6 +158,49 @@ func Load() *Config {
 
 		InitialAdminEmail:    getEnv("INITIAL_ADMIN_EMAIL", "admin@shop.com"),
 		InitialAdminPassword: getEnv("INITIAL_ADMIN_PASSWORD", "adminpassword"),
+
+		StorageDriver:   getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalDir: getEnv("STORAGE_LOCAL_DIR", "uploads"),
+		StorageBaseURL:  getEnv("STORAGE_BASE_URL", ""),
+		MaxImageSize:    maxImageSize,
+
+		S3Endpoint:  getEnv("S3_ENDPOINT", "localhost:9000"),
+		S3Region:    getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:    getEnv("S3_BUCKET", "shop-images"),
+		S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:    s3UseSSL,
+
+		ShopName:     getEnv("SHOP_NAME", "OnlineShop"),
+		ShopCompany:  getEnv("SHOP_COMPANY", "OnlineShop"),
+		ShopURL:      getEnv("SHOP_URL", "http://localhost:8080"),
+		ShopCurrency: getEnv("SHOP_CURRENCY", "RUB"),
+
+		PriceSchedulerInterval: priceSchedulerInterval,
+
+		TaxPricesIncludeTax: taxPricesIncludeTax,
+		TaxRounding:         taxRounding,
+
+		PaymentProvider:      getEnv("PAYMENT_PROVIDER", ""),
+		PaymentWebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentFakeConfirm:   paymentFakeConfirm,
+
+		IdempotencyKeyTTL: idempotencyKeyTTL,
+
+		MailDriver:    getEnv("MAIL_DRIVER", "file"),
+		MailFrom:      getEnv("MAIL_FROM", "OnlineShop <noreply@localhost>"),
+		MailOutboxDir: getEnv("MAIL_OUTBOX_DIR", "outbox"),
+		SMTPHost:      getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:      smtpPort,
+		SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+
+		NotificationInterval: notificationInterval,
+
+		WebhookInterval:     webhookInterval,
+		WebhookTimeout:      webhookTimeout,
+		WebhookMaxAttempts:  webhookMaxAttempts,
+		WebhookDisableAfter: webhookDisableAfter,
 	}
 }
 
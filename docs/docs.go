@@ -15,94 +15,88 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/orders": {
+        "/audit": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает все заказы, сделанные аутентифицированным пользователем, с полной информацией о товарах.",
+                "description": "Возвращает записи журнала действий администраторов и событий безопасности, начиная с новых.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Заказы (Orders)"
+                    "Администрирование (Admin)"
                 ],
-                "summary": "Получить список заказов пользователя",
-                "responses": {
-                    "200": {
-                        "description": "Массив заказов пользователя",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/database.Order"
-                            }
-                        }
+                "summary": "Получить журнал аудита",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя, выполнившего действие",
+                        "name": "actor_id",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "Ошибка аутентификации",
-                        "schema": {
-                            "$ref": "#/definitions/router.HTTPError"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Действие, например product.update",
+                        "name": "action",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
-                        "schema": {
-                            "$ref": "#/definitions/router.HTTPError"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "security": [
                     {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Создает новый заказ для аутентифицированного пользователя. Требует список ID товаров и их количество.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Заказы (Orders)"
-                ],
-                "summary": "Создать новый заказ",
-                "parameters": [
+                        "type": "string",
+                        "description": "Тип сущности, например product",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
                     {
-                        "description": "Данные для создания нового заказа",
-                        "name": "order",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/router.CreateOrderInput"
-                        }
+                        "type": "string",
+                        "description": "ID сущности",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "С даты (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "По дату (YYYY-MM-DD), включительно",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Возвращает созданный заказ со всеми позициями",
+                    "200": {
+                        "description": "Страница журнала аудита",
                         "schema": {
-                            "$ref": "#/definitions/database.Order"
+                            "$ref": "#/definitions/router.Page-database_AuditLog"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации входных данных",
-                        "schema": {
-                            "$ref": "#/definitions/router.HTTPError"
-                        }
-                    },
-                    "401": {
-                        "description": "Ошибка аутентификации",
+                        "description": "Некорректные параметры запроса",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Один или несколько товаров не найдены",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
@@ -116,29 +110,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/pending": {
+        "/audit/verify": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список всех заказов в статусе \"Pending\". Доступно только для администраторов.",
+                "description": "Пересчитывает цепочку хешей журнала аудита и возвращает ID первой измененной или удаленной записи, если такая есть.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Администрирование (Admin)"
                 ],
-                "summary": "Получить список всех незавершенных заказов",
+                "summary": "Проверить целостность журнала аудита",
                 "responses": {
                     "200": {
-                        "description": "Список незавершенных заказов",
+                        "description": "Результат проверки",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/database.Order"
-                            }
+                            "$ref": "#/definitions/database.AuditVerification"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
                         }
                     },
                     "500": {
@@ -150,25 +147,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
+        "/categories": {
             "get": {
-                "description": "Возвращает массив всех товаров, доступных в магазине",
+                "description": "Возвращает все категории товаров. Дерево категорий строится по полю ParentID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Товары (Products)"
+                    "Категории (Categories)"
                 ],
-                "summary": "Получить список всех товаров",
+                "summary": "Получить список категорий",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/database.Product"
+                                "$ref": "#/definitions/database.Category"
                             }
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
                     }
                 }
             },
@@ -178,7 +181,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Добавляет новый товар в базу данных. ID в теле запроса игнорируется.",
+                "description": "Создает категорию товаров, при необходимости вложенную в родительскую.",
                 "consumes": [
                     "application/json"
                 ],
@@ -186,17 +189,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Товары (Products)"
+                    "Категории (Categories)"
                 ],
-                "summary": "Создать новый товар",
+                "summary": "Создать категорию",
                 "parameters": [
                     {
-                        "description": "Данные для создания нового товара",
-                        "name": "product",
+                        "description": "Данные категории",
+                        "name": "category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/database.Product"
+                            "$ref": "#/definitions/router.CategoryInput"
                         }
                     }
                 ],
@@ -204,7 +207,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/database.Product"
+                            "$ref": "#/definitions/database.Category"
                         }
                     },
                     "400": {
@@ -212,51 +215,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
-                    }
-                }
-            }
-        },
-        "/products/{id}": {
-            "get": {
-                "description": "Получает информацию о конкретном товаре по его ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Товары (Products)"
-                ],
-                "summary": "Получить товар по ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID Товара",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/database.Product"
+                            "$ref": "#/definitions/router.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/categories/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Полностью обновляет информацию о товаре с указанным ID",
+                "description": "Переименовывает категорию или переносит ее в другую родительскую категорию.",
                 "consumes": [
                     "application/json"
                 ],
@@ -264,24 +246,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Товары (Products)"
+                    "Категории (Categories)"
                 ],
-                "summary": "Обновить существующий товар",
+                "summary": "Обновить категорию",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Товара для обновления",
+                        "description": "ID категории",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Новые данные для товара",
-                        "name": "product",
+                        "description": "Данные категории",
+                        "name": "category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/router.UpdateProductInput"
+                            "$ref": "#/definitions/router.CategoryInput"
                         }
                     }
                 ],
@@ -289,11 +271,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/database.Product"
+                            "$ref": "#/definitions/database.Category"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Некорректные данные или циклическая вложенность",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
@@ -303,6 +291,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
                     }
                 }
             },
@@ -312,18 +306,18 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет товар из базы данных по его ID",
+                "description": "Удаляет категорию, в которой нет подкатегорий и товаров (включая товары в корзине) и которой не ограничены\nкупоны и акции.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Товары (Products)"
+                    "Категории (Categories)"
                 ],
-                "summary": "Удалить товар",
+                "summary": "Удалить категорию",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Товара для удаления",
+                        "description": "ID категории",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -336,12 +330,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/router.SuccessMessage"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "В категории есть подкатегории или товары, или ею ограничены купоны и акции",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -351,40 +357,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/login": {
-            "post": {
-                "description": "Проверяет учетные данные и в случае успеха возвращает JWT токен.",
-                "consumes": [
-                    "application/json"
+        "/coupons": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает постраничный список купонов с поиском по коду и фильтром по активности.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Пользователи (Auth)"
+                    "Купоны (Coupons)"
                 ],
-                "summary": "Вход пользователя в систему",
+                "summary": "Получить список купонов",
                 "parameters": [
                     {
-                        "description": "Учетные данные для входа",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/router.LoginInput"
-                        }
+                        "type": "string",
+                        "description": "Часть кода купона",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только активные или только отключенные",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT токен",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "token": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/router.Page-database_Coupon"
                         }
                     },
                     "400": {
@@ -393,55 +413,27 @@ const docTemplate = `{
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/router.HTTPError"
-                        }
-                    }
-                }
-            }
-        },
-        "/users/me": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Возвращает данные пользователя, аутентифицированного с помощью JWT токена.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Пользователи (Auth)"
-                ],
-                "summary": "Получить информацию о текущем пользователе",
-                "responses": {
-                    "200": {
-                        "description": "Данные текущего пользователя",
-                        "schema": {
-                            "$ref": "#/definitions/database.Customer"
-                        }
-                    },
-                    "401": {
-                        "description": "Ошибка аутентификации",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Пользователь из токена не найден в БД",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     }
                 }
-            }
-        },
-        "/users/register": {
+            },
             "post": {
-                "description": "Создает новый аккаунт пользователя с email и паролем.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает купон со скидкой в процентах (percent) или фиксированной суммой (fixed).\nКод купона не зависит от регистра. Если указаны product_ids или category_ids, скидка действует только на эти товары и категории.\nНезаданные лимиты использования не ограничены.",
                 "consumes": [
                     "application/json"
                 ],
@@ -449,41 +441,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Пользователи (Auth)"
+                    "Купоны (Coupons)"
                 ],
-                "summary": "Регистрация нового пользователя",
+                "summary": "Создать купон",
                 "parameters": [
                     {
-                        "description": "Данные для регистрации",
-                        "name": "input",
+                        "description": "Данные купона",
+                        "name": "coupon",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/router.LoginInput"
+                            "$ref": "#/definitions/router.CouponInput"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Возвращает созданного пользователя",
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/database.Customer"
+                            "$ref": "#/definitions/database.Coupon"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации входных данных",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
                     "409": {
-                        "description": "Пользователь с таким email уже существует",
+                        "description": "Купон с таким кодом уже существует",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
@@ -491,25 +489,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}/promote": {
-            "post": {
+        "/coupons/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Позволяет администратору назначить другого пользователя администратором.",
+                "description": "Возвращает купон с ограничениями по товарам и категориям и числом использований.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Администрирование (Admin)"
+                    "Купоны (Coupons)"
                 ],
-                "summary": "Повысить пользователя до администратора",
+                "summary": "Получить купон",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID пользователя, которого нужно повысить",
+                        "description": "ID купона",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -517,211 +515,9948 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Сообщение об успешном повышении",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/router.SuccessMessage"
+                            "$ref": "#/definitions/database.Coupon"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью заменяет условия купона. Счетчик использований не меняется.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Купоны (Coupons)"
+                ],
+                "summary": "Обновить купон",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID купона",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные купона",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.CouponInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Coupon"
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID пользователя",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
                     "403": {
-                        "description": "Попытка повысить самого себя",
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "Пользователь не найден",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
                     },
                     "409": {
-                        "description": "Пользователь уже является администратором",
+                        "description": "Купон с таким кодом уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет купон, который еще ни разу не использовался. Использованные купоны можно только отключить.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Купоны (Coupons)"
+                ],
+                "summary": "Удалить купон",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID купона",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Купон уже использовался",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/router.HTTPError"
                         }
+                    }
+                }
+            }
+        },
+        "/feeds/google.xml": {
+            "get": {
+                "description": "Каталог опубликованных товаров в формате RSS 2.0 для Google Merchant Center.\nФид кешируется и формируется заново после изменения товаров или категорий.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "Фиды (Feeds)"
+                ],
+                "summary": "Фид для Google Merchant Center",
+                "responses": {
+                    "200": {
+                        "description": "RSS-документ",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/yandex.yml": {
+            "get": {
+                "description": "Каталог опубликованных товаров в формате YML с категориями, ценами, наличием и изображениями.\nФид кешируется и формируется заново после изменения товаров или категорий.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "Фиды (Feeds)"
+                ],
+                "summary": "Фид для Яндекс Маркета",
+                "responses": {
+                    "200": {
+                        "description": "YML-документ",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный список заказов аутентифицированного пользователя, начиная с последних,\nс полной информацией о товарах. Заказы можно отфильтровать по статусу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Заказы (Orders)"
+                ],
+                "summary": "Получить список заказов пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус заказа (Pending, Paid, Shipped, Delivered, Refunded, Cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница заказов пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает новый заказ для аутентифицированного пользователя. Требует список ID товаров и их количество.\nДля товаров с вариантами необходимо указать variant_id; остаток варианта резервируется.\nЕсли адрес доставки не указан, используется адрес доставки по умолчанию. Адреса копируются в заказ.\nК заказу автоматически применяются действующие акции, затем купон (coupon_code), если он указан.\nСкидки сохраняются в позициях заказа (Discount и Adjustments) и в поле DiscountTotal заказа.\nНалог рассчитывается по ставкам региона доставки и сохраняется в позициях (TaxRate, Tax) и в поле TaxTotal заказа.\nЕсли цены включают налог (PricesIncludeTax), налог уже входит в Total, иначе добавляется к нему.\nЕсли в магазине есть активные способы доставки, shipping_method_id обязателен; стоимость доставки\nсохраняется в поле ShippingTotal и добавляется к Total. Доступные способы возвращает POST /shipping/quote.\nПокупателю отправляется письмо с подтверждением заказа, если он не отключил такие уведомления.\nПовтор запроса с тем же заголовком Idempotency-Key не создает новый заказ, а возвращает ответ на первый запрос\n(с заголовком Idempotent-Replayed: true). Ключ действует IDEMPOTENCY_KEY_TTL; с другим телом запроса его использовать нельзя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Заказы (Orders)"
+                ],
+                "summary": "Создать новый заказ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Уникальный ключ запроса для безопасных повторов",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные для создания нового заказа",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.CreateOrderInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Возвращает созданный заказ со всеми позициями",
+                        "schema": {
+                            "$ref": "#/definitions/database.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации входных данных, не выбран вариант товара или способ доставки, купон недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Один или несколько товаров или адрес не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Недостаточно товара на складе, исчерпан лимит использования купона или запрос с тем же Idempotency-Key еще выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key уже использован с другим запросом",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/all": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный список всех заказов с покупателями. Заказы можно отфильтровать по статусу, части email\nпокупателя, дате оформления, сумме и товару в заказе и отсортировать по дате или сумме (с \"-\" — по убыванию).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Получить список заказов магазина",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус заказа (Pending, Paid, Shipped, Delivered, Refunded, Cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часть email покупателя",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата оформления с (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата оформления по (YYYY-MM-DD), включительно",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Минимальная сумма заказа",
+                        "name": "min_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Максимальная сумма заказа",
+                        "name": "max_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID товара в заказе",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-order_date",
+                        "description": "Сортировка (order_date, -order_date, total, -total)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница заказов",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/preview": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Рассчитывает цены, скидки по акциям и купону для набора товаров так же, как при создании заказа, но не создает заказ,\nне резервирует товар и не расходует купон. Для каждой позиции указано, какие акции и купон к ней применены.\nНалог и доступные способы доставки (shipping_options) рассчитываются по адресу доставки (shipping_address_id или адрес по умолчанию);\nбез адреса они не считаются. Если указан shipping_method_id, стоимость доставки добавляется к итогу.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Заказы (Orders)"
+                ],
+                "summary": "Рассчитать стоимость корзины",
+                "parameters": [
+                    {
+                        "description": "Товары и код купона",
+                        "name": "basket",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.PreviewOrderInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Расчет стоимости",
+                        "schema": {
+                            "$ref": "#/definitions/pricing.Quote"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации входных данных, купон недействителен или способ доставки недоступен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Один или несколько товаров или адрес не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Недостаточно товара на складе или исчерпан лимит использования купона",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает заказ с покупателем, позициями, платежами, отправлениями и историей статусов. Покупателю доступны только\nего собственные заказы, чужие для него не существуют (404); сотрудникам с правом orders.view — любые.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Заказы (Orders)"
+                ],
+                "summary": "Получить заказ по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заказ",
+                        "schema": {
+                            "$ref": "#/definitions/database.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID заказа",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отменяет заказ, ожидающий оплаты (Pending): зарезервированный товар возвращается на склад, использование купона\nотменяется. Если покупатель все же завершит начатый платеж, платеж помечается как требующий возврата (NeedsRefund).\nПокупатель может отменить свой заказ, сотрудник с правом orders.manage — любой.\nПокупателю отправляется письмо об отмене. Оплаченный заказ отменить нельзя — по нему оформляется возврат.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Заказы (Orders)"
+                ],
+                "summary": "Отменить заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отмененный заказ",
+                        "schema": {
+                            "$ref": "#/definitions/database.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID заказа",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заказ уже оплачен или отменен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/invoice": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает счет, выставленный на заказ при его оплате. Счет нумеруется последовательно в пределах года и после\nвыставления не меняется. Покупателю доступны только счета по его заказам, сотрудникам с правом orders.view — любые.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Счета (Invoices)"
+                ],
+                "summary": "Получить счет по заказу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Invoice"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID заказа",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден или счет еще не выставлен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выставляет счет на оплаченный заказ, у которого его еще нет, например на заказ, оплаченный до появления счетов.\nЕсли счет уже выставлен, возвращает его без изменений.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Счета (Invoices)"
+                ],
+                "summary": "Выставить счет по заказу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Invoice"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID заказа",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заказ не оплачен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/invoice.html": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает счет по заказу в виде HTML-страницы для просмотра и печати.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Счета (Invoices)"
+                ],
+                "summary": "Получить счет в HTML",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML-страница счета",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID заказа",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден или счет еще не выставлен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/invoice.pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает счет по заказу в виде PDF-документа.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Счета (Invoices)"
+                ],
+                "summary": "Скачать счет в PDF",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID заказа",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден или счет еще не выставлен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/payments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает платеж на сумму заказа у платежного провайдера и возвращает его. Для оплаты покупателя нужно\nперенаправить по адресу ConfirmationURL. Заказ становится оплаченным (Paid), когда провайдер сообщит об успешной оплате.\nОплатить можно только заказ в статусе Pending. У заказа может быть только один незавершенный платеж: если он уже есть,\nвозвращается он (200), чтобы покупатель не оплатил заказ дважды; после неудачного платежа можно создать новый.\nПовтор запроса с тем же заголовком Idempotency-Key возвращает уже созданный платеж.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Платежи (Payments)"
+                ],
+                "summary": "Оплатить заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Уникальный ключ запроса для безопасных повторов",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Незавершенный платеж заказа",
+                        "schema": {
+                            "$ref": "#/definitions/database.Payment"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Payment"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заказ не ожидает оплаты, платеж еще создается или запрос с тем же Idempotency-Key еще выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key уже использован с другим запросом",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "Платежный провайдер недоступен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/refunds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все возвраты денег по заказу с позициями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Получить возвраты денег по заказу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Refund"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает деньги за позиции заказа и/или доставку через платежного провайдера, которым заказ был оплачен.\nДля позиции указывается количество единиц; без amount возвращается уплаченная за них сумма (с учетом скидок и налога),\nс amount — указанная сумма (частичный возврат, например компенсация), при этом quantity может быть 0.\nНельзя вернуть больше, чем было уплачено и еще не возвращено. Возвращенные суммы отражаются в RefundedTotal заказа\nи RefundedQuantity/RefundedAmount позиций; при полном возврате заказ переходит в статус Refunded. Если после возврата\nвсе оставшиеся единицы уже отправлены или доставлены, заказ переходит в статус Shipped или Delivered.\nПовтор запроса с тем же заголовком Idempotency-Key не возвращает деньги повторно, а возвращает первый ответ.\nВозврат сохраняется (статус pending) до обращения к провайдеру и завершается (completed) после выплаты; если магазин\nне успел записать выплату, возврат повторно отправляется провайдеру в фоне без двойной выплаты. Пока возврат заказа\nобрабатывается, новый возврат по этому заказу не создается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Вернуть деньги за заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Уникальный ключ запроса для безопасных повторов",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Позиции и суммы возврата",
+                        "name": "refund",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.RefundInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Refund"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации, некорректный ID или сумма превышает доступную к возврату",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заказ не оплачен, по нему уже выполняется возврат или запрос с тем же Idempotency-Key еще выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key уже использован с другим запросом",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "Платежный провайдер не выполнил возврат",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/returns": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает заявки на возврат по заказу аутентифицированного пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Получить заявки на возврат по заказу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ReturnRequest"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает заявку на возврат позиций доставленного заказа (статус Delivered) с указанием причины.\nНельзя вернуть больше единиц, чем было в заказе, с учетом других заявок, кроме отклоненных.\nЗаявка рассматривается магазином; после получения товара деньги возвращаются через платежного провайдера.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Оформить возврат товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Позиции и причина возврата",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.CreateReturnInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.ReturnRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации или количество превышает доступное к возврату",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заказ еще не доставлен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/shipments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает отправления заказа с позициями, статусами, перевозчиком и трек-номером для отслеживания посылок.\nПокупателю доступны только его собственные заказы, сотрудникам с правом orders.view — любые.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Fulfilment)"
+                ],
+                "summary": "Отследить заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Shipment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID заказа",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает отправление (посылку) с позициями оплаченного заказа. Заказ можно разбить на несколько отправлений;\nбез items в отправление попадают все еще не отправленные единицы. Возвращенные покупателю единицы не отправляются.\nОтправление создается в статусе pending — для него можно распечатать упаковочный лист — и отправляется через POST /shipments/{id}/ship.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Fulfilment)"
+                ],
+                "summary": "Создать отправление",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Позиции, перевозчик и трек-номер",
+                        "name": "shipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.CreateShipmentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Shipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации или количество превышает не отправленное",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Заказ не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заказ не оплачен или уже отправлен полностью",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный список платежей, новые первыми, с фильтрами по статусу и заказу. С needs_refund=true\nвозвращаются успешные платежи, которые не оплатили заказ (например, второй платеж за уже оплаченный или отмененный заказ):\nденьги по ним нужно вернуть покупателю.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Платежи (Payments)"
+                ],
+                "summary": "Получить платежи",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed",
+                            "canceled",
+                            "refunded"
+                        ],
+                        "type": "string",
+                        "description": "Статус платежа",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только платежи, требующие возврата",
+                        "name": "needs_refund",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/fake/{externalId}": {
+            "post": {
+                "description": "Страница оплаты встроенного тестового провайдера (PAYMENT_PROVIDER=fake): завершает платеж с указанным статусом,\nотправляя подписанное уведомление так же, как это сделал бы настоящий провайдер. Доступно только с тестовым провайдером\nи только при PAYMENT_FAKE_CONFIRM=true: эндпоинт позволяет оплатить заказ без денег и предназначен для разработки и тестов.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Платежи (Payments)"
+                ],
+                "summary": "Подтвердить тестовый платеж",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID платежа у провайдера",
+                        "name": "externalId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Результат оплаты",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.FakePaymentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Платеж не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/webhook": {
+            "post": {
+                "description": "Принимает уведомление провайдера об изменении статуса платежа. Тело должно быть подписано HMAC-SHA256\nсекретом PAYMENT_WEBHOOK_SECRET (заголовок X-Signature: sha256=\u003chex\u003e). При успешной оплате заказ становится оплаченным (Paid).\nПовторные уведомления по уже завершенному платежу подтверждаются без изменений. На оплаченный заказ выставляется счет,\nпокупателю отправляется письмо об оплате. Успешный платеж за заказ, который уже не ожидает оплаты, не меняет заказ\nи помечается как требующий возврата (NeedsRefund).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Платежи (Payments)"
+                ],
+                "summary": "Webhook платежного провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подпись тела запроса",
+                        "name": "X-Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректное уведомление",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверная подпись",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Платеж не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{id}/refund": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает покупателю всю сумму успешного платежа, помеченного как требующий возврата (NeedsRefund), через провайдера,\nи переводит платеж в статус refunded. Провайдер выплачивает возврат по платежу один раз, поэтому запрос можно безопасно повторить.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Платежи (Payments)"
+                ],
+                "summary": "Вернуть платеж, не оплативший заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID платежа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Платеж не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Платеж не требует возврата или принят другим провайдером",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "Платежный провайдер не выполнил возврат",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Возвращает массив всех опубликованных товаров, доступных в магазине",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Получить список всех товаров",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Product"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет новый товар в базу данных. Если статус не указан, товар создается как черновик и не виден в каталоге.\nВес (weight) указывается в килограммах и используется для расчета стоимости доставки.\nЕсли slug не указан, он формируется из названия.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Создать новый товар",
+                "parameters": [
+                    {
+                        "description": "Данные для создания нового товара",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ProductInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU или slug уже используется",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/all": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный список товаров в любом статусе, включая черновики и архивные.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Получить товары для администрирования",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус (draft, published, archived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выгружает все товары, кроме удаленных, в CSV или JSON в том же формате, который принимает импорт.\nОтвет передается потоком, поэтому подходит для больших каталогов.",
+                "produces": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Экспортировать товары",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат файла (csv, json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус (draft, published, archived)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/catalog.Row"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "CSV должен содержать заголовок с колонками sku, name, price и, по желанию, description, slug, status, attributes (JSON-объект). Цена обязательна в каждой строке, нулевую цену нужно указать явно.\nCSV должен содержать заголовок с колонками sku, name, price и, по желанию, description, slug, status, attributes (JSON-объект).\nИмпорт выполняется в одной транзакции: если хотя бы одна строка содержит ошибку, ничего не сохраняется и возвращается отчет об ошибках по строкам.\nС параметром dry_run файл только проверяется.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Импортировать товары",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Файл с товарами",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат файла (csv, json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только проверить файл, не сохраняя изменения",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отчет об импорте",
+                        "schema": {
+                            "$ref": "#/definitions/catalog.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Файл не передан или не читается",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Файл слишком большой",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Отчет с ошибками, изменения не сохранены",
+                        "schema": {
+                            "$ref": "#/definitions/catalog.Report"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/search": {
+            "get": {
+                "description": "Полнотекстовый поиск по названию и описанию товара с учетом морфологии русского и английского языков.\nПоследнее слово может быть введено не полностью, что позволяет использовать поиск для автодополнения.\nРезультаты отсортированы по релевантности, фрагмент (snippet) — HTML: текст товара экранирован,\nнайденные слова выделены тегом \u003cb\u003e.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Поиск товаров",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Максимальное число результатов",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ProductSearchHit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/slug/{slug}": {
+            "get": {
+                "description": "Получает информацию об опубликованном товаре по его адресу (slug)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Получить товар по slug",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug товара",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает удаленные товары, которые можно восстановить или удалить окончательно.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Получить корзину товаров",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Product"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Получает информацию о конкретном опубликованном товаре по его ID\nВо время распродажи Price содержит текущую цену, а CompareAtPrice — цену до скидки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Получить товар по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью обновляет информацию о товаре с указанным ID. Если статус не указан, он не меняется.\nИзменение цены записывается в историю цен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Обновить существующий товар",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара для обновления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные для товара",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ProductInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU или slug уже используется",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перемещает товар в корзину: он пропадает из каталога, но остается доступен в истории заказов и может быть восстановлен.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Удалить товар",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара для удаления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/images": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Задает порядок изображений. Список должен содержать каждое изображение товара ровно один раз.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Изменить порядок изображений товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID изображений в новом порядке",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ImageOrderInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ProductImage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает изображение (JPEG, PNG, GIF или WebP) и создает для него миниатюру.\nТип файла определяется по содержимому. Новое изображение добавляется в конец списка.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Загрузить изображение товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл изображения",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.ProductImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Файл не передан",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "413": {
+                        "description": "Файл или изображение слишком большие",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "415": {
+                        "description": "Неподдерживаемый формат",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/images/{imageId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет изображение и его миниатюру из хранилища.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Удалить изображение товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Изображения",
+                        "name": "imageId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/options": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью заменяет список опций товара. Запрос отклоняется, если существующие варианты перестанут им соответствовать.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Задать опции товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Опции товара",
+                        "name": "options",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ProductOptionsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ProductOption"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные опции или варианты им не соответствуют",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Варианты совпадают после изменения опций",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/price-schedules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все запланированные изменения цены товара, включая завершенные и отмененные.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Запланированные цены товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ScheduledPrice"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Планирует новую цену товара с момента starts_at. Если указан ends_at, это распродажа:\nна время ее действия прежняя цена показывается в CompareAtPrice, а после окончания восстанавливается, если цену не изменили вручную во время распродажи.\nБез ends_at цена меняется навсегда. Периоды запланированных цен одного товара не должны пересекаться.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Запланировать изменение цены",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Цена и период действия",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ScheduledPriceInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.ScheduledPrice"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Период пересекается с другой запланированной ценой",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/price-schedules/{scheduleId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отменяет запланированное изменение цены. Если распродажа уже идет, она завершается досрочно и товару возвращается прежняя цена.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Отменить запланированную цену",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID запланированной цены",
+                        "name": "scheduleId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Запланированная цена уже применена или отменена",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/prices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает изменения цены товара от новых к старым: кто и когда изменил цену и каким способом (manual, import, schedule).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "История цен товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_PriceChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/purge": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Безвозвратно удаляет товар из корзины вместе с вариантами и изображениями. Запрещено, пока на товар ссылаются заказы\nили им ограничены купоны и акции: их нужно сначала изменить, иначе они стали бы действовать на все товары.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Удалить товар окончательно",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Товар не найден в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Товар присутствует в заказах, купонах или акциях",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает товар из корзины в каталог.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Восстановить товар",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Product"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Товар не найден в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/variants": {
+            "get": {
+                "description": "Возвращает опции (например, размер и цвет) и варианты опубликованного товара с ценой и остатком.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Получить варианты товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Товар с опциями и вариантами",
+                        "schema": {
+                            "$ref": "#/definitions/database.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает вариант товара с собственными SKU, ценой и остатком. Значения опций должны соответствовать опциям товара.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Добавить вариант товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные варианта",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.VariantInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Variant"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные или опции",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU занят или вариант с такими опциями уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/variants/{variantId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью обновляет SKU, цену, остаток и опции варианта.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Обновить вариант товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Варианта",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные варианта",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.VariantInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Variant"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные или опции",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU занят или вариант с такими опциями уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет вариант из продажи. Вариант остается доступен в истории заказов.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Товары (Products)"
+                ],
+                "summary": "Удалить вариант товара",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Товара",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Варианта",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный список акций в порядке их применения: по убыванию приоритета, затем по ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Акции (Promotions)"
+                ],
+                "summary": "Получить список акций",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только активные или только отключенные",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает акцию, которая автоматически применяется при оформлении заказа. Типы акций:\nbuy_x_get_y — из каждых buy_quantity + get_quantity товаров get_quantity самых дешевых получают скидку percent (по умолчанию 100%, то есть бесплатно);\ntiered — скидка percent наивысшего из уровней tiers, чей порог min_subtotal достигнут;\nbundle — каждые bundle_quantity товаров (начиная с самых дорогих) продаются за bundle_price.\nАкции применяются по убыванию priority, затем по ID, каждая к ценам после предыдущих.\nЭксклюзивная (exclusive) акция применяется, только если до нее не сработала ни одна акция, и отменяет следующие за ней. Купон применяется после всех акций.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Акции (Promotions)"
+                ],
+                "summary": "Создать акцию",
+                "parameters": [
+                    {
+                        "description": "Данные акции",
+                        "name": "promotion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.PromotionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает акцию с ограничениями по товарам и категориям.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Акции (Promotions)"
+                ],
+                "summary": "Получить акцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID акции",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Promotion"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью заменяет условия акции. Уже оформленные заказы не пересчитываются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Акции (Promotions)"
+                ],
+                "summary": "Обновить акцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID акции",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные акции",
+                        "name": "promotion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.PromotionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет акцию, которая еще не применялась к заказам. Примененные акции можно только отключить.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Акции (Promotions)"
+                ],
+                "summary": "Удалить акцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID акции",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Акция уже применялась к заказам",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/returns": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный список заявок на возврат всех покупателей, новые первыми, с фильтром по статусу.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Получить заявки на возврат",
+                "parameters": [
+                    {
+                        "enum": [
+                            "requested",
+                            "approved",
+                            "rejected",
+                            "received",
+                            "refunded"
+                        ],
+                        "type": "string",
+                        "description": "Статус заявки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_ReturnRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/returns/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает заявку на возврат с позициями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Получить заявку на возврат",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ReturnRequest"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/returns/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Одобряет заявку в статусе requested. В note можно указать инструкции для покупателя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Одобрить заявку на возврат",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий магазина",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ReturnDecisionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ReturnRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заявка уже рассмотрена",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/returns/{id}/receive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмечает, что товар по одобренной заявке получен, и возвращает единицы вариантов товаров на склад.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Принять возвращенный товар",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ReturnRequest"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заявка не одобрена или товар уже получен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/returns/{id}/refund": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает деньги за полученные по заявке позиции (уплаченную за них сумму) и, если указано, за доставку.\nДоступно для заявок в статусе received; заявка переходит в статус refunded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Вернуть деньги по заявке на возврат",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Сумма возврата за доставку",
+                        "name": "refund",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.RefundReturnInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ReturnRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации или сумма превышает доступную к возврату",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Товар по заявке не получен, заказ не оплачен или по нему уже выполняется возврат",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "502": {
+                        "description": "Платежный провайдер не выполнил возврат",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/returns/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отклоняет заявку в статусе requested. Позиции отклоненной заявки можно вернуть новой заявкой.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Возвраты (Returns)"
+                ],
+                "summary": "Отклонить заявку на возврат",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отказа",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ReturnDecisionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ReturnRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заявка уже рассмотрена",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все роли сотрудников вместе с их разрешениями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Получить список ролей",
+                "responses": {
+                    "200": {
+                        "description": "Список ролей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Role"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipments/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет еще не отправленное отправление; его единицы снова можно включить в другое отправление.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Fulfilment)"
+                ],
+                "summary": "Отменить отправление",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отправления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Отправление не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Отправление уже отправлено",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipments/{id}/deliver": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмечает, что покупатель получил отправление. Когда доставлены все отправления полностью отправленного заказа,\nзаказ переходит в статус Delivered, после чего по нему можно оформить возврат.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Fulfilment)"
+                ],
+                "summary": "Отметить отправление доставленным",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отправления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Shipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Отправление не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Отправление еще не отправлено или уже доставлено",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipments/{id}/packing-slip": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает HTML-страницу для печати со списком позиций отправления и адресом получателя, без цен.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Доставка (Fulfilment)"
+                ],
+                "summary": "Получить упаковочный лист",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отправления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML-страница упаковочного листа",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Отправление не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipments/{id}/ship": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмечает, что отправление передано перевозчику. Перевозчик и трек-номер можно указать здесь, если они не были\nуказаны при создании; пустые поля оставляют прежние значения.\nКогда отправлены все единицы заказа, заказ переходит в статус Shipped. Покупателю отправляется письмо\nс составом отправления и трек-номером.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Fulfilment)"
+                ],
+                "summary": "Отправить отправление",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отправления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Перевозчик и трек-номер",
+                        "name": "shipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShipShipmentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Shipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Отправление не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Отправление уже отправлено",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipping-methods": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все способы доставки, включая неактивные, в порядке position.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Получить способы доставки",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ShippingMethod"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает способ доставки: курьер (courier), пункт выдачи (pickup) или почта (post).\nСтоимость доставки задается тарифами способа в зонах доставки. Если active не указан, способ создается активным.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Создать способ доставки",
+                "parameters": [
+                    {
+                        "description": "Данные способа доставки",
+                        "name": "method",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShippingMethodInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.ShippingMethod"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipping-methods/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет способ доставки. Если active не указан, активность не меняется. Уже оформленные заказы не пересчитываются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Обновить способ доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID способа доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные способа доставки",
+                        "name": "method",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShippingMethodInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ShippingMethod"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет способ доставки вместе с его тарифами. В оформленных заказах сохраняется название способа.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Удалить способ доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID способа доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipping-rates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает тарифы доставки с фильтрами по способу и зоне.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Получить тарифы доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID способа доставки",
+                        "name": "method_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID зоны доставки",
+                        "name": "zone_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ShippingRate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает тариф способа доставки в зоне для заказов весом от min_weight (включительно) до max_weight (не включительно) кг.\nСтоимость равна price плюс price_per_kg за каждый начатый килограмм сверх min_weight. Заказы на сумму от free_from доставляются бесплатно.\nЕсли для веса заказа подходят несколько тарифов способа, применяется самый дешевый.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Создать тариф доставки",
+                "parameters": [
+                    {
+                        "description": "Данные тарифа доставки",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShippingRateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.ShippingRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации, способ или зона доставки не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipping-rates/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет тариф доставки. Уже оформленные заказы не пересчитываются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Обновить тариф доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID тарифа доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные тарифа доставки",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShippingRateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ShippingRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации, способ или зона доставки не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет тариф доставки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Удалить тариф доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID тарифа доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipping-zones": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все зоны доставки с их странами и регионами.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Получить зоны доставки",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ShippingZone"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает зону доставки из стран и регионов. Локация без региона охватывает всю страну; регион сравнивается\nс регионом адреса без учета регистра, и зона региона имеет приоритет над зоной страны. Локация может входить только в одну зону.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Создать зону доставки",
+                "parameters": [
+                    {
+                        "description": "Данные зоны доставки",
+                        "name": "zone",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShippingZoneInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.ShippingZone"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Название зоны занято или локация входит в другую зону",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipping-zones/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переименовывает зону доставки и заменяет список ее локаций.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Обновить зону доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID зоны доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные зоны доставки",
+                        "name": "zone",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShippingZoneInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ShippingZone"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Название зоны занято или локация входит в другую зону",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет зону доставки вместе с ее локациями и тарифами.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Удалить зону доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID зоны доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipping/quote": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает способы доставки, доступные для корзины по адресу доставки (shipping_address_id или адрес по умолчанию),\nи их стоимость с учетом веса товаров и суммы заказа после скидок. Пустой список означает, что доставка по адресу невозможна.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Доставка (Shipping)"
+                ],
+                "summary": "Рассчитать стоимость доставки",
+                "parameters": [
+                    {
+                        "description": "Товары, адрес доставки и код купона",
+                        "name": "basket",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ShippingQuoteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/pricing.ShippingOption"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации входных данных, не указан адрес или купон недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Один или несколько товаров или адрес не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Недостаточно товара на складе или исчерпан лимит использования купона",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-classes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все налоговые классы. Товары без налогового класса облагаются по классу по умолчанию.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Получить налоговые классы",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.TaxClass"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает налоговый класс. Если is_default установлен, класс становится классом по умолчанию вместо текущего.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Создать налоговый класс",
+                "parameters": [
+                    {
+                        "description": "Данные налогового класса",
+                        "name": "class",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.TaxClassInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.TaxClass"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Класс с таким названием уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-classes/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переименовывает налоговый класс или делает его классом по умолчанию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Обновить налоговый класс",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID налогового класса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные налогового класса",
+                        "name": "class",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.TaxClassInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.TaxClass"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Класс с таким названием уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет налоговый класс вместе с его ставками. Класс, назначенный товарам (включая товары в корзине), удалить нельзя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Удалить налоговый класс",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID налогового класса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Класс назначен товарам",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-rates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает налоговые ставки с фильтрами по классу и стране.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Получить налоговые ставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID налогового класса",
+                        "name": "tax_class_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код страны (ISO 3166-1 alpha-2)",
+                        "name": "country",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.TaxRate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает ставку налогового класса для страны или, если указан region, для региона страны. Ставка региона имеет приоритет над ставкой страны.\nРегион сравнивается с регионом адреса доставки без учета регистра.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Создать налоговую ставку",
+                "parameters": [
+                    {
+                        "description": "Данные налоговой ставки",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.TaxRateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.TaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Ставка для этого класса и региона уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-rates/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет налоговую ставку. Уже оформленные заказы не пересчитываются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Обновить налоговую ставку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID налоговой ставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные налоговой ставки",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.TaxRateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.TaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Ставка для этого класса и региона уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет налоговую ставку. Товары этого класса в этом регионе больше не облагаются налогом, если нет ставки для всей страны.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Налоги (Taxes)"
+                ],
+                "summary": "Удалить налоговую ставку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID налоговой ставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный список пользователей с поиском по email и фильтрами по роли и дате регистрации.\nРоль \"user\" выбирает пользователей без ролей сотрудников.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Получить список пользователей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Часть email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль (admin, catalog_manager, order_fulfiller, support, user)",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата регистрации с (YYYY-MM-DD)",
+                        "name": "registered_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата регистрации по (YYYY-MM-DD), включительно",
+                        "name": "registered_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только заблокированные или только активные",
+                        "name": "disabled",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница пользователей",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "Проверяет учетные данные и в случае успеха возвращает JWT токен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пользователи (Auth)"
+                ],
+                "summary": "Вход пользователя в систему",
+                "parameters": [
+                    {
+                        "description": "Учетные данные для входа",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.LoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT токен",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "token": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Аккаунт заблокирован",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает данные пользователя, аутентифицированного с помощью JWT токена.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пользователи (Auth)"
+                ],
+                "summary": "Получить информацию о текущем пользователе",
+                "responses": {
+                    "200": {
+                        "description": "Данные текущего пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/database.Customer"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь из токена не найден в БД",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/addresses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все адреса доставки и оплаты текущего пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Адреса (Addresses)"
+                ],
+                "summary": "Получить адресную книгу",
+                "responses": {
+                    "200": {
+                        "description": "Список адресов пользователя",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Address"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет адрес в адресную книгу. Первый адрес автоматически становится адресом доставки и оплаты по умолчанию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Адреса (Addresses)"
+                ],
+                "summary": "Добавить адрес",
+                "parameters": [
+                    {
+                        "description": "Данные адреса",
+                        "name": "address",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.AddressInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Address"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации входных данных",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/addresses/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает один адрес из адресной книги текущего пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Адреса (Addresses)"
+                ],
+                "summary": "Получить адрес по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID адреса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Address"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Адрес не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью обновляет адрес из адресной книги. Изменения не затрагивают уже оформленные заказы.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Адреса (Addresses)"
+                ],
+                "summary": "Обновить адрес",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID адреса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные адреса",
+                        "name": "address",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.AddressInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Address"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации входных данных",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Адрес не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет адрес из адресной книги. Снимки адреса в оформленных заказах сохраняются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Адреса (Addresses)"
+                ],
+                "summary": "Удалить адрес",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID адреса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка аутентификации",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Адрес не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает язык писем и отключенные пользователем уведомления. В optional перечислены уведомления, которые можно отключить;\nписьма о регистрации и смене пароля отправляются всегда.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пользователи (Auth)"
+                ],
+                "summary": "Получить настройки уведомлений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.NotificationPreferences"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Задает язык писем (ru, en) и список отключенных уведомлений (order.placed, order.paid, order.shipped, order.cancelled).\nБез языка письма приходят на языке по умолчанию. Настройки применяются к уведомлениям о событиях после изменения.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пользователи (Auth)"
+                ],
+                "summary": "Изменить настройки уведомлений",
+                "parameters": [
+                    {
+                        "description": "Настройки уведомлений",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.NotificationPreferenceInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.NotificationPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет пароль текущего пользователя после проверки текущего пароля. На email пользователя отправляется уведомление о смене пароля.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пользователи (Auth)"
+                ],
+                "summary": "Сменить пароль",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.ChangePasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации входных данных",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный текущий пароль",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "Создает новый аккаунт пользователя с email и паролем. Пользователю отправляется приветственное письмо.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пользователи (Auth)"
+                ],
+                "summary": "Регистрация нового пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные для регистрации",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.LoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Возвращает созданного пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/database.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации входных данных",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь с таким email уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает данные пользователя, его роли, адреса, количество заказов и общую сумму покупок: сумму оплаченных\nзаказов за вычетом возвратов.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Получить пользователя по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Данные пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.CustomerDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Блокирует аккаунт: пользователь не может войти, а выданные ему токены перестают действовать.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Заблокировать пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь заблокирован",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав или попытка заблокировать самого себя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь уже заблокирован",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Снимает блокировку с аккаунта пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Разблокировать пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь разблокирован",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь не заблокирован",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/promote": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Позволяет администратору назначить другого пользователя администратором. Эквивалентно назначению роли admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Повысить пользователя до администратора",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя, которого нужно повысить",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сообщение об успешном повышении",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Попытка повысить самого себя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь уже является администратором",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает роли, назначенные пользователю.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Получить роли пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Роли пользователя",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Role"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Назначает пользователю роль сотрудника (admin, catalog_manager, order_fulfiller, support).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Назначить роль пользователю",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Назначаемая роль",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.AssignRoleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Роль назначена",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные входные данные",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь или роль не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Роль уже назначена",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles/{role}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает роль сотрудника. Отзыв роли admin понижает администратора до обычного пользователя.\nНельзя отозвать роль admin у самого себя и у последнего администратора.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование (Admin)"
+                ],
+                "summary": "Отозвать роль у пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название роли",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Роль отозвана",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав или попытка понизить самого себя",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден или роль не назначена",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Нельзя понизить последнего администратора",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook-deliveries/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ставит событие доставки в очередь на повторную отправку тому же вебхуку. Создается новая доставка с тем же телом\nи id события (по нему получатель может распознать повтор); исходная запись журнала не меняется.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Повторить доставку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID доставки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Доставка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Вебхук отключен",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все вебхуки интеграций с событиями, на которые они подписаны, и состоянием: автоматически отключенный\nвебхук содержит DisabledAt и причину отключения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Получить вебхуки",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Webhook"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Подписывает URL интеграции на события: order.created, order.status_changed, product.updated, product.deleted,\ncustomer.registered. О каждом событии отправляется POST с JSON {id, event, created_at, data}, где data — заказ, товар\nили покупатель в том же виде, что и в API; для product.deleted — {id, sku} окончательно удаленного товара. Перемещение\nтовара в корзину и восстановление из нее приходят как product.updated с заполненным или пустым DeletedAt.\nЗапрос подписан: X-Webhook-Signature: sha256=\u003chex\u003e — HMAC-SHA256 секретом вебхука от строки\n\"\u003cX-Webhook-Timestamp\u003e.\u003cтело запроса\u003e\". Ответ не 2xx считается ошибкой: доставка повторяется с экспоненциально\nрастущей паузой (не более суток) до WEBHOOK_MAX_ATTEMPTS раз, после WEBHOOK_DISABLE_AFTER ошибок подряд вебхук отключается.\nЕсли secret не указан, он генерируется. Секрет возвращается только в ответе на создание.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Создать вебхук",
+                "parameters": [
+                    {
+                        "description": "Данные вебхука",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.WebhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/router.WebhookWithSecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Получить вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет URL, описание и события вебхука. Если указан secret, следующие запросы подписываются новым секретом.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Обновить вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные вебхука",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.WebhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет вебхук вместе с журналом его доставок. Неотправленные события ему больше не доставляются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Удалить вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.SuccessMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает постраничный журнал доставок вебхука, новые первыми: тело события, число попыток, код и начало ответа,\nпоследнюю ошибку и время следующей попытки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Журнал доставок вебхука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Статус доставки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Событие",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.Page-database_WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Приостанавливает доставку событий вебхуку. События продолжают накапливаться и будут отправлены после включения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Отключить вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Включает отключенный вебхук и сбрасывает счетчик ошибок. Накопившиеся за время отключения доставки отправляются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Вебхуки (Webhooks)"
+                ],
+                "summary": "Включить вебхук",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID вебхука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "catalog.Report": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "created": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/catalog.RowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 19
+                }
+            }
+        },
+        "catalog.Row": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Китайский зеленый чай с жасмином"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Зеленый чай"
+                },
+                "price": {
+                    "type": "number",
+                    "example": 350
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "TEA-GREEN-100"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "zelenyy-chay"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "published"
+                }
+            }
+        },
+        "catalog.RowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "price must not be negative"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "TEA-GREEN-100"
+                }
+            }
+        },
+        "database.Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "customerID": {
+                    "type": "integer"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isDefaultBilling": {
+                    "type": "boolean"
+                },
+                "isDefaultShipping": {
+                    "type": "boolean"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "postalCode": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.AddressSnapshot": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "fullName": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "postalCode": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actorID": {
+                    "type": "integer"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before and After are stored as text rather than jsonb so that the bytes\nread back are exactly the ones that were hashed.",
+                    "type": "object"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "entityID": {
+                    "type": "string"
+                },
+                "entityType": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "prevHash": {
+                    "type": "string"
+                },
+                "statusCode": {
+                    "type": "integer"
+                },
+                "userAgent": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.AuditVerification": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "integer"
+                },
+                "first_broken_id": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "database.Category": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parentID": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Coupon": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Category"
+                    }
+                },
+                "code": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "minOrderTotal": {
+                    "type": "number"
+                },
+                "perCustomerLimit": {
+                    "type": "integer"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Product"
+                    }
+                },
+                "startsAt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "usageLimit": {
+                    "type": "integer"
+                },
+                "usedCount": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "database.Customer": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Address"
+                    }
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Order"
+                    }
+                },
+                "registrationDate": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Role"
+                    }
+                }
+            }
+        },
+        "database.Invoice": {
+            "type": "object",
+            "properties": {
+                "billingAddress": {
+                    "$ref": "#/definitions/database.AddressSnapshot"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customerEmail": {
+                    "type": "string"
+                },
+                "customerID": {
+                    "type": "integer"
+                },
+                "discountTotal": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "issuedAt": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.InvoiceLine"
+                    }
+                },
+                "number": {
+                    "type": "string"
+                },
+                "orderDate": {
+                    "type": "string"
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "pricesIncludeTax": {
+                    "type": "boolean"
+                },
+                "sellerCompany": {
+                    "type": "string"
+                },
+                "sellerName": {
+                    "type": "string"
+                },
+                "shippingAddress": {
+                    "$ref": "#/definitions/database.AddressSnapshot"
+                },
+                "shippingMethod": {
+                    "type": "string"
+                },
+                "shippingTotal": {
+                    "type": "number"
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "taxTotal": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "database.InvoiceLine": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "discount": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoiceID": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "taxRate": {
+                    "type": "number"
+                },
+                "unitPrice": {
+                    "type": "number"
+                }
+            }
+        },
+        "database.Order": {
+            "type": "object",
+            "properties": {
+                "billingAddress": {
+                    "$ref": "#/definitions/database.AddressSnapshot"
+                },
+                "couponCode": {
+                    "type": "string"
+                },
+                "customer": {
+                    "$ref": "#/definitions/database.Customer"
+                },
+                "customerID": {
+                    "type": "integer"
+                },
+                "discountTotal": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.OrderItem"
+                    }
+                },
+                "orderDate": {
+                    "type": "string"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Payment"
+                    }
+                },
+                "pricesIncludeTax": {
+                    "type": "boolean"
+                },
+                "refundedTotal": {
+                    "type": "number"
+                },
+                "shipments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Shipment"
+                    }
+                },
+                "shippingAddress": {
+                    "$ref": "#/definitions/database.AddressSnapshot"
+                },
+                "shippingMethod": {
+                    "type": "string"
+                },
+                "shippingMethodID": {
+                    "type": "integer"
+                },
+                "shippingTotal": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusHistory": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.OrderStatusChange"
+                    }
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "taxTotal": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "database.OrderAdjustment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "couponID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "orderItemID": {
+                    "type": "integer"
+                },
+                "promotionID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.OrderItem": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.OrderAdjustment"
+                    }
+                },
+                "discount": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "product": {
+                    "$ref": "#/definitions/database.Product"
+                },
+                "productID": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "refundedAmount": {
+                    "type": "number"
+                },
+                "refundedQuantity": {
+                    "type": "integer"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "taxRate": {
+                    "type": "number"
+                },
+                "variant": {
+                    "$ref": "#/definitions/database.Variant"
+                },
+                "variantID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.OrderStatusChange": {
+            "type": "object",
+            "properties": {
+                "actorID": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "fromStatus": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "toStatus": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "confirmationURL": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "externalID": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "needsRefund": {
+                    "type": "boolean"
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "paidAt": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "refundExternalID": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Permission": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.PriceChange": {
+            "type": "object",
+            "properties": {
+                "actorID": {
+                    "type": "integer"
+                },
+                "changedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "newPrice": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "oldPrice": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "productID": {
+                    "type": "integer"
+                },
+                "scheduledPriceID": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Product": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object"
+                },
+                "category": {
+                    "$ref": "#/definitions/database.Category"
+                },
+                "categoryID": {
+                    "type": "integer"
+                },
+                "compareAtPrice": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ProductImage"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ProductOption"
+                    }
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "taxClassID": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Variant"
+                    }
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "database.ProductImage": {
+            "type": "object",
+            "properties": {
+                "contentType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "productID": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "thumbnailKey": {
+                    "type": "string"
+                },
+                "thumbnailURL": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.ProductOption": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "productID": {
+                    "type": "integer"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "database.ProductSearchHit": {
+            "type": "object",
+            "properties": {
+                "product": {
+                    "$ref": "#/definitions/database.Product"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.6079
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "Зеленый \u003cb\u003eчай\u003c/b\u003e с жасмином"
+                }
+            }
+        },
+        "database.Promotion": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "bundlePrice": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "bundleQuantity": {
+                    "type": "integer"
+                },
+                "buyQuantity": {
+                    "type": "integer"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Category"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "endsAt": {
+                    "type": "string"
+                },
+                "exclusive": {
+                    "type": "boolean"
+                },
+                "getQuantity": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percent": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Product"
+                    }
+                },
+                "startsAt": {
+                    "type": "string"
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Refund": {
+            "type": "object",
+            "properties": {
+                "actorID": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "externalID": {
+                    "type": "string"
+                },
+                "failureReason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.RefundItem"
+                    }
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "paymentID": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "returnRequestID": {
+                    "type": "integer"
+                },
+                "shippingAmount": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.RefundItem": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "orderItemID": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "refundID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.ReturnItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "orderItemID": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "returnRequestID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.ReturnRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "customerID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ReturnItem"
+                    }
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "receivedAt": {
+                    "type": "string"
+                },
+                "refundID": {
+                    "type": "integer"
+                },
+                "resolutionNote": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Role": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Permission"
+                    }
+                }
+            }
+        },
+        "database.ScheduledPrice": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "endsAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "productID": {
+                    "type": "integer"
+                },
+                "revertPrice": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "startsAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Shipment": {
+            "type": "object",
+            "properties": {
+                "actorID": {
+                    "type": "integer"
+                },
+                "carrier": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ShipmentItem"
+                    }
+                },
+                "orderID": {
+                    "type": "integer"
+                },
+                "shippedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trackingNumber": {
+                    "type": "string"
+                },
+                "trackingURL": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.ShipmentItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "orderItemID": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "shipmentID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.ShippingMethod": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.ShippingRate": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "freeFrom": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maxWeight": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "method": {
+                    "$ref": "#/definitions/database.ShippingMethod"
+                },
+                "methodID": {
+                    "type": "integer"
+                },
+                "minWeight": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "pricePerKg": {
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "zoneID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.ShippingZone": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ShippingZoneLocation"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.ShippingZoneLocation": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "zoneID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.TaxClass": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isDefault": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.TaxRate": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "taxClassID": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Variant": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "options": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "productID": {
+                    "type": "integer"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Webhook": {
+            "type": "object",
+            "properties": {
+                "consecutiveFailures": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "disabledReason": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "eventID": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "replayOfID": {
+                    "type": "integer"
+                },
+                "responseBody": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhookID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pricing.Adjustment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 350
+                },
+                "coupon_id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "3 по цене 2"
+                },
+                "promotion_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "pricing.AppliedPromotion": {
+            "type": "object",
+            "properties": {
+                "discount": {
+                    "type": "number",
+                    "example": 350
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "3 по цене 2"
+                }
+            }
+        },
+        "pricing.Line": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.Adjustment"
+                    }
+                },
+                "category_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "discount": {
+                    "type": "number",
+                    "example": 350
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Зеленый чай"
+                },
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "subtotal": {
+                    "type": "number",
+                    "example": 1050
+                },
+                "tax": {
+                    "type": "number",
+                    "example": 116.67
+                },
+                "tax_class_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "tax_rate": {
+                    "type": "number",
+                    "example": 20
+                },
+                "total": {
+                    "type": "number",
+                    "example": 700
+                },
+                "unit_price": {
+                    "type": "number",
+                    "example": 350
+                },
+                "variant_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 0.1
+                }
+            }
+        },
+        "pricing.Quote": {
+            "type": "object",
+            "properties": {
+                "coupon_code": {
+                    "type": "string",
+                    "example": "WELCOME10"
+                },
+                "coupon_discount": {
+                    "type": "number",
+                    "example": 0
+                },
+                "discount_total": {
+                    "type": "number",
+                    "example": 350
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.Line"
+                    }
+                },
+                "prices_include_tax": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "promotions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.AppliedPromotion"
+                    }
+                },
+                "shipping_method": {
+                    "type": "string",
+                    "example": "Курьер"
+                },
+                "shipping_method_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "shipping_options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pricing.ShippingOption"
+                    }
+                },
+                "shipping_total": {
+                    "type": "number",
+                    "example": 300
+                },
+                "subtotal": {
+                    "type": "number",
+                    "example": 1050
+                },
+                "tax_total": {
+                    "type": "number",
+                    "example": 116.67
+                },
+                "total": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 0.3
+                }
+            }
+        },
+        "pricing.ShippingOption": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number",
+                    "example": 300
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Доставка до двери"
+                },
+                "method_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Курьер"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "courier"
+                }
+            }
+        },
+        "router.AddressInput": {
+            "type": "object",
+            "required": [
+                "city",
+                "country",
+                "full_name",
+                "street"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Москва"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "RU"
+                },
+                "full_name": {
+                    "type": "string",
+                    "example": "Иван Иванов"
+                },
+                "is_default_billing": {
+                    "type": "boolean"
+                },
+                "is_default_shipping": {
+                    "type": "boolean"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+79001234567"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "125009"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "Москва"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "ул. Тверская, д. 1, кв. 1"
+                }
+            }
+        },
+        "router.AssignRoleInput": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "example": "catalog_manager"
+                }
+            }
+        },
+        "router.CategoryInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Чай"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "router.ChangePasswordInput": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "router.CouponInput": {
+            "type": "object",
+            "required": [
+                "code",
+                "type",
+                "value"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3
+                    ]
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "WELCOME10"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Скидка 10% на первый заказ"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-12-31T23:59:59Z"
+                },
+                "min_order_total": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 1000
+                },
+                "per_customer_limit": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "starts_at": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ],
+                    "example": "percent"
+                },
+                "usage_limit": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 100
+                },
+                "value": {
+                    "type": "number",
+                    "example": 10
+                }
+            }
+        },
+        "router.CreateOrderInput": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "billing_address_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "coupon_code": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "WELCOME10"
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/router.CreateOrderItemInput"
+                    }
+                },
+                "shipping_address_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "shipping_method_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "router.CreateOrderItemInput": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "quantity"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "router.CreateReturnInput": {
+            "type": "object",
+            "required": [
+                "items",
+                "reason"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Кружка пришла с трещиной"
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/router.ReturnItemInput"
+                    }
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "defective",
+                        "wrong_item",
+                        "not_as_described",
+                        "changed_mind",
+                        "other"
+                    ],
+                    "example": "defective"
+                }
+            }
+        },
+        "router.CreateShipmentInput": {
+            "type": "object",
+            "properties": {
+                "carrier": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "СДЭК"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/router.ShipmentItemInput"
+                    }
+                },
+                "tracking_number": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "1234567890"
+                },
+                "tracking_url": {
+                    "type": "string",
+                    "example": "https://www.cdek.ru/ru/tracking?order_id=1234567890"
+                }
+            }
+        },
+        "router.CustomerDetails": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Address"
+                    }
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lifetime_spend": {
+                    "type": "number",
+                    "example": 1520.5
+                },
+                "order_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Order"
+                    }
+                },
+                "registrationDate": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Role"
+                    }
+                }
+            }
+        },
+        "router.FakePaymentInput": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "succeeded",
+                        "failed",
+                        "canceled"
+                    ],
+                    "example": "succeeded"
+                }
+            }
+        },
+        "router.HTTPError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Product not found"
+                }
+            }
+        },
+        "router.ImageOrderInput": {
+            "type": "object",
+            "required": [
+                "image_ids"
+            ],
+            "properties": {
+                "image_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "router.LoginInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "Test@gmail.com"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "router.NotificationPreferenceInput": {
+            "type": "object",
+            "properties": {
+                "disabled": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order.placed"
+                    ]
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "ru",
+                        "en"
+                    ],
+                    "example": "ru"
+                }
+            }
+        },
+        "router.NotificationPreferences": {
+            "type": "object",
+            "properties": {
+                "disabled": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order.placed"
+                    ]
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "ru"
+                },
+                "optional": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order.placed",
+                        "order.paid",
+                        "order.shipped",
+                        "order.cancelled"
+                    ]
+                }
+            }
+        },
+        "router.Page-database_AuditLog": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.AuditLog"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_Coupon": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Coupon"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_Customer": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Customer"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_Order": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Order"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_Payment": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Payment"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_PriceChange": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.PriceChange"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_Product": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Product"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_Promotion": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Promotion"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_ReturnRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ReturnRequest"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.Page-database_WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.WebhookDelivery"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "router.PreviewOrderInput": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "coupon_code": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "WELCOME10"
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/router.CreateOrderItemInput"
+                    }
+                },
+                "shipping_address_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "shipping_method_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "router.ProductInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "attributes": {
+                    "type": "object"
+                },
+                "category_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Китайский зеленый чай с жасмином"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Зеленый чай"
+                },
+                "price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 350
+                },
+                "sku": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "TEA-GREEN-100"
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "zelenyy-chay"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "published",
+                        "archived"
+                    ],
+                    "example": "draft"
+                },
+                "tax_class_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "weight": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0.1
+                }
+            }
+        },
+        "router.ProductOptionInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "values"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Размер"
+                },
+                "values": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "S",
+                        "M",
+                        "L"
+                    ]
+                }
+            }
+        },
+        "router.ProductOptionsInput": {
+            "type": "object",
+            "properties": {
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/router.ProductOptionInput"
+                    }
+                }
+            }
+        },
+        "router.PromotionInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "bundle_price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 999
+                },
+                "bundle_quantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3
+                },
+                "buy_quantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
+                },
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3
+                    ]
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Третий чай в подарок"
+                },
+                "ends_at": {
+                    "type": "string",
+                    "example": "2025-12-31T23:59:59Z"
+                },
+                "exclusive": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "get_quantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "3 по цене 2"
+                },
+                "percent": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 100
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
-                        "schema": {
-                            "$ref": "#/definitions/router.HTTPError"
-                        }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "database.Customer": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
+                    "example": [
+                        1,
+                        2
+                    ]
                 },
-                "id": {
-                    "type": "integer"
+                "starts_at": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
                 },
-                "orders": {
+                "tiers": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/database.Order"
+                        "$ref": "#/definitions/router.PromotionTierInput"
                     }
                 },
-                "registrationDate": {
-                    "type": "string"
-                },
-                "role": {
-                    "type": "string"
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "buy_x_get_y",
+                        "tiered",
+                        "bundle"
+                    ],
+                    "example": "buy_x_get_y"
                 }
             }
         },
-        "database.Order": {
+        "router.PromotionTierInput": {
             "type": "object",
             "properties": {
-                "customer": {
-                    "$ref": "#/definitions/database.Customer"
-                },
-                "customerID": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "integer"
+                "min_subtotal": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 3000
                 },
+                "percent": {
+                    "type": "number",
+                    "maximum": 100,
+                    "example": 10
+                }
+            }
+        },
+        "router.RefundInput": {
+            "type": "object",
+            "properties": {
                 "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/database.OrderItem"
+                        "$ref": "#/definitions/router.RefundItemInput"
                     }
                 },
-                "orderDate": {
-                    "type": "string"
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Товар поврежден при доставке"
                 },
-                "status": {
-                    "type": "string"
+                "shipping_amount": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0
                 }
             }
         },
-        "database.OrderItem": {
+        "router.RefundItemInput": {
             "type": "object",
+            "required": [
+                "order_item_id"
+            ],
             "properties": {
-                "id": {
-                    "type": "integer"
+                "amount": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 150
                 },
-                "orderID": {
-                    "type": "integer"
+                "order_item_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                }
+            }
+        },
+        "router.RefundReturnInput": {
+            "type": "object",
+            "properties": {
+                "shipping_amount": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0
+                }
+            }
+        },
+        "router.ReturnDecisionInput": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Возврат одобрен, отправьте товар по адресу склада"
+                }
+            }
+        },
+        "router.ReturnItemInput": {
+            "type": "object",
+            "required": [
+                "order_item_id",
+                "quantity"
+            ],
+            "properties": {
+                "order_item_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "router.ScheduledPriceInput": {
+            "type": "object",
+            "required": [
+                "price",
+                "starts_at"
+            ],
+            "properties": {
+                "ends_at": {
+                    "type": "string",
+                    "example": "2025-12-01T00:00:00Z"
                 },
                 "price": {
                     "type": "number",
-                    "format": "float64"
+                    "example": 990
                 },
-                "product": {
-                    "$ref": "#/definitions/database.Product"
+                "starts_at": {
+                    "type": "string",
+                    "example": "2025-11-28T00:00:00Z"
+                }
+            }
+        },
+        "router.ShipShipmentInput": {
+            "type": "object",
+            "properties": {
+                "carrier": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "СДЭК"
                 },
-                "productID": {
-                    "type": "integer"
+                "tracking_number": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "1234567890"
+                },
+                "tracking_url": {
+                    "type": "string",
+                    "example": "https://www.cdek.ru/ru/tracking?order_id=1234567890"
+                }
+            }
+        },
+        "router.ShipmentItemInput": {
+            "type": "object",
+            "required": [
+                "order_item_id",
+                "quantity"
+            ],
+            "properties": {
+                "order_item_id": {
+                    "type": "integer",
+                    "example": 1
                 },
                 "quantity": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "database.Product": {
+        "router.ShippingLocationInput": {
             "type": "object",
+            "required": [
+                "country"
+            ],
             "properties": {
-                "id": {
-                    "type": "integer"
+                "country": {
+                    "type": "string",
+                    "example": "RU"
+                },
+                "region": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Москва"
+                }
+            }
+        },
+        "router.ShippingMethodInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "type"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Доставка до двери"
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Курьер"
                 },
-                "price": {
-                    "type": "number",
-                    "format": "float64"
+                "position": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "courier",
+                        "pickup",
+                        "post"
+                    ],
+                    "example": "courier"
                 }
             }
         },
-        "router.CreateOrderInput": {
+        "router.ShippingQuoteInput": {
             "type": "object",
             "required": [
                 "items"
             ],
             "properties": {
+                "coupon_code": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "WELCOME10"
+                },
                 "items": {
                     "type": "array",
                     "minItems": 1,
                     "items": {
                         "$ref": "#/definitions/router.CreateOrderItemInput"
                     }
+                },
+                "shipping_address_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "router.CreateOrderItemInput": {
+        "router.ShippingRateInput": {
             "type": "object",
             "required": [
-                "product_id",
-                "quantity"
+                "method_id",
+                "zone_id"
             ],
             "properties": {
-                "product_id": {
-                    "type": "integer"
+                "free_from": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 5000
                 },
-                "quantity": {
-                    "type": "integer"
+                "max_weight": {
+                    "type": "number",
+                    "example": 10
+                },
+                "method_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "min_weight": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 300
+                },
+                "price_per_kg": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 50
+                },
+                "zone_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "router.HTTPError": {
+        "router.ShippingZoneInput": {
             "type": "object",
+            "required": [
+                "locations",
+                "name"
+            ],
             "properties": {
-                "error": {
+                "locations": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/router.ShippingLocationInput"
+                    }
+                },
+                "name": {
                     "type": "string",
-                    "example": "Product not found"
+                    "maxLength": 100,
+                    "example": "Москва"
                 }
             }
         },
-        "router.LoginInput": {
+        "router.SuccessMessage": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Product deleted successfully"
+                }
+            }
+        },
+        "router.TaxClassInput": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "name"
             ],
             "properties": {
-                "email": {
+                "is_default": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
                     "type": "string",
-                    "example": "Test@gmail.com"
+                    "maxLength": 100,
+                    "example": "НДС 20%"
+                }
+            }
+        },
+        "router.TaxRateInput": {
+            "type": "object",
+            "required": [
+                "country",
+                "name",
+                "tax_class_id"
+            ],
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "example": "RU"
                 },
-                "password": {
+                "name": {
                     "type": "string",
-                    "minLength": 8
+                    "maxLength": 100,
+                    "example": "НДС"
+                },
+                "rate": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 20
+                },
+                "region": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": ""
+                },
+                "tax_class_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "router.SuccessMessage": {
+        "router.VariantInput": {
             "type": "object",
             "properties": {
-                "message": {
+                "options": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "Размер": "M",
+                        "Цвет": "Красный"
+                    }
+                },
+                "price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 1290
+                },
+                "sku": {
                     "type": "string",
-                    "example": "Product deleted successfully"
+                    "maxLength": 64,
+                    "example": "TSHIRT-RED-M"
+                },
+                "stock": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 10
                 }
             }
         },
-        "router.UpdateProductInput": {
+        "router.WebhookInput": {
             "type": "object",
             "required": [
-                "name"
+                "events",
+                "url"
             ],
             "properties": {
-                "name": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "CRM"
+                },
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "order.created",
+                        "order.status_changed"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16,
+                    "example": "b5d1c1f0e6a34a1f9c3e0d2b7a8f6e41"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://crm.example.com/hooks/shop"
+                }
+            }
+        },
+        "router.WebhookWithSecret": {
+            "type": "object",
+            "properties": {
+                "consecutiveFailures": {
+                    "type": "integer"
+                },
+                "createdAt": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number",
-                    "minimum": 0
+                "description": {
+                    "type": "string"
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "disabledReason": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "b5d1c1f0e6a34a1f9c3e0d2b7a8f6e41"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         }
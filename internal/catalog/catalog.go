@@ -0,0 +1,49 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// columns are the CSV columns of import and export files, in export order.
+var columns = []string{"sku", "name", "description", "slug", "price", "status", "attributes"}
+
+// Row is one product in an import or export file. Products are matched by
+// SKU, so the SKU is required on import.
+type Row struct {
+	SKU         string                 `json:"sku" example:"TEA-GREEN-100"`
+	Name        string                 `json:"name" example:"Зеленый чай"`
+	Description string                 `json:"description" example:"Китайский зеленый чай с жасмином"`
+	Slug        string                 `json:"slug" example:"zelenyy-chay"`
+	Price       float64                `json:"price" example:"350"`
+	Status      string                 `json:"status" example:"published"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty" swaggertype:"object"`
+}
+
+// Record is a parsed row together with its 1-based position in the file. Err
+// is set when the row could not be parsed at all.
+type Record struct {
+	Number int
+	Row    Row
+	Err    error
+}
+
+// ParseFormat accepts a format name or a file name with a known extension.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(s)
+	switch {
+	case s == string(FormatCSV) || strings.HasSuffix(s, ".csv"):
+		return FormatCSV, nil
+	case s == string(FormatJSON) || strings.HasSuffix(s, ".json"):
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected csv or json", s)
+	}
+}
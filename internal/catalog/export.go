@@ -0,0 +1,123 @@
+package catalog
+
+import (
+	"OnlineShop/internal/database"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+)
+
+// exportBatchSize is how many products are loaded from the database at once,
+// so that exporting a large catalogue does not hold it in memory.
+const exportBatchSize = 500
+
+type ExportOptions struct {
+	// Status limits the export to products in this status when set.
+	Status string
+}
+
+// Export writes all products that are not in the trash in the same format
+// Import reads, so an exported file can be edited and imported back. Output
+// is flushed after every batch when w supports it.
+func Export(db *gorm.DB, w io.Writer, format Format, opts ExportOptions) error {
+	var writeBatch func([]database.Product) error
+	var finish func() error
+
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		writeBatch = func(products []database.Product) error {
+			for _, product := range products {
+				row := productRow(product)
+				attributes := ""
+				if len(product.Attributes) > 0 && string(product.Attributes) != "null" {
+					attributes = string(product.Attributes)
+				}
+				record := []string{
+					row.SKU, row.Name, row.Description, row.Slug,
+					strconv.FormatFloat(row.Price, 'f', -1, 64), row.Status, attributes,
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+		finish = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case FormatJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		first := true
+		writeBatch = func(products []database.Product) error {
+			for _, product := range products {
+				data, err := json.Marshal(productRow(product))
+				if err != nil {
+					return err
+				}
+				separator := ",\n"
+				if first {
+					separator, first = "\n", false
+				}
+				if _, err := io.WriteString(w, separator+string(data)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		finish = func() error {
+			_, err := io.WriteString(w, "\n]\n")
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	query := db.Model(&database.Product{})
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+
+	var batch []database.Product
+	result := query.Order("id ASC").FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		if err := writeBatch(batch); err != nil {
+			return err
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	return finish()
+}
+
+func productRow(product database.Product) Row {
+	row := Row{
+		Name:        product.Name,
+		Description: product.Description,
+		Slug:        product.Slug,
+		Price:       product.Price,
+		Status:      product.Status,
+	}
+	if product.SKU != nil {
+		row.SKU = *product.SKU
+	}
+	if len(product.Attributes) > 0 {
+		// Attributes were validated as a JSON object when they were saved.
+		_ = json.Unmarshal(product.Attributes, &row.Attributes)
+	}
+	return row
+}
@@ -0,0 +1,170 @@
+package catalog
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"unicode/utf8"
+)
+
+// errRollback aborts the import transaction without reporting a failure.
+var errRollback = errors.New("rollback")
+
+var statuses = map[string]bool{
+	database.ProductStatusDraft:     true,
+	database.ProductStatusPublished: true,
+	database.ProductStatusArchived:  true,
+}
+
+type RowError struct {
+	Row     int    `json:"row" example:"2"`
+	SKU     string `json:"sku,omitempty" example:"TEA-GREEN-100"`
+	Message string `json:"error" example:"price must not be negative"`
+}
+
+type Report struct {
+	Total   int        `json:"total" example:"120"`
+	Created int        `json:"created" example:"100"`
+	Updated int        `json:"updated" example:"19"`
+	Failed  int        `json:"failed" example:"1"`
+	DryRun  bool       `json:"dry_run" example:"false"`
+	Applied bool       `json:"applied" example:"false"`
+	Errors  []RowError `json:"errors"`
+}
+
+type ImportOptions struct {
+	// DryRun validates the rows and counts what would change without saving.
+	DryRun bool
+	// ActorID is recorded in the price history as the author of the changes.
+	ActorID *uint
+}
+
+// Import creates or updates products by SKU. All rows are applied in a single
+// transaction: if any row is invalid nothing is saved, and the report lists
+// every invalid row. Rows without a status create drafts and keep the status
+// of existing products.
+func Import(db *gorm.DB, records []Record, opts ImportOptions) (Report, error) {
+	report := Report{Total: len(records), DryRun: opts.DryRun, Errors: []RowError{}}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		seen := make(map[string]int, len(records))
+		for _, record := range records {
+			created, problem, err := importRow(tx, record, seen, opts.ActorID)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", record.Number, err)
+			}
+			switch {
+			case problem != "":
+				report.Failed++
+				report.Errors = append(report.Errors, RowError{Row: record.Number, SKU: record.Row.SKU, Message: problem})
+			case created:
+				report.Created++
+			default:
+				report.Updated++
+			}
+		}
+
+		if report.Failed > 0 || opts.DryRun {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return Report{}, err
+	}
+	report.Applied = err == nil
+	return report, nil
+}
+
+// importRow saves a single row. Invalid rows are described by problem; err is
+// only returned for database failures, which abort the whole import.
+func importRow(tx *gorm.DB, record Record, seen map[string]int, actorID *uint) (created bool, problem string, err error) {
+	row := record.Row
+	if record.Err != nil {
+		return false, record.Err.Error(), nil
+	}
+	if message := validateRow(row); message != "" {
+		return false, message, nil
+	}
+	if first, ok := seen[row.SKU]; ok {
+		return false, fmt.Sprintf("SKU is already used in row %d", first), nil
+	}
+	seen[row.SKU] = record.Number
+
+	var product database.Product
+	err = tx.Unscoped().Where("sku = ?", row.SKU).First(&product).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		created = true
+		sku := row.SKU
+		product = database.Product{SKU: &sku, Status: database.ProductStatusDraft}
+	case err != nil:
+		return false, "", err
+	case product.DeletedAt.Valid:
+		return false, "SKU belongs to a product in the trash", nil
+	}
+
+	var oldPrice *float64
+	if !created {
+		price := product.Price
+		oldPrice = &price
+	}
+
+	product.Name = row.Name
+	product.Description = row.Description
+	product.Price = row.Price
+	if row.Status != "" {
+		product.Status = row.Status
+	}
+
+	if slug := database.Slugify(row.Slug); slug != "" {
+		var count int64
+		err := tx.Unscoped().Model(&database.Product{}).
+			Where("slug = ? AND id <> ?", slug, product.ID).
+			Count(&count).Error
+		if err != nil {
+			return false, "", err
+		}
+		if count > 0 {
+			return false, "slug is already used by another product", nil
+		}
+		product.Slug = slug
+	}
+
+	product.Attributes, err = database.NewJSON(row.Attributes)
+	if err != nil {
+		return false, "attributes must be a JSON object", nil
+	}
+
+	if err := tx.Save(&product).Error; err != nil {
+		return false, "", err
+	}
+	if err := database.RecordPriceChange(tx, product.ID, oldPrice, product.Price, actorID, database.PriceSourceImport); err != nil {
+		return false, "", err
+	}
+	if created {
+		return true, "", nil
+	}
+	return false, "", database.EnqueueProductUpdated(tx, product.ID)
+}
+
+func validateRow(row Row) string {
+	switch {
+	case row.SKU == "":
+		return "sku is required"
+	case utf8.RuneCountInString(row.SKU) > 64:
+		return "sku must not be longer than 64 characters"
+	case row.Name == "":
+		return "name is required"
+	case utf8.RuneCountInString(row.Name) > 255:
+		return "name must not be longer than 255 characters"
+	case utf8.RuneCountInString(row.Slug) > 255:
+		return "slug must not be longer than 255 characters"
+	case row.Price < 0:
+		return "price must not be negative"
+	case row.Status != "" && !statuses[row.Status]:
+		return fmt.Sprintf("status %q is not one of draft, published, archived", row.Status)
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parse reads all rows of the file. Problems with single rows are stored in
+// their records so they can be reported together; an error is only returned
+// when the file as a whole cannot be read.
+func Parse(r io.Reader, format Format) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatJSON:
+		return parseJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func parseCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	// Spreadsheet programs often start UTF-8 files with a byte order mark.
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"sku", "name", "price"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain the %q column", required)
+		}
+	}
+
+	var records []Record
+	for number := 1; ; number++ {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		record := Record{Number: number}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			record.Err = parseErr.Err
+			records = append(records, record)
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+
+		record.Row = Row{
+			SKU:         field("sku"),
+			Name:        field("name"),
+			Description: field("description"),
+			Slug:        field("slug"),
+			Status:      field("status"),
+		}
+		// An empty cell is more likely a mistake than a free product, so a
+		// zero price has to be written out.
+		if price := field("price"); price == "" {
+			record.Err = errors.New("price is required")
+		} else if record.Row.Price, err = strconv.ParseFloat(strings.Replace(price, ",", ".", 1), 64); err != nil {
+			record.Err = fmt.Errorf("price %q is not a number", price)
+		}
+		if attributes := field("attributes"); attributes != "" && record.Err == nil {
+			if err := json.Unmarshal([]byte(attributes), &record.Row.Attributes); err != nil {
+				record.Err = errors.New("attributes must be a JSON object")
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+func parseJSON(r io.Reader) ([]Record, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("file must contain a JSON array of products: %w", err)
+	}
+
+	records := make([]Record, len(items))
+	for i, item := range items {
+		records[i].Number = i + 1
+		decoder := json.NewDecoder(strings.NewReader(string(item)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&records[i].Row); err != nil {
+			records[i].Err = fmt.Errorf("invalid product: %w", err)
+			continue
+		}
+		var fields map[string]json.RawMessage
+		json.Unmarshal(item, &fields)
+		if price, ok := fields["price"]; !ok || string(price) == "null" {
+			records[i].Err = errors.New("price is required")
+		}
+	}
+	return records, nil
+}
@@ -15,33 +15,74 @@ type Customer struct {
 	ID               uint   `gorm:"primaryKey"`
 	Email            string `gorm:"type:varchar(255);not null;unique"`
 	PasswordHash     string `gorm:"type:varchar(255);not null" json:"-"`
-	Role             string `gorm:"type:varchar(50);not null;default:'user'"`
 	RegistrationDate time.Time
+	DisabledAt       *time.Time
+	Roles            []Role `gorm:"many2many:customer_roles"`
 	Orders           []Order
+	Addresses        []Address
 }
 
 type Product struct {
-	ID    uint   `gorm:"primaryKey"`
-	Name  string `gorm:"type:varchar(255);not null"`
-	Price float64
+	ID             uint    `gorm:"primaryKey"`
+	Name           string  `gorm:"type:varchar(255);not null"`
+	Description    string  `gorm:"type:text"`
+	SKU            *string `gorm:"type:varchar(64);uniqueIndex"`
+	Slug           string  `gorm:"type:varchar(255);uniqueIndex"`
+	Price          float64
+	CompareAtPrice *float64
+	Weight         float64         `gorm:"not null;default:0"`
+	CategoryID     *uint           `gorm:"index"`
+	Category       *Category       `json:",omitempty"`
+	TaxClassID     *uint           `gorm:"index"`
+	Status         string          `gorm:"type:varchar(20);not null;default:'published';index"`
+	Attributes     JSON            `gorm:"type:jsonb" swaggertype:"object"`
+	Options        []ProductOption `gorm:"foreignKey:ProductID" json:",omitempty"`
+	Variants       []Variant       `gorm:"foreignKey:ProductID" json:",omitempty"`
+	Images         []ProductImage  `gorm:"foreignKey:ProductID" json:",omitempty"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index" swaggertype:"string" format:"date-time"`
 }
 
 type Order struct {
-	ID         uint `gorm:"primaryKey"`
-	CustomerID uint
-	OrderDate  time.Time
-	Status     string      `gorm:"type:varchar(50);not null"`
-	Items      []OrderItem `gorm:"foreignKey:OrderID"`
-	Customer   Customer    `gorm:"foreignKey:CustomerID"`
+	ID               uint `gorm:"primaryKey"`
+	CustomerID       uint
+	OrderDate        time.Time
+	Status           string              `gorm:"type:varchar(50);not null"`
+	Subtotal         float64             `gorm:"not null;default:0"`
+	DiscountTotal    float64             `gorm:"not null;default:0"`
+	TaxTotal         float64             `gorm:"not null;default:0"`
+	PricesIncludeTax bool                `gorm:"not null;default:false"`
+	Total            float64             `gorm:"not null;default:0"`
+	CouponCode       *string             `gorm:"type:varchar(64)"`
+	ShippingMethodID *uint               `gorm:"index"`
+	ShippingMethod   string              `gorm:"type:varchar(100)"`
+	ShippingTotal    float64             `gorm:"not null;default:0"`
+	RefundedTotal    float64             `gorm:"not null;default:0"`
+	ShippingAddress  AddressSnapshot     `gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress   AddressSnapshot     `gorm:"embedded;embeddedPrefix:billing_"`
+	Items            []OrderItem         `gorm:"foreignKey:OrderID"`
+	Payments         []Payment           `gorm:"foreignKey:OrderID" json:",omitempty"`
+	Shipments        []Shipment          `gorm:"foreignKey:OrderID" json:",omitempty"`
+	StatusHistory    []OrderStatusChange `gorm:"foreignKey:OrderID" json:",omitempty"`
+	Customer         Customer            `gorm:"foreignKey:CustomerID"`
 }
 
 type OrderItem struct {
-	ID        uint `gorm:"primaryKey"`
-	OrderID   uint
-	ProductID uint
-	Quantity  int
-	Price     float64
-	Product   Product `gorm:"foreignKey:ProductID"`
+	ID               uint `gorm:"primaryKey"`
+	OrderID          uint
+	ProductID        uint
+	VariantID        *uint
+	Quantity         int
+	Price            float64
+	Discount         float64           `gorm:"not null;default:0"`
+	TaxRate          float64           `gorm:"not null;default:0"`
+	Tax              float64           `gorm:"not null;default:0"`
+	RefundedQuantity int               `gorm:"not null;default:0"`
+	RefundedAmount   float64           `gorm:"not null;default:0"`
+	Product          Product           `gorm:"foreignKey:ProductID"`
+	Variant          *Variant          `gorm:"foreignKey:VariantID"`
+	Adjustments      []OrderAdjustment `gorm:"foreignKey:OrderItemID" json:",omitempty"`
 }
 
 var DB *gorm.DB
@@ -58,10 +99,30 @@ func InitDB(cfg *config.Config) {
 		log.Fatal("Failed to connect to DB:", err)
 	}
 
-	err = DB.AutoMigrate(&Product{}, &Customer{}, &Order{}, &OrderItem{})
+	err = DB.AutoMigrate(&Permission{}, &Role{}, &Category{}, &TaxClass{}, &TaxRate{}, &ShippingMethod{}, &ShippingZone{}, &ShippingZoneLocation{}, &ShippingRate{}, &Product{}, &ProductOption{}, &Variant{}, &ProductImage{}, &PriceChange{}, &ScheduledPrice{}, &Customer{}, &Address{}, &Order{}, &OrderItem{}, &OrderStatusChange{}, &Coupon{}, &CouponRedemption{}, &Promotion{}, &OrderAdjustment{}, &Payment{}, &Refund{}, &RefundItem{}, &ReturnRequest{}, &ReturnItem{}, &Shipment{}, &ShipmentItem{}, &InvoiceCounter{}, &Invoice{}, &InvoiceLine{}, &CatalogVersion{}, &Notification{}, &NotificationPreference{}, &Webhook{}, &WebhookDelivery{}, &IdempotencyKey{}, &AuditLog{})
 	if err != nil {
 		log.Fatal("Migration failed:", err)
 	}
+
+	if err := installAuditTriggers(DB); err != nil {
+		log.Fatal("Failed to install audit log triggers:", err)
+	}
+
+	if err := installSearchIndex(DB); err != nil {
+		log.Fatal("Failed to create product search index:", err)
+	}
+
+	if err := installCatalogVersion(DB); err != nil {
+		log.Fatal("Failed to install catalogue version triggers:", err)
+	}
+
+	if err := backfillProductSlugs(DB); err != nil {
+		log.Fatal("Failed to generate product slugs:", err)
+	}
+
+	if err := backfillOrderTotals(DB); err != nil {
+		log.Fatal("Failed to calculate order totals:", err)
+	}
 }
 
 func CreateInitialAdmin(db *gorm.DB, cfg *config.Config) {
@@ -85,12 +146,14 @@ func CreateInitialAdmin(db *gorm.DB, cfg *config.Config) {
 			Email:            cfg.InitialAdminEmail,
 			PasswordHash:     string(hashedPassword),
 			RegistrationDate: time.Now(),
-			Role:             "admin",
 		}
 
 		if result := db.Create(&admin); result.Error != nil {
 			log.Fatalf("Failed to create initial admin: %v", result.Error)
 		}
+		if err := AssignRole(db, admin.ID, RoleAdmin); err != nil {
+			log.Fatalf("Failed to grant admin role to initial admin: %v", err)
+		}
 		log.Println("Initial admin created successfully.")
 	} else if err != nil {
 		log.Fatalf("Failed to query for initial admin: %v", err)
@@ -98,3 +161,12 @@ func CreateInitialAdmin(db *gorm.DB, cfg *config.Config) {
 		log.Println("Initial admin user already exists.")
 	}
 }
+
+// backfillOrderTotals calculates the totals of orders placed before orders
+// stored them.
+func backfillOrderTotals(db *gorm.DB) error {
+	return db.Exec(`UPDATE orders
+		SET subtotal = (SELECT COALESCE(SUM(price * quantity), 0) FROM order_items WHERE order_items.order_id = orders.id),
+		    total = (SELECT COALESCE(SUM(price * quantity), 0) FROM order_items WHERE order_items.order_id = orders.id)
+		WHERE subtotal = 0 AND total = 0`).Error
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"strings"
+)
+
+type Address struct {
+	ID                uint   `gorm:"primaryKey"`
+	CustomerID        uint   `gorm:"not null;index"`
+	FullName          string `gorm:"type:varchar(255);not null"`
+	Phone             string `gorm:"type:varchar(50)"`
+	Country           string `gorm:"type:varchar(2);not null"`
+	Region            string `gorm:"type:varchar(255)"`
+	City              string `gorm:"type:varchar(255);not null"`
+	Street            string `gorm:"type:varchar(255);not null"`
+	PostalCode        string `gorm:"type:varchar(20)"`
+	IsDefaultShipping bool   `gorm:"not null;default:false"`
+	IsDefaultBilling  bool   `gorm:"not null;default:false"`
+}
+
+// AddressSnapshot is a copy of an address stored on an order, so that later
+// edits or deletion of the address book entry do not change order history.
+type AddressSnapshot struct {
+	FullName   string `gorm:"type:varchar(255)"`
+	Phone      string `gorm:"type:varchar(50)"`
+	Country    string `gorm:"type:varchar(2)"`
+	Region     string `gorm:"type:varchar(255)"`
+	City       string `gorm:"type:varchar(255)"`
+	Street     string `gorm:"type:varchar(255)"`
+	PostalCode string `gorm:"type:varchar(20)"`
+}
+
+func (a Address) Snapshot() AddressSnapshot {
+	return AddressSnapshot{
+		FullName:   a.FullName,
+		Phone:      a.Phone,
+		Country:    a.Country,
+		Region:     a.Region,
+		City:       a.City,
+		Street:     a.Street,
+		PostalCode: a.PostalCode,
+	}
+}
+
+// Line returns the address on one line for documents such as invoices, from
+// the postal code to the street.
+func (a AddressSnapshot) Line() string {
+	parts := make([]string, 0, 5)
+	for _, part := range []string{a.PostalCode, a.Country, a.Region, a.City, a.Street} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NormalizeRegion makes region names comparable regardless of case and
+// surrounding spaces, as regions are typed in by customers.
+func NormalizeRegion(region string) string {
+	return strings.ToLower(strings.TrimSpace(region))
+}
+
+// ClearDefaultAddresses resets the default shipping and/or billing flag on all
+// addresses of the customer except the one with keepID.
+func ClearDefaultAddresses(tx *gorm.DB, customerID, keepID uint, shipping, billing bool) error {
+	if shipping {
+		err := tx.Model(&Address{}).
+			Where("customer_id = ? AND id <> ?", customerID, keepID).
+			Update("is_default_shipping", false).Error
+		if err != nil {
+			return err
+		}
+	}
+	if billing {
+		err := tx.Model(&Address{}).
+			Where("customer_id = ? AND id <> ?", customerID, keepID).
+			Update("is_default_billing", false).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
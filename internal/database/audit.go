@@ -0,0 +1,158 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// genesisHash is the PrevHash of the first entry of the audit chain.
+var genesisHash = strings.Repeat("0", 64)
+
+var errAuditChainBroken = errors.New("audit chain broken")
+
+// AuditLog is an append-only record of an admin or security action. Every entry
+// stores the hash of the previous one, so editing or deleting a row breaks the
+// chain and is detected by VerifyAuditChain.
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey"`
+	CreatedAt  time.Time `gorm:"not null;index"`
+	ActorID    *uint     `gorm:"index"`
+	Action     string    `gorm:"type:varchar(100);not null;index"`
+	EntityType string    `gorm:"type:varchar(50);index"`
+	EntityID   string    `gorm:"type:varchar(100);index"`
+	// Before and After are stored as text rather than jsonb so that the bytes
+	// read back are exactly the ones that were hashed.
+	Before     JSON   `gorm:"type:text" swaggertype:"object"`
+	After      JSON   `gorm:"type:text" swaggertype:"object"`
+	Method     string `gorm:"type:varchar(10)"`
+	Path       string `gorm:"type:varchar(255)"`
+	StatusCode int
+	IP         string `gorm:"type:varchar(64)"`
+	UserAgent  string `gorm:"type:varchar(512)"`
+	PrevHash   string `gorm:"type:char(64);not null"`
+	Hash       string `gorm:"type:char(64);not null;unique"`
+}
+
+func (a *AuditLog) computeHash() string {
+	actor := ""
+	if a.ActorID != nil {
+		actor = fmt.Sprint(*a.ActorID)
+	}
+
+	fields := []string{
+		a.PrevHash,
+		a.CreatedAt.UTC().Format(time.RFC3339Nano),
+		actor,
+		a.Action,
+		a.EntityType,
+		a.EntityID,
+		string(a.Before),
+		string(a.After),
+		a.Method,
+		a.Path,
+		fmt.Sprint(a.StatusCode),
+		a.IP,
+		a.UserAgent,
+	}
+
+	h := sha256.New()
+	for _, field := range fields {
+		// Length-prefix every field so that values cannot bleed into each other.
+		fmt.Fprintf(h, "%d:%s|", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AppendAudit links the entry to the end of the chain and stores it.
+func AppendAudit(db *gorm.DB, entry *AuditLog) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			// Serialize writers so that two entries never share a PrevHash.
+			if err := tx.Exec("LOCK TABLE audit_logs IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+				return err
+			}
+		}
+
+		var last AuditLog
+		if err := tx.Select("hash").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+
+		entry.ID = 0
+		entry.PrevHash = last.Hash
+		if entry.PrevHash == "" {
+			entry.PrevHash = genesisHash
+		}
+		// Postgres keeps microseconds, so truncate before hashing.
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.Hash = entry.computeHash()
+
+		return tx.Create(entry).Error
+	})
+}
+
+type AuditVerification struct {
+	Valid         bool  `json:"valid"`
+	Checked       int   `json:"checked"`
+	FirstBrokenID *uint `json:"first_broken_id,omitempty"`
+}
+
+// VerifyAuditChain recomputes the hash of every entry in order and reports the
+// first entry whose hash or link to the previous entry does not match.
+func VerifyAuditChain(db *gorm.DB) (AuditVerification, error) {
+	result := AuditVerification{Valid: true}
+	prevHash := genesisHash
+
+	var batch []AuditLog
+	err := db.Order("id ASC").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			entry := &batch[i]
+			result.Checked++
+			if entry.PrevHash != prevHash || entry.computeHash() != entry.Hash {
+				id := entry.ID
+				result.Valid = false
+				result.FirstBrokenID = &id
+				return errAuditChainBroken
+			}
+			prevHash = entry.Hash
+		}
+		return nil
+	}).Error
+	if errors.Is(err, errAuditChainBroken) {
+		err = nil
+	}
+
+	return result, err
+}
+
+// installAuditTriggers makes audit_logs append-only at the database level.
+func installAuditTriggers(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+		FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs`,
+		`CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CatalogTables hold the data the marketplace feeds are generated from.
+var CatalogTables = []string{"products", "variants", "product_images", "categories"}
+
+// CatalogVersion is a single row counting the transactions that changed the
+// catalogue. Triggers keep it up to date whoever writes to the database, so
+// the feed cache of the server also notices imports run from the command line.
+type CatalogVersion struct {
+	ID      uint  `gorm:"primaryKey"`
+	Version int64 `gorm:"not null;default:0"`
+	// LastTx is the transaction that last bumped the version, so that a
+	// transaction changing many rows bumps it only once.
+	LastTx int64 `gorm:"not null;default:0"`
+}
+
+const catalogVersionID = 1
+
+// CurrentCatalogVersion returns the version of the catalogue. It changes when
+// a transaction writing to CatalogTables commits.
+func CurrentCatalogVersion(db *gorm.DB) (int64, error) {
+	var version CatalogVersion
+	err := db.Select("version").First(&version, catalogVersionID).Error
+	return version.Version, err
+}
+
+func installCatalogVersion(db *gorm.DB) error {
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&CatalogVersion{ID: catalogVersionID}).Error; err != nil {
+		return err
+	}
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	// The triggers are deferred to the commit, so checkouts updating stock
+	// hold the lock on the version row only while they commit.
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION catalog_versions_bump() RETURNS trigger AS $$
+		BEGIN
+			UPDATE catalog_versions SET version = version + 1, last_tx = txid_current()
+			WHERE id = 1 AND last_tx <> txid_current();
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql`,
+	}
+	for _, table := range CatalogTables {
+		statements = append(statements,
+			`DROP TRIGGER IF EXISTS catalog_versions_bump ON `+table,
+			`CREATE CONSTRAINT TRIGGER catalog_versions_bump AFTER INSERT OR UPDATE OR DELETE ON `+table+`
+			DEFERRABLE INITIALLY DEFERRED FOR EACH ROW EXECUTE FUNCTION catalog_versions_bump()`,
+		)
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
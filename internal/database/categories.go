@@ -0,0 +1,35 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrCategoryCycle = errors.New("category cannot be its own ancestor")
+
+// Category groups products into a tree that storefronts and marketplace feeds
+// use for navigation.
+type Category struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(255);not null"`
+	ParentID  *uint  `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CheckCategoryParent reports ErrCategoryCycle if making parentID the parent of
+// categoryID would create a loop in the tree.
+func CheckCategoryParent(db *gorm.DB, categoryID uint, parentID *uint) error {
+	for id := parentID; id != nil; {
+		if *id == categoryID {
+			return ErrCategoryCycle
+		}
+		var parent Category
+		if err := db.Select("id", "parent_id").First(&parent, *id).Error; err != nil {
+			return err
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	CouponTypePercent = "percent"
+	CouponTypeFixed   = "fixed"
+)
+
+var (
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponInactive      = errors.New("coupon is not active")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponMinTotal      = errors.New("order total is below the coupon minimum")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to any product in the order")
+	ErrCouponUsageLimit    = errors.New("coupon usage limit has been reached")
+	ErrCouponCustomerLimit = errors.New("coupon has already been used the maximum number of times by this customer")
+)
+
+// Coupon is a discount code entered at checkout. A percent coupon takes Value
+// percent off the eligible items, a fixed one takes Value off their sum. When
+// Products or Categories are set, only items of those products or categories
+// are eligible. Limits left empty are unlimited.
+type Coupon struct {
+	ID               uint    `gorm:"primaryKey"`
+	Code             string  `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Description      string  `gorm:"type:varchar(255)"`
+	Type             string  `gorm:"type:varchar(20);not null"`
+	Value            float64 `gorm:"not null"`
+	MinOrderTotal    float64 `gorm:"not null;default:0"`
+	StartsAt         *time.Time
+	ExpiresAt        *time.Time
+	UsageLimit       *int
+	PerCustomerLimit *int
+	UsedCount        int        `gorm:"not null;default:0"`
+	Active           bool       `gorm:"not null;default:false"`
+	Products         []Product  `gorm:"many2many:coupon_products" json:",omitempty"`
+	Categories       []Category `gorm:"many2many:coupon_categories" json:",omitempty"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// CouponRedemption records the use of a coupon by an order.
+type CouponRedemption struct {
+	ID         uint    `gorm:"primaryKey"`
+	CouponID   uint    `gorm:"not null;index:idx_coupon_redemptions_customer"`
+	CustomerID uint    `gorm:"not null;index:idx_coupon_redemptions_customer"`
+	OrderID    uint    `gorm:"not null;uniqueIndex"`
+	Discount   float64 `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+// CouponLine is an order item as seen by coupon validation.
+type CouponLine struct {
+	ProductID  uint
+	CategoryID *uint
+	Amount     float64
+}
+
+// NormalizeCouponCode makes coupon codes case-insensitive.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// RoundMoney rounds an amount to kopecks.
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// AppliesTo reports whether the coupon discounts the product.
+func (c Coupon) AppliesTo(productID uint, categoryID *uint) bool {
+	return matchesRestrictions(c.Products, c.Categories, productID, categoryID)
+}
+
+// Discount returns the discount the coupon gives on the lines, never more
+// than the eligible amount.
+func (c Coupon) Discount(lines []CouponLine) float64 {
+	eligible := 0.0
+	for _, line := range lines {
+		if c.AppliesTo(line.ProductID, line.CategoryID) {
+			eligible += line.Amount
+		}
+	}
+
+	discount := c.Value
+	if c.Type == CouponTypePercent {
+		discount = eligible * c.Value / 100
+	}
+	return RoundMoney(math.Min(discount, eligible))
+}
+
+// FindCoupon loads a coupon by code with its product and category
+// restrictions.
+func FindCoupon(tx *gorm.DB, code string) (Coupon, error) {
+	var coupon Coupon
+	err := tx.Preload("Products").Preload("Categories").
+		Where("code = ?", NormalizeCouponCode(code)).
+		First(&coupon).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return coupon, ErrCouponNotFound
+	}
+	return coupon, err
+}
+
+// ValidateCoupon checks everything about a coupon that does not depend on
+// its usage and returns the discount for the lines.
+func ValidateCoupon(coupon Coupon, lines []CouponLine, now time.Time) (float64, error) {
+	if !coupon.Active || (coupon.StartsAt != nil && now.Before(*coupon.StartsAt)) {
+		return 0, ErrCouponInactive
+	}
+	if coupon.ExpiresAt != nil && !now.Before(*coupon.ExpiresAt) {
+		return 0, ErrCouponExpired
+	}
+
+	subtotal := 0.0
+	for _, line := range lines {
+		subtotal += line.Amount
+	}
+	if subtotal < coupon.MinOrderTotal {
+		return 0, fmt.Errorf("%w of %.2f", ErrCouponMinTotal, coupon.MinOrderTotal)
+	}
+
+	discount := coupon.Discount(lines)
+	if discount <= 0 {
+		return 0, ErrCouponNotApplicable
+	}
+	return discount, nil
+}
+
+// CheckCouponUsage reports whether the coupon has uses left, globally and for
+// the customer, without using it.
+func CheckCouponUsage(db *gorm.DB, coupon Coupon, customerID uint) error {
+	if coupon.UsageLimit != nil && coupon.UsedCount >= *coupon.UsageLimit {
+		return ErrCouponUsageLimit
+	}
+	if coupon.PerCustomerLimit == nil {
+		return nil
+	}
+	var used int64
+	err := db.Model(&CouponRedemption{}).
+		Where("coupon_id = ? AND customer_id = ?", coupon.ID, customerID).
+		Count(&used).Error
+	if err != nil {
+		return err
+	}
+	if used >= int64(*coupon.PerCustomerLimit) {
+		return ErrCouponCustomerLimit
+	}
+	return nil
+}
+
+// RedeemCoupon counts a use of the coupon by the order. It must run in the
+// transaction that creates the order. The conditional update enforces the
+// global limit and locks the coupon row until the transaction ends, so
+// concurrent checkouts with the same coupon count per-customer uses one at a
+// time.
+func RedeemCoupon(tx *gorm.DB, coupon Coupon, customerID, orderID uint, discount float64) error {
+	result := tx.Model(&Coupon{}).
+		Where("id = ? AND (usage_limit IS NULL OR used_count < usage_limit)", coupon.ID).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCouponUsageLimit
+	}
+
+	if coupon.PerCustomerLimit != nil {
+		var used int64
+		err := tx.Model(&CouponRedemption{}).
+			Where("coupon_id = ? AND customer_id = ?", coupon.ID, customerID).
+			Count(&used).Error
+		if err != nil {
+			return err
+		}
+		if used >= int64(*coupon.PerCustomerLimit) {
+			return ErrCouponCustomerLimit
+		}
+	}
+
+	return tx.Create(&CouponRedemption{
+		CouponID:   coupon.ID,
+		CustomerID: customerID,
+		OrderID:    orderID,
+		Discount:   discount,
+	}).Error
+}
+
+// ReleaseCoupon undoes the redemption of a coupon by the order, if any, so
+// that the use counts towards the coupon limits no more.
+func ReleaseCoupon(tx *gorm.DB, orderID uint) error {
+	var redemption CouponRedemption
+	err := tx.Where("order_id = ?", orderID).First(&redemption).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&Coupon{}).
+		Where("id = ? AND used_count > 0", redemption.CouponID).
+		UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+	if err != nil {
+		return err
+	}
+	return tx.Delete(&redemption).Error
+}
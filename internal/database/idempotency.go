@@ -0,0 +1,75 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// IdempotencyKey remembers a mutating request sent with an Idempotency-Key
+// header and the response it got, so that a retry of the request returns the
+// same response instead of being executed again. Keys are scoped to the user
+// who sent them.
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primaryKey"`
+	ActorID     uint   `gorm:"not null;uniqueIndex:idx_idempotency_keys_actor_key"`
+	Key         string `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_actor_key"`
+	Method      string `gorm:"type:varchar(10);not null"`
+	Path        string `gorm:"type:varchar(255);not null"`
+	Fingerprint string `gorm:"type:varchar(64);not null"`
+	StatusCode  int
+	ContentType string `gorm:"type:varchar(255)"`
+	Response    []byte
+	CompletedAt *time.Time
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+}
+
+// ReserveIdempotencyKey stores the key if the user has not used it yet, or
+// only before it expired, and reports whether it did. Otherwise key is loaded
+// with the stored request, which may still be in progress. The unique index
+// makes sure that of two concurrent requests with the same key only one is
+// executed.
+func ReserveIdempotencyKey(tx *gorm.DB, key *IdempotencyKey, now time.Time) (bool, error) {
+	err := tx.Where("actor_id = ? AND key = ? AND expires_at < ?", key.ActorID, key.Key, now).
+		Delete(&IdempotencyKey{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+	return false, tx.Where("actor_id = ? AND key = ?", key.ActorID, key.Key).First(key).Error
+}
+
+// CompleteIdempotencyKey stores the response to the request of the key.
+func CompleteIdempotencyKey(tx *gorm.DB, key *IdempotencyKey, statusCode int, contentType string, response []byte, now time.Time) error {
+	key.StatusCode = statusCode
+	key.ContentType = contentType
+	key.Response = response
+	key.CompletedAt = &now
+	return tx.Model(&IdempotencyKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"status_code":  statusCode,
+		"content_type": contentType,
+		"response":     response,
+		"completed_at": now,
+	}).Error
+}
+
+// ReleaseIdempotencyKey forgets the key, so that the request can be retried
+// with it, e.g. after a server error.
+func ReleaseIdempotencyKey(tx *gorm.DB, key IdempotencyKey) error {
+	return tx.Delete(&IdempotencyKey{}, key.ID).Error
+}
+
+// DeleteExpiredIdempotencyKeys removes the keys that expired before now and
+// returns how many there were.
+func DeleteExpiredIdempotencyKeys(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("expires_at < ?", now).Delete(&IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,20 @@
+package database
+
+import "time"
+
+// ProductImage is an uploaded picture of a product. Key and ThumbnailKey
+// locate the files in storage; the URLs are what clients load.
+type ProductImage struct {
+	ID           uint   `gorm:"primaryKey"`
+	ProductID    uint   `gorm:"not null;index"`
+	Key          string `gorm:"type:varchar(255);not null"`
+	ThumbnailKey string `gorm:"type:varchar(255);not null"`
+	URL          string `gorm:"type:varchar(1024);not null"`
+	ThumbnailURL string `gorm:"type:varchar(1024);not null"`
+	ContentType  string `gorm:"type:varchar(50);not null"`
+	Size         int64
+	Width        int
+	Height       int
+	Position     int `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+}
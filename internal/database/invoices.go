@@ -0,0 +1,153 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Invoice is the document issued for a paid order. It copies everything it
+// shows from the order, the customer and the shop at the time it is issued,
+// so later changes to them, such as a renamed product, do not alter it.
+// Invoices are numbered sequentially within a year and never change once
+// issued.
+type Invoice struct {
+	ID               uint            `gorm:"primaryKey"`
+	Number           string          `gorm:"type:varchar(32);not null;uniqueIndex"`
+	OrderID          uint            `gorm:"not null;uniqueIndex"`
+	CustomerID       uint            `gorm:"not null;index"`
+	CustomerEmail    string          `gorm:"type:varchar(255);not null"`
+	SellerName       string          `gorm:"type:varchar(255);not null"`
+	SellerCompany    string          `gorm:"type:varchar(255);not null"`
+	BillingAddress   AddressSnapshot `gorm:"embedded;embeddedPrefix:billing_"`
+	ShippingAddress  AddressSnapshot `gorm:"embedded;embeddedPrefix:shipping_"`
+	Currency         string          `gorm:"type:varchar(3);not null"`
+	PricesIncludeTax bool            `gorm:"not null;default:false"`
+	Subtotal         float64         `gorm:"not null"`
+	DiscountTotal    float64         `gorm:"not null"`
+	TaxTotal         float64         `gorm:"not null"`
+	ShippingMethod   string          `gorm:"type:varchar(100)"`
+	ShippingTotal    float64         `gorm:"not null"`
+	Total            float64         `gorm:"not null"`
+	Lines            []InvoiceLine   `gorm:"foreignKey:InvoiceID"`
+	OrderDate        time.Time
+	IssuedAt         time.Time
+}
+
+// InvoiceLine is an order line as shown on the invoice. Amount is what was
+// paid for the line.
+type InvoiceLine struct {
+	ID        uint    `gorm:"primaryKey"`
+	InvoiceID uint    `gorm:"not null;index"`
+	Position  int     `gorm:"not null"`
+	Name      string  `gorm:"type:varchar(512);not null"`
+	SKU       string  `gorm:"type:varchar(64)"`
+	Quantity  int     `gorm:"not null"`
+	UnitPrice float64 `gorm:"not null"`
+	Discount  float64 `gorm:"not null"`
+	TaxRate   float64 `gorm:"not null"`
+	Tax       float64 `gorm:"not null"`
+	Amount    float64 `gorm:"not null"`
+}
+
+// InvoiceCounter holds the last invoice number issued in a year.
+type InvoiceCounter struct {
+	Year int `gorm:"primaryKey;autoIncrement:false"`
+	Last int `gorm:"not null"`
+}
+
+// InvoiceSeller is the shop as shown on invoices.
+type InvoiceSeller struct {
+	Name    string
+	Company string
+}
+
+// nextInvoiceNumber takes the next number of the year. The counter row stays
+// locked until the transaction ends, so numbers have no gaps or duplicates.
+func nextInvoiceNumber(tx *gorm.DB, year int) (string, error) {
+	counter := InvoiceCounter{Year: year}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&counter, "year = ?", year).Error; err != nil {
+		return "", err
+	}
+	counter.Last++
+	if err := tx.Model(&InvoiceCounter{}).Where("year = ?", year).Update("last", counter.Last).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%06d", year, counter.Last), nil
+}
+
+// IssueInvoice issues the invoice of a paid order, unless it already has one,
+// and returns it.
+func IssueInvoice(tx *gorm.DB, orderID uint, seller InvoiceSeller, currency string, now time.Time) (Invoice, error) {
+	var invoice Invoice
+	err := tx.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("order_id = ?", orderID).First(&invoice).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return invoice, err
+	}
+
+	var order Order
+	err = tx.Preload("Customer").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Items.Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Items.Product.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Items.Variant", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		First(&order, orderID).Error
+	if err != nil {
+		return invoice, err
+	}
+
+	number, err := nextInvoiceNumber(tx, now.Year())
+	if err != nil {
+		return invoice, err
+	}
+
+	invoice = Invoice{
+		Number:           number,
+		OrderID:          order.ID,
+		CustomerID:       order.CustomerID,
+		CustomerEmail:    order.Customer.Email,
+		SellerName:       seller.Name,
+		SellerCompany:    seller.Company,
+		BillingAddress:   order.BillingAddress,
+		ShippingAddress:  order.ShippingAddress,
+		Currency:         currency,
+		PricesIncludeTax: order.PricesIncludeTax,
+		Subtotal:         order.Subtotal,
+		DiscountTotal:    order.DiscountTotal,
+		TaxTotal:         order.TaxTotal,
+		ShippingMethod:   order.ShippingMethod,
+		ShippingTotal:    order.ShippingTotal,
+		Total:            order.Total,
+		OrderDate:        order.OrderDate,
+		IssuedAt:         now,
+	}
+	for i, item := range order.Items {
+		invoice.Lines = append(invoice.Lines, InvoiceLine{
+			Position:  i + 1,
+			Name:      item.DisplayName(),
+			SKU:       item.DisplaySKU(),
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Discount:  item.Discount,
+			TaxRate:   item.TaxRate,
+			Tax:       item.Tax,
+			Amount:    item.PaidAmount(order.PricesIncludeTax),
+		})
+	}
+	return invoice, tx.Create(&invoice).Error
+}
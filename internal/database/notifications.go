@@ -0,0 +1,148 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Notification types, one for every event customers are emailed about.
+const (
+	NotificationAccountRegistered = "account.registered"
+	NotificationPasswordChanged   = "password.changed"
+	NotificationOrderPlaced       = "order.placed"
+	NotificationOrderPaid         = "order.paid"
+	NotificationOrderShipped      = "order.shipped"
+	NotificationOrderCancelled    = "order.cancelled"
+)
+
+// OptionalNotifications are the notification types customers may turn off.
+// Notices about the account itself are always sent.
+var OptionalNotifications = []string{
+	NotificationOrderPlaced,
+	NotificationOrderPaid,
+	NotificationOrderShipped,
+	NotificationOrderCancelled,
+}
+
+// Notification statuses. A notification waits pending in the outbox until it
+// is sent, or fails for good once it has run out of attempts.
+const (
+	NotificationStatusPending = "pending"
+	NotificationStatusSent    = "sent"
+	NotificationStatusFailed  = "failed"
+)
+
+// Notification is an email to a customer in the outbox. It is stored in the
+// transaction of the event it reports and rendered when it is sent, in the
+// locale the customer had chosen at the time of the event.
+type Notification struct {
+	ID         uint   `gorm:"primaryKey"`
+	CustomerID uint   `gorm:"not null;index"`
+	Type       string `gorm:"type:varchar(50);not null"`
+	Locale     string `gorm:"type:varchar(10);not null"`
+	Recipient  string `gorm:"type:varchar(255);not null"`
+	OrderID    *uint  `gorm:"index"`
+	ShipmentID *uint
+	Status     string    `gorm:"type:varchar(20);not null;index:idx_notifications_outbox"`
+	Attempts   int       `gorm:"not null;default:0"`
+	LastError  string    `gorm:"type:text"`
+	SendAfter  time.Time `gorm:"not null;index:idx_notifications_outbox"`
+	SentAt     *time.Time
+	CreatedAt  time.Time
+}
+
+// NotificationPreference holds the choices of a customer about emails.
+// Customers without preferences get every notification in the default locale.
+type NotificationPreference struct {
+	CustomerID uint       `gorm:"primaryKey;autoIncrement:false"`
+	Locale     string     `gorm:"type:varchar(10)"`
+	Disabled   StringList `gorm:"type:jsonb;not null" swaggertype:"array,string"`
+	UpdatedAt  time.Time
+}
+
+// Enabled reports whether the customer wants notifications of the type.
+func (p NotificationPreference) Enabled(notificationType string) bool {
+	for _, disabled := range p.Disabled {
+		if disabled == notificationType {
+			return false
+		}
+	}
+	return true
+}
+
+// FindNotificationPreference returns the preferences of the customer, or the
+// defaults if they have none.
+func FindNotificationPreference(tx *gorm.DB, customerID uint) (NotificationPreference, error) {
+	preference := NotificationPreference{CustomerID: customerID, Disabled: StringList{}}
+	err := tx.First(&preference, customerID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return preference, nil
+	}
+	return preference, err
+}
+
+// NotificationEvent describes what a notification is about. OrderID and
+// ShipmentID are set for the order notifications.
+type NotificationEvent struct {
+	Type       string
+	CustomerID uint
+	OrderID    *uint
+	ShipmentID *uint
+}
+
+// EnqueueNotification puts an email about the event into the outbox, unless
+// the customer has turned notifications of its type off. It should run in the
+// transaction of the event, so the email is sent if and only if the event
+// happened.
+func EnqueueNotification(tx *gorm.DB, event NotificationEvent) error {
+	preference, err := FindNotificationPreference(tx, event.CustomerID)
+	if err != nil {
+		return err
+	}
+	if !preference.Enabled(event.Type) {
+		return nil
+	}
+
+	var customer Customer
+	if err := tx.Select("id", "email").First(&customer, event.CustomerID).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&Notification{
+		CustomerID: event.CustomerID,
+		Type:       event.Type,
+		Locale:     preference.Locale,
+		Recipient:  customer.Email,
+		OrderID:    event.OrderID,
+		ShipmentID: event.ShipmentID,
+		Status:     NotificationStatusPending,
+		SendAfter:  time.Now(),
+	}).Error
+}
+
+// DueNotifications returns up to limit pending notifications whose time to be
+// sent has come, oldest first.
+func DueNotifications(tx *gorm.DB, now time.Time, limit int) ([]Notification, error) {
+	var notifications []Notification
+	err := tx.Where("status = ? AND send_after <= ?", NotificationStatusPending, now).
+		Order("send_after, id").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// EnqueueOrderNotification puts an email about an event of the order into the
+// outbox of its customer. shipmentID is set for order.shipped.
+func EnqueueOrderNotification(tx *gorm.DB, notificationType string, orderID uint, shipmentID *uint) error {
+	var order Order
+	if err := tx.Select("id", "customer_id").First(&order, orderID).Error; err != nil {
+		return err
+	}
+	return EnqueueNotification(tx, NotificationEvent{
+		Type:       notificationType,
+		CustomerID: order.CustomerID,
+		OrderID:    &order.ID,
+		ShipmentID: shipmentID,
+	})
+}
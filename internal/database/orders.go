@@ -0,0 +1,122 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Order statuses.
+const (
+	// OrderStatusPending is the status of a placed order awaiting payment.
+	OrderStatusPending = "Pending"
+	// OrderStatusPaid is the status of an order whose payment succeeded.
+	OrderStatusPaid = "Paid"
+	// OrderStatusShipped is the status of an order whose units have all been
+	// handed to carriers.
+	OrderStatusShipped = "Shipped"
+	// OrderStatusDelivered is the status of an order handed to the customer.
+	OrderStatusDelivered = "Delivered"
+	// OrderStatusRefunded is the status of an order refunded in full.
+	OrderStatusRefunded = "Refunded"
+	// OrderStatusCancelled is the status of an order cancelled before it was
+	// paid.
+	OrderStatusCancelled = "Cancelled"
+)
+
+// PaidOrderStatuses are the statuses of orders that have been paid.
+var PaidOrderStatuses = []string{OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered, OrderStatusRefunded}
+
+// ErrOrderNotCancellable is returned when cancelling an order that is no
+// longer awaiting payment.
+var ErrOrderNotCancellable = errors.New("only orders awaiting payment can be cancelled")
+
+// OrderStatusChange is an entry of the status history of an order. The first
+// entry of an order has an empty FromStatus. ActorID is empty for changes not
+// made by a user, such as payments reported by the provider.
+type OrderStatusChange struct {
+	ID         uint   `gorm:"primaryKey"`
+	OrderID    uint   `gorm:"not null;index"`
+	FromStatus string `gorm:"type:varchar(50)"`
+	ToStatus   string `gorm:"type:varchar(50);not null"`
+	ActorID    *uint
+	CreatedAt  time.Time
+}
+
+// RecordOrderStatus appends a status change to the history of the order and,
+// unless it is the first status of a new order, delivers order.status_changed
+// to the webhooks subscribed to it.
+func RecordOrderStatus(tx *gorm.DB, orderID uint, from, to string, actorID *uint) error {
+	err := tx.Create(&OrderStatusChange{OrderID: orderID, FromStatus: from, ToStatus: to, ActorID: actorID}).Error
+	if err != nil || from == "" {
+		return err
+	}
+	return EnqueueOrderStatusChanged(tx, orderID, from, to)
+}
+
+// CancelOrder cancels an order awaiting payment: the reserved stock of its
+// variants is put back and its coupon redemption is released. A late payment
+// webhook cannot mark it paid any more; a payment completed after all is
+// flagged for a refund by CompletePayment.
+func CancelOrder(tx *gorm.DB, order *Order, actorID *uint) error {
+	if err := LockOrder(tx, order, order.ID); err != nil {
+		return err
+	}
+	if order.Status != OrderStatusPending {
+		return ErrOrderNotCancellable
+	}
+
+	for _, item := range order.Items {
+		if item.VariantID == nil {
+			continue
+		}
+		err := tx.Unscoped().Model(&Variant{}).
+			Where("id = ?", *item.VariantID).
+			Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ReleaseCoupon(tx, order.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Model(&Order{}).Where("id = ?", order.ID).Update("status", OrderStatusCancelled).Error; err != nil {
+		return err
+	}
+	from := order.Status
+	order.Status = OrderStatusCancelled
+	return RecordOrderStatus(tx, order.ID, from, order.Status, actorID)
+}
+
+// DisplayName names the line after its product, followed by the option values
+// of the variant in the order of the product options, e.g.
+// "Футболка (L, красный)". The product must have its options loaded.
+func (i OrderItem) DisplayName() string {
+	if i.Variant == nil {
+		return i.Product.Name
+	}
+	var values []string
+	for _, option := range i.Product.Options {
+		if value, ok := i.Variant.Options[option.Name]; ok {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return i.Product.Name
+	}
+	return i.Product.Name + " (" + strings.Join(values, ", ") + ")"
+}
+
+// DisplaySKU returns the SKU of the variant, falling back to the product SKU.
+func (i OrderItem) DisplaySKU() string {
+	if i.Variant != nil && i.Variant.SKU != nil {
+		return *i.Variant.SKU
+	}
+	if i.Product.SKU != nil {
+		return *i.Product.SKU
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// Payment statuses. A payment is created pending and moves to one of the
+// other statuses once, when the provider reports its outcome.
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusCanceled  = "canceled"
+	// PaymentStatusRefunded is a succeeded payment given back in full because
+	// it did not pay for its order, see Payment.NeedsRefund.
+	PaymentStatusRefunded = "refunded"
+)
+
+var (
+	ErrPaymentInProgress = errors.New("a payment of this order is being created")
+	ErrPaymentNoRefund   = errors.New("payment does not need a refund")
+)
+
+// Payment is an attempt to pay for an order through a payment provider. An
+// order may have several, e.g. after a failed one, but only one pending at a
+// time. A payment that succeeds when its order no longer awaits payment, e.g.
+// because it was cancelled, did not pay for anything: it NeedsRefund, and
+// RefundExternalID is set once it has been given back.
+type Payment struct {
+	ID               uint    `gorm:"primaryKey"`
+	OrderID          uint    `gorm:"not null;index"`
+	Provider         string  `gorm:"type:varchar(50);not null;uniqueIndex:idx_payments_external"`
+	ExternalID       *string `gorm:"type:varchar(255);uniqueIndex:idx_payments_external"`
+	Amount           float64 `gorm:"not null"`
+	Currency         string  `gorm:"type:varchar(3);not null"`
+	Status           string  `gorm:"type:varchar(20);not null;index"`
+	ConfirmationURL  string  `gorm:"type:text"`
+	NeedsRefund      bool    `gorm:"not null;default:false;index"`
+	RefundExternalID string  `gorm:"type:varchar(255)"`
+	PaidAt           *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// CompletePayment records the outcome the provider reported for a pending
+// payment and, if it succeeded, marks the pending order as paid. A payment
+// that has already completed is left alone, so repeated webhooks have no
+// effect; changed reports whether the payment changed and orderPaid whether
+// the order moved from Pending to Paid. A payment that succeeds for an order
+// that is not Pending any more is flagged as needing a refund.
+func CompletePayment(tx *gorm.DB, payment *Payment, status string, now time.Time) (changed, orderPaid bool, err error) {
+	updates := map[string]interface{}{"status": status}
+	if status == PaymentStatusSucceeded {
+		updates["paid_at"] = now
+	}
+	result := tx.Model(&Payment{}).
+		Where("id = ? AND status = ?", payment.ID, PaymentStatusPending).
+		Updates(updates)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, false, result.Error
+	}
+
+	payment.Status = status
+	if status != PaymentStatusSucceeded {
+		return true, false, nil
+	}
+	payment.PaidAt = &now
+	result = tx.Model(&Order{}).
+		Where("id = ? AND status = ?", payment.OrderID, OrderStatusPending).
+		Update("status", OrderStatusPaid)
+	if result.Error != nil {
+		return true, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("Payment %d succeeded but order %d does not await payment, it needs a refund", payment.ID, payment.OrderID)
+		payment.NeedsRefund = true
+		return true, false, tx.Model(&Payment{}).Where("id = ?", payment.ID).Update("needs_refund", true).Error
+	}
+	return true, true, RecordOrderStatus(tx, payment.OrderID, OrderStatusPending, OrderStatusPaid, nil)
+}
+
+// PendingPayment returns the pending payment of the order, if it has one.
+func PendingPayment(tx *gorm.DB, orderID uint) (*Payment, error) {
+	var payment Payment
+	err := tx.Where("order_id = ? AND status = ?", orderID, PaymentStatusPending).First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// MarkPaymentRefunded records that a payment needing a refund was given back
+// in full.
+func MarkPaymentRefunded(tx *gorm.DB, payment *Payment, refundExternalID string) error {
+	result := tx.Model(&Payment{}).
+		Where("id = ? AND needs_refund = ?", payment.ID, true).
+		Updates(map[string]interface{}{"status": PaymentStatusRefunded, "needs_refund": false, "refund_external_id": refundExternalID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPaymentNoRefund
+	}
+	return tx.First(payment, payment.ID).Error
+}
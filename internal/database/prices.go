@@ -0,0 +1,204 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+const (
+	PriceSourceManual   = "manual"
+	PriceSourceImport   = "import"
+	PriceSourceSchedule = "schedule"
+)
+
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusActive    = "active"
+	ScheduleStatusFinished  = "finished"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// PriceChange records one change of a product price. OldPrice is empty for
+// the price a product was created with; ActorID is empty for changes made by
+// the scheduler.
+type PriceChange struct {
+	ID               uint `gorm:"primaryKey"`
+	ProductID        uint `gorm:"not null;index:idx_price_changes_product"`
+	OldPrice         *float64
+	NewPrice         float64
+	ActorID          *uint
+	Source           string `gorm:"type:varchar(20);not null"`
+	ScheduledPriceID *uint
+	ChangedAt        time.Time `gorm:"not null;index:idx_price_changes_product"`
+}
+
+// ScheduledPrice sets the product price to Price at StartsAt. With EndsAt it
+// is a sale: the product shows its previous price as CompareAtPrice and gets
+// it back at EndsAt, unless its price was edited by hand in the meantime.
+// Without EndsAt the new price is permanent.
+type ScheduledPrice struct {
+	ID          uint       `gorm:"primaryKey"`
+	ProductID   uint       `gorm:"not null;index"`
+	Price       float64    `gorm:"not null"`
+	StartsAt    time.Time  `gorm:"not null;index"`
+	EndsAt      *time.Time `gorm:"index"`
+	Status      string     `gorm:"type:varchar(20);not null;default:'pending';index"`
+	RevertPrice *float64
+	CreatedBy   *uint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RecordPriceChange stores a price change unless the price stayed the same.
+func RecordPriceChange(tx *gorm.DB, productID uint, oldPrice *float64, newPrice float64, actorID *uint, source string) error {
+	if oldPrice != nil && *oldPrice == newPrice {
+		return nil
+	}
+	return tx.Create(&PriceChange{
+		ProductID: productID,
+		OldPrice:  oldPrice,
+		NewPrice:  newPrice,
+		ActorID:   actorID,
+		Source:    source,
+		ChangedAt: time.Now(),
+	}).Error
+}
+
+// ApplyScheduledPrices starts the scheduled prices that are due and ends the
+// sales that are over. Each schedule is handled in its own transaction; the
+// status check in the update makes sure that with several application
+// instances every schedule is applied once.
+func ApplyScheduledPrices(db *gorm.DB, now time.Time) (int, error) {
+	applied := 0
+
+	var due []ScheduledPrice
+	if err := db.Where("status = ? AND starts_at <= ?", ScheduleStatusPending, now).Order("starts_at ASC").Find(&due).Error; err != nil {
+		return applied, err
+	}
+	for _, schedule := range due {
+		ok, err := startScheduledPrice(db, schedule)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	var over []ScheduledPrice
+	if err := db.Where("status = ? AND ends_at <= ?", ScheduleStatusActive, now).Order("ends_at ASC").Find(&over).Error; err != nil {
+		return applied, err
+	}
+	for _, schedule := range over {
+		ok, err := EndScheduledPrice(db, schedule, ScheduleStatusFinished)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+func startScheduledPrice(db *gorm.DB, schedule ScheduledPrice) (bool, error) {
+	started := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// The product stays locked until the price is set, so a price edited
+		// by hand meanwhile is neither overwritten nor kept as RevertPrice.
+		var product Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, schedule.ProductID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Scheduled price %d cancelled: product %d is deleted", schedule.ID, schedule.ProductID)
+				return tx.Model(&schedule).Update("status", ScheduleStatusCancelled).Error
+			}
+			return err
+		}
+
+		oldPrice := product.Price
+		updates := map[string]interface{}{"status": ScheduleStatusFinished}
+		productUpdates := map[string]interface{}{"price": schedule.Price}
+		if schedule.EndsAt != nil {
+			updates = map[string]interface{}{"status": ScheduleStatusActive, "revert_price": oldPrice}
+			productUpdates["compare_at_price"] = oldPrice
+		}
+		result := tx.Model(&ScheduledPrice{}).
+			Where("id = ? AND status = ?", schedule.ID, ScheduleStatusPending).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Model(&product).Updates(productUpdates).Error; err != nil {
+			return err
+		}
+		if err := recordScheduledChange(tx, schedule.ID, product.ID, oldPrice, schedule.Price); err != nil {
+			return err
+		}
+		started = true
+		return EnqueueProductUpdated(tx, product.ID)
+	})
+	return started, err
+}
+
+// EndScheduledPrice finishes or cancels an active sale and restores the price
+// the product had before it started. A price edited by hand during the sale is
+// kept.
+func EndScheduledPrice(db *gorm.DB, schedule ScheduledPrice, status string) (bool, error) {
+	ended := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ScheduledPrice{}).
+			Where("id = ? AND status = ?", schedule.ID, ScheduleStatusActive).
+			Update("status", status)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		ended = true
+		if schedule.RevertPrice == nil {
+			return nil
+		}
+
+		var product Product
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, schedule.ProductID).Error; err != nil {
+			return err
+		}
+		oldPrice := product.Price
+		newPrice := product.Price
+		if product.Price == schedule.Price {
+			newPrice = *schedule.RevertPrice
+		} else {
+			log.Printf("Scheduled price %d ended without restoring the price: product %d was repriced during the sale", schedule.ID, product.ID)
+		}
+
+		err := tx.Unscoped().Model(&product).Updates(map[string]interface{}{
+			"price":            newPrice,
+			"compare_at_price": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := recordScheduledChange(tx, schedule.ID, product.ID, oldPrice, newPrice); err != nil {
+			return err
+		}
+		return EnqueueProductUpdated(tx, product.ID)
+	})
+	return ended, err
+}
+
+func recordScheduledChange(tx *gorm.DB, scheduleID, productID uint, oldPrice, newPrice float64) error {
+	if oldPrice == newPrice {
+		return nil
+	}
+	return tx.Create(&PriceChange{
+		ProductID:        productID,
+		OldPrice:         &oldPrice,
+		NewPrice:         newPrice,
+		Source:           PriceSourceSchedule,
+		ScheduledPriceID: &scheduleID,
+		ChangedAt:        time.Now(),
+	}).Error
+}
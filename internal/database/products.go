@@ -0,0 +1,101 @@
+package database
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"unicode"
+)
+
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// Slugify turns a product name into a lower-case ASCII slug, transliterating
+// Russian letters: "Зеленый чай 100 г" becomes "zelenyy-chay-100-g".
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		var part string
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			part = string(r)
+		case cyrillicToLatin[r] != "":
+			part = cyrillicToLatin[r]
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			continue
+		default:
+			dash = b.Len() > 0
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// UniqueProductSlug returns a slug derived from base that is not used by any
+// other product, including products in the trash.
+func UniqueProductSlug(db *gorm.DB, base string, productID uint) (string, error) {
+	base = Slugify(base)
+	if base == "" {
+		base = "product"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		var count int64
+		err := db.Unscoped().Model(&Product{}).
+			Where("slug = ? AND id <> ?", slug, productID).
+			Count(&count).Error
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// BeforeSave fills in the slug of products that do not have one yet.
+func (p *Product) BeforeSave(tx *gorm.DB) error {
+	if p.Slug != "" {
+		return nil
+	}
+	slug, err := UniqueProductSlug(tx.Session(&gorm.Session{NewDB: true}), p.Name, p.ID)
+	if err != nil {
+		return err
+	}
+	p.Slug = slug
+	return nil
+}
+
+// backfillProductSlugs generates slugs for products created before slugs
+// were introduced.
+func backfillProductSlugs(db *gorm.DB) error {
+	var products []Product
+	if err := db.Unscoped().Where("slug IS NULL OR slug = ''").Find(&products).Error; err != nil {
+		return err
+	}
+	for i := range products {
+		if err := db.Unscoped().Save(&products[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
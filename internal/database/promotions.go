@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	// PromotionBuyXGetY discounts GetQuantity of every BuyQuantity+GetQuantity
+	// eligible units by Percent, starting from the cheapest units.
+	PromotionBuyXGetY = "buy_x_get_y"
+	// PromotionTiered takes the Percent of the highest tier whose MinSubtotal
+	// the eligible items reach off those items.
+	PromotionTiered = "tiered"
+	// PromotionBundle sells every BundleQuantity eligible units for
+	// BundlePrice, starting from the most expensive units.
+	PromotionBundle = "bundle"
+)
+
+// Promotion is a discount applied automatically at checkout. Promotions are
+// evaluated by descending Priority, then by ID, each on the prices left by the
+// previous ones. An Exclusive promotion only applies if no promotion has been
+// applied before it and stops the evaluation of the ones after it.
+type Promotion struct {
+	ID             uint   `gorm:"primaryKey"`
+	Name           string `gorm:"type:varchar(255);not null"`
+	Description    string `gorm:"type:text"`
+	Type           string `gorm:"type:varchar(20);not null"`
+	Priority       int    `gorm:"not null;default:0"`
+	Exclusive      bool   `gorm:"not null;default:false"`
+	Active         bool   `gorm:"not null;default:false"`
+	StartsAt       *time.Time
+	EndsAt         *time.Time
+	BuyQuantity    int
+	GetQuantity    int
+	Percent        float64
+	Tiers          PromotionTiers `gorm:"type:jsonb" swaggertype:"array,object"`
+	BundleQuantity int
+	BundlePrice    float64
+	Products       []Product  `gorm:"many2many:promotion_products" json:",omitempty"`
+	Categories     []Category `gorm:"many2many:promotion_categories" json:",omitempty"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type PromotionTier struct {
+	MinSubtotal float64
+	Percent     float64
+}
+
+// PromotionTiers is a list of tiers stored as a JSON array.
+type PromotionTiers []PromotionTier
+
+func (t PromotionTiers) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]PromotionTier(t))
+	return string(data), err
+}
+
+func (t *PromotionTiers) Scan(value interface{}) error {
+	return scanJSON(value, (*[]PromotionTier)(t))
+}
+
+// OrderAdjustment explains a discount applied to an order item by a promotion
+// or a coupon.
+type OrderAdjustment struct {
+	ID          uint `gorm:"primaryKey"`
+	OrderID     uint `gorm:"not null;index"`
+	OrderItemID uint `gorm:"not null;index"`
+	PromotionID *uint
+	CouponID    *uint
+	Label       string  `gorm:"type:varchar(255);not null"`
+	Amount      float64 `gorm:"not null"`
+}
+
+// AppliesTo reports whether the promotion covers the product.
+func (p Promotion) AppliesTo(productID uint, categoryID *uint) bool {
+	return matchesRestrictions(p.Products, p.Categories, productID, categoryID)
+}
+
+// ActivePromotions returns the promotions running at now in evaluation order.
+func ActivePromotions(db *gorm.DB, now time.Time) ([]Promotion, error) {
+	var promotions []Promotion
+	err := db.Preload("Products").Preload("Categories").
+		Where("active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("priority DESC, id ASC").
+		Find(&promotions).Error
+	return promotions, err
+}
+
+// matchesRestrictions reports whether a product falls under a product and
+// category restriction. Empty restrictions match every product.
+func matchesRestrictions(products []Product, categories []Category, productID uint, categoryID *uint) bool {
+	if len(products) == 0 && len(categories) == 0 {
+		return true
+	}
+	for _, product := range products {
+		if product.ID == productID {
+			return true
+		}
+	}
+	if categoryID != nil {
+		for _, category := range categories {
+			if category.ID == *categoryID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Refund statuses. A refund is stored pending before it is sent to the
+// payment provider and completes once the provider has paid it out.
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusCompleted = "completed"
+	RefundStatusFailed    = "failed"
+)
+
+var (
+	ErrRefundInProgress   = errors.New("another refund of this order is being processed")
+	ErrRefundItemNotFound = errors.New("order item not found in this order")
+	ErrRefundQuantity     = errors.New("refund quantity exceeds the quantity not yet refunded")
+	ErrRefundAmount       = errors.New("refund amount exceeds the amount not yet refunded")
+	ErrRefundEmpty        = errors.New("refund amount must be positive")
+	ErrOrderNotPaid       = errors.New("order has no successful payment")
+)
+
+// Refund is money given back for an order through its payment provider,
+// either for some of its lines, for shipping, or both. Only completed refunds
+// count towards the refunded amounts of the order.
+type Refund struct {
+	ID              uint    `gorm:"primaryKey"`
+	OrderID         uint    `gorm:"not null;index"`
+	PaymentID       uint    `gorm:"not null;index"`
+	ReturnRequestID *uint   `gorm:"index"`
+	ExternalID      string  `gorm:"type:varchar(255)"`
+	Status          string  `gorm:"type:varchar(20);not null;default:'completed';index"`
+	FailureReason   string  `gorm:"type:text"`
+	Amount          float64 `gorm:"not null"`
+	ShippingAmount  float64 `gorm:"not null;default:0"`
+	Reason          string  `gorm:"type:text"`
+	ActorID         *uint
+	Items           []RefundItem `gorm:"foreignKey:RefundID"`
+	CreatedAt       time.Time
+	CompletedAt     *time.Time
+}
+
+// RefundItem is the part of a refund given back for an order line.
+type RefundItem struct {
+	ID          uint    `gorm:"primaryKey"`
+	RefundID    uint    `gorm:"not null;index"`
+	OrderItemID uint    `gorm:"not null;index"`
+	Quantity    int     `gorm:"not null;default:0"`
+	Amount      float64 `gorm:"not null"`
+}
+
+// RefundLine asks to refund units of an order line. Without an Amount the
+// units are refunded at what was paid for them; an Amount allows refunding
+// part of the price, e.g. as compensation for a damaged item, in which case
+// Quantity may be zero.
+type RefundLine struct {
+	OrderItemID uint
+	Quantity    int
+	Amount      *float64
+}
+
+// PaidAmount returns what the customer paid for the line: its price less the
+// discounts, plus the tax when it was added on top of the price.
+func (i OrderItem) PaidAmount(pricesIncludeTax bool) float64 {
+	amount := i.Price*float64(i.Quantity) - i.Discount
+	if !pricesIncludeTax {
+		amount += i.Tax
+	}
+	return RoundMoney(amount)
+}
+
+// LockOrder loads the order with its items and locks it until the end of the
+// transaction, so that concurrent refunds cannot both pass the checks.
+func LockOrder(tx *gorm.DB, order *Order, id interface{}) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(order, id).Error
+}
+
+// SucceededPayment returns the payment that paid for the order.
+func SucceededPayment(tx *gorm.DB, orderID uint) (Payment, error) {
+	var payment Payment
+	err := tx.Where("order_id = ? AND status = ? AND needs_refund = ?", orderID, PaymentStatusSucceeded, false).
+		Order("paid_at DESC").
+		First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return payment, ErrOrderNotPaid
+	}
+	return payment, err
+}
+
+// PlanRefund checks the lines against what has already been refunded for the
+// order and returns the refund to issue. The order must have its items
+// loaded.
+func PlanRefund(order Order, lines []RefundLine, shippingAmount float64) (Refund, error) {
+	items := make(map[uint]OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+
+	refund := Refund{OrderID: order.ID, ShippingAmount: RoundMoney(shippingAmount)}
+	for _, line := range lines {
+		item, ok := items[line.OrderItemID]
+		if !ok {
+			return Refund{}, ErrRefundItemNotFound
+		}
+		// Removing the item makes a line repeated in the request fail as
+		// not found instead of being refunded twice.
+		delete(items, line.OrderItemID)
+		if line.Quantity > item.Quantity-item.RefundedQuantity {
+			return Refund{}, ErrRefundQuantity
+		}
+
+		paid := item.PaidAmount(order.PricesIncludeTax)
+		remaining := RoundMoney(paid - item.RefundedAmount)
+		var amount float64
+		switch {
+		case line.Amount != nil:
+			amount = RoundMoney(*line.Amount)
+		case item.RefundedQuantity+line.Quantity == item.Quantity:
+			// The last units take what is left, so rounding never leaves
+			// kopecks unrefunded.
+			amount = remaining
+		default:
+			amount = RoundMoney(paid * float64(line.Quantity) / float64(item.Quantity))
+		}
+		if amount > remaining {
+			return Refund{}, ErrRefundAmount
+		}
+		refund.Items = append(refund.Items, RefundItem{OrderItemID: item.ID, Quantity: line.Quantity, Amount: amount})
+		refund.Amount += amount
+	}
+
+	if refund.ShippingAmount > order.ShippingTotal {
+		return Refund{}, ErrRefundAmount
+	}
+	refund.Amount = RoundMoney(refund.Amount + refund.ShippingAmount)
+	if refund.Amount <= 0 {
+		return Refund{}, ErrRefundEmpty
+	}
+	if refund.Amount > RoundMoney(order.Total-order.RefundedTotal) {
+		return Refund{}, ErrRefundAmount
+	}
+	return refund, nil
+}
+
+// CheckNoPendingRefund returns ErrRefundInProgress while a refund of the
+// order waits for the provider. Refunds are planned against completed ones
+// only, so the order must be locked and refunded one at a time.
+func CheckNoPendingRefund(tx *gorm.DB, orderID uint) error {
+	var count int64
+	err := tx.Model(&Refund{}).Where("order_id = ? AND status = ?", orderID, RefundStatusPending).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRefundInProgress
+	}
+	return nil
+}
+
+// SaveRefund stores a planned refund as pending. It should be committed
+// before the refund is sent to the provider, so that money given back is
+// never left unrecorded.
+func SaveRefund(tx *gorm.DB, refund *Refund) error {
+	refund.Status = RefundStatusPending
+	return tx.Create(refund).Error
+}
+
+// CompleteRefund records that the provider paid out a pending refund. It adds
+// the refund to the refunded amounts of the order and its lines, moves a fully
+// refunded order to Refunded, a partly refunded one on through fulfilment with
+// UpdateFulfilmentStatus, and the return request the refund was issued for, if
+// any, to refunded. A refund that is no longer pending is left alone;
+// the result reports whether the refund changed.
+func CompleteRefund(tx *gorm.DB, refund *Refund, externalID string, now time.Time) (bool, error) {
+	result := tx.Model(&Refund{}).
+		Where("id = ? AND status = ?", refund.ID, RefundStatusPending).
+		Updates(map[string]interface{}{"status": RefundStatusCompleted, "external_id": externalID, "completed_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	if err := tx.Preload("Items").First(refund, refund.ID).Error; err != nil {
+		return true, err
+	}
+
+	var order Order
+	if err := LockOrder(tx, &order, refund.OrderID); err != nil {
+		return true, err
+	}
+	for _, item := range refund.Items {
+		err := tx.Model(&OrderItem{}).Where("id = ?", item.OrderItemID).Updates(map[string]interface{}{
+			"refunded_quantity": gorm.Expr("refunded_quantity + ?", item.Quantity),
+			"refunded_amount":   gorm.Expr("refunded_amount + ?", item.Amount),
+		}).Error
+		if err != nil {
+			return true, err
+		}
+	}
+
+	order.RefundedTotal = RoundMoney(order.RefundedTotal + refund.Amount)
+	updates := map[string]interface{}{"refunded_total": order.RefundedTotal}
+	from := order.Status
+	if order.RefundedTotal >= order.Total {
+		order.Status = OrderStatusRefunded
+		updates["status"] = order.Status
+	}
+	if err := tx.Model(&Order{}).Where("id = ?", order.ID).Updates(updates).Error; err != nil {
+		return true, err
+	}
+	if order.Status != from {
+		if err := RecordOrderStatus(tx, order.ID, from, order.Status, refund.ActorID); err != nil {
+			return true, err
+		}
+	} else {
+		// Refunding the units left to ship may complete the fulfilment.
+		if err := UpdateFulfilmentStatus(tx, order.ID, refund.ActorID); err != nil {
+			return true, err
+		}
+	}
+
+	if refund.ReturnRequestID == nil {
+		return true, nil
+	}
+	request := ReturnRequest{ID: *refund.ReturnRequestID}
+	return true, TransitionReturn(tx, &request, ReturnStatusRefunded, map[string]interface{}{"refund_id": refund.ID}, ReturnStatusReceived)
+}
+
+// FailRefund records that the provider refused a pending refund. Nothing was
+// given back, so the refunded amounts stay as they are.
+func FailRefund(tx *gorm.DB, refund *Refund, reason string) error {
+	refund.Status = RefundStatusFailed
+	refund.FailureReason = reason
+	return tx.Model(&Refund{}).
+		Where("id = ? AND status = ?", refund.ID, RefundStatusPending).
+		Updates(map[string]interface{}{"status": RefundStatusFailed, "failure_reason": reason}).Error
+}
+
+// StalePendingRefunds returns up to limit refunds that have been pending
+// since before, oldest first: refunds the shop stopped processing after
+// sending them to the provider.
+func StalePendingRefunds(tx *gorm.DB, before time.Time, limit int) ([]Refund, error) {
+	var refunds []Refund
+	err := tx.Where("status = ? AND created_at < ?", RefundStatusPending, before).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&refunds).Error
+	return refunds, err
+}
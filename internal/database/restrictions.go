@@ -0,0 +1,55 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// Restriction owners are what discounts can be restricted to.
+const (
+	RestrictionProduct  = "product_id"
+	RestrictionCategory = "category_id"
+)
+
+// restriction is a many2many table restricting a discount to products or
+// categories. Removing a product or category a discount is restricted to
+// would lift the restriction, so they cannot be deleted while referenced.
+type restriction struct {
+	joinTable     string
+	column        string
+	discountTable string
+	discountKey   string
+	nameColumn    string
+	kind          string
+}
+
+var restrictions = []restriction{
+	{"coupon_products", RestrictionProduct, "coupons", "coupon_id", "code", "coupon"},
+	{"coupon_categories", RestrictionCategory, "coupons", "coupon_id", "code", "coupon"},
+	{"promotion_products", RestrictionProduct, "promotions", "promotion_id", "name", "promotion"},
+	{"promotion_categories", RestrictionCategory, "promotions", "promotion_id", "name", "promotion"},
+}
+
+// RestrictingDiscounts returns the coupons and promotions restricted to the
+// product or category, e.g. "coupon SALE10". owner is RestrictionProduct or
+// RestrictionCategory.
+func RestrictingDiscounts(tx *gorm.DB, owner string, id uint) ([]string, error) {
+	var discounts []string
+	for _, r := range restrictions {
+		if r.column != owner {
+			continue
+		}
+		var names []string
+		err := tx.Table(r.discountTable).
+			Joins("JOIN "+r.joinTable+" ON "+r.joinTable+"."+r.discountKey+" = "+r.discountTable+".id").
+			Where(r.joinTable+"."+r.column+" = ?", id).
+			Order(r.discountTable+"."+r.nameColumn).
+			Pluck(r.discountTable+"."+r.nameColumn, &names).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			discounts = append(discounts, r.kind+" "+name)
+		}
+	}
+	return discounts, nil
+}
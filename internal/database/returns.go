@@ -0,0 +1,135 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Return request statuses. A request is approved or rejected by staff; the
+// items of an approved request are then received back and refunded.
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunded  = "refunded"
+)
+
+var (
+	ErrReturnItemNotFound = errors.New("order item not found in this order")
+	ErrReturnQuantity     = errors.New("return quantity exceeds the quantity not yet returned")
+	ErrReturnStatus       = errors.New("return request is not in a status that allows this action")
+)
+
+// ReturnRequest is a customer's request to send back items of a delivered
+// order.
+type ReturnRequest struct {
+	ID             uint         `gorm:"primaryKey"`
+	OrderID        uint         `gorm:"not null;index"`
+	CustomerID     uint         `gorm:"not null;index"`
+	Status         string       `gorm:"type:varchar(20);not null;index"`
+	Reason         string       `gorm:"type:varchar(30);not null"`
+	Comment        string       `gorm:"type:text"`
+	ResolutionNote string       `gorm:"type:text"`
+	RefundID       *uint        `gorm:"index"`
+	Items          []ReturnItem `gorm:"foreignKey:ReturnRequestID"`
+	ReceivedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type ReturnItem struct {
+	ID              uint `gorm:"primaryKey"`
+	ReturnRequestID uint `gorm:"not null;index"`
+	OrderItemID     uint `gorm:"not null;index"`
+	Quantity        int  `gorm:"not null"`
+}
+
+// ReturnLine asks to return units of an order line.
+type ReturnLine struct {
+	OrderItemID uint
+	Quantity    int
+}
+
+// PlanReturn checks the lines against the order and the units already asked
+// to be returned by requests that were not rejected, and returns the items to
+// return.
+func PlanReturn(tx *gorm.DB, order Order, lines []ReturnLine) ([]ReturnItem, error) {
+	var returned []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	err := tx.Model(&ReturnItem{}).
+		Select("return_items.order_item_id, SUM(return_items.quantity) AS quantity").
+		Joins("JOIN return_requests ON return_requests.id = return_items.return_request_id").
+		Where("return_requests.order_id = ? AND return_requests.status <> ?", order.ID, ReturnStatusRejected).
+		Group("return_items.order_item_id").
+		Scan(&returned).Error
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		available[item.ID] = item.Quantity
+	}
+	for _, r := range returned {
+		available[r.OrderItemID] -= r.Quantity
+	}
+
+	items := make([]ReturnItem, 0, len(lines))
+	for _, line := range lines {
+		left, ok := available[line.OrderItemID]
+		if !ok {
+			return nil, ErrReturnItemNotFound
+		}
+		if line.Quantity > left {
+			return nil, ErrReturnQuantity
+		}
+		available[line.OrderItemID] = left - line.Quantity
+		items = append(items, ReturnItem{OrderItemID: line.OrderItemID, Quantity: line.Quantity})
+	}
+	return items, nil
+}
+
+// TransitionReturn moves the request to the status if it is currently in one
+// of the from statuses. The check and the update are one statement, so two
+// staff members cannot, for example, both receive the same return.
+func TransitionReturn(tx *gorm.DB, request *ReturnRequest, to string, updates map[string]interface{}, from ...string) error {
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["status"] = to
+	result := tx.Model(&ReturnRequest{}).
+		Where("id = ? AND status IN ?", request.ID, from).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReturnStatus
+	}
+	return tx.Preload("Items").First(request, request.ID).Error
+}
+
+// RestockReturn puts the returned units of variants back in stock. Products
+// without variants do not track stock.
+func RestockReturn(tx *gorm.DB, request ReturnRequest) error {
+	for _, item := range request.Items {
+		var orderItem OrderItem
+		if err := tx.First(&orderItem, item.OrderItemID).Error; err != nil {
+			return err
+		}
+		if orderItem.VariantID == nil {
+			continue
+		}
+		err := tx.Unscoped().Model(&Variant{}).
+			Where("id = ?", *orderItem.VariantID).
+			Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
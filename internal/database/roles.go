@@ -0,0 +1,185 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+)
+
+const (
+	PermissionProductsManage = "products.manage"
+	PermissionOrdersView     = "orders.view"
+	PermissionOrdersManage   = "orders.manage"
+	PermissionUsersView      = "users.view"
+	PermissionUsersManage    = "users.manage"
+	PermissionRolesManage    = "roles.manage"
+	PermissionAuditView      = "audit.view"
+	PermissionCouponsManage  = "coupons.manage"
+	PermissionSettingsManage = "settings.manage"
+	PermissionWebhooksManage = "webhooks.manage"
+)
+
+const (
+	RoleAdmin          = "admin"
+	RoleCatalogManager = "catalog_manager"
+	RoleOrderFulfiller = "order_fulfiller"
+	RoleSupport        = "support"
+)
+
+type Permission struct {
+	ID          uint   `gorm:"primaryKey"`
+	Code        string `gorm:"type:varchar(100);not null;unique"`
+	Description string `gorm:"type:varchar(255)"`
+}
+
+type Role struct {
+	ID          uint         `gorm:"primaryKey"`
+	Name        string       `gorm:"type:varchar(50);not null;unique"`
+	Description string       `gorm:"type:varchar(255)"`
+	Permissions []Permission `gorm:"many2many:role_permissions"`
+}
+
+var permissionDescriptions = map[string]string{
+	PermissionProductsManage: "Create, edit and delete products",
+	PermissionOrdersView:     "View orders of all customers",
+	PermissionOrdersManage:   "Change status of orders, issue refunds and handle returns",
+	PermissionUsersView:      "View customer accounts",
+	PermissionUsersManage:    "Disable and enable customer accounts",
+	PermissionRolesManage:    "Assign and revoke staff roles",
+	PermissionAuditView:      "View and verify the audit log",
+	PermissionCouponsManage:  "Create, edit and delete coupons and promotions",
+	PermissionSettingsManage: "Configure taxes and other shop settings",
+	PermissionWebhooksManage: "Manage webhooks and their delivery log",
+}
+
+// builtinRoles is the source of truth for the permissions of each staff role.
+// SeedRoles brings the database in line with it on every start.
+var builtinRoles = []struct {
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{RoleAdmin, "Full access to the shop", []string{
+		PermissionProductsManage, PermissionOrdersView, PermissionOrdersManage,
+		PermissionUsersView, PermissionUsersManage, PermissionRolesManage,
+		PermissionAuditView, PermissionCouponsManage, PermissionSettingsManage,
+		PermissionWebhooksManage,
+	}},
+	{RoleCatalogManager, "Manages the product catalog", []string{
+		PermissionProductsManage, PermissionCouponsManage,
+	}},
+	{RoleOrderFulfiller, "Processes and ships orders", []string{
+		PermissionOrdersView, PermissionOrdersManage,
+	}},
+	{RoleSupport, "Answers customer questions", []string{
+		PermissionOrdersView, PermissionUsersView,
+	}},
+}
+
+// SeedRoles creates the built-in permissions and roles and migrates customers
+// from the legacy role column.
+func SeedRoles(db *gorm.DB) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		permissions := make(map[string]Permission, len(permissionDescriptions))
+		for code, description := range permissionDescriptions {
+			permission := Permission{Code: code, Description: description}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "code"}},
+				DoUpdates: clause.AssignmentColumns([]string{"description"}),
+			}).Create(&permission).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("code = ?", code).First(&permission).Error; err != nil {
+				return err
+			}
+			permissions[code] = permission
+		}
+
+		for _, builtin := range builtinRoles {
+			var role Role
+			err := tx.Where(Role{Name: builtin.Name}).
+				Attrs(Role{Description: builtin.Description}).
+				FirstOrCreate(&role).Error
+			if err != nil {
+				return err
+			}
+
+			rolePermissions := make([]Permission, 0, len(builtin.Permissions))
+			for _, code := range builtin.Permissions {
+				rolePermissions = append(rolePermissions, permissions[code])
+			}
+			if err := tx.Model(&role).Association("Permissions").Replace(rolePermissions); err != nil {
+				return err
+			}
+		}
+
+		return migrateLegacyRoleColumn(tx)
+	})
+	if err != nil {
+		log.Fatalf("Failed to seed roles: %v", err)
+	}
+}
+
+// migrateLegacyRoleColumn grants the admin role to customers that had
+// role = 'admin' before roles were introduced and drops the old column.
+func migrateLegacyRoleColumn(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&Customer{}, "role") {
+		return nil
+	}
+
+	var adminIDs []uint
+	if err := tx.Model(&Customer{}).Where("role = ?", RoleAdmin).Pluck("id", &adminIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range adminIDs {
+		if err := AssignRole(tx, id, RoleAdmin); err != nil {
+			return err
+		}
+	}
+	log.Printf("Migrated %d admin(s) from the legacy role column.", len(adminIDs))
+
+	return tx.Migrator().DropColumn(&Customer{}, "role")
+}
+
+// AssignRole grants a role to a customer. Granting a role the customer already
+// has is a no-op.
+func AssignRole(db *gorm.DB, customerID uint, roleName string) error {
+	var role Role
+	if err := db.Where("name = ?", roleName).First(&role).Error; err != nil {
+		return err
+	}
+	return db.Model(&Customer{ID: customerID}).Association("Roles").Append(&role)
+}
+
+// CustomerHasRole reports whether the customer has been granted the role.
+func CustomerHasRole(db *gorm.DB, customerID uint, roleName string) (bool, error) {
+	var count int64
+	err := db.Table("customer_roles").
+		Joins("JOIN roles ON roles.id = customer_roles.role_id").
+		Where("customer_roles.customer_id = ? AND roles.name = ?", customerID, roleName).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CustomerHasPermission reports whether any of the customer's roles grants
+// the permission.
+func CustomerHasPermission(db *gorm.DB, customerID uint, code string) (bool, error) {
+	var count int64
+	err := db.Table("customer_roles").
+		Joins("JOIN role_permissions ON role_permissions.role_id = customer_roles.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("customer_roles.customer_id = ? AND permissions.code = ?", customerID, code).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountRoleMembers returns the number of customers that have the role.
+func CountRoleMembers(db *gorm.DB, roleName string) (int64, error) {
+	var count int64
+	err := db.Table("customer_roles").
+		Joins("JOIN roles ON roles.id = customer_roles.role_id").
+		Where("roles.name = ?", roleName).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// productSearchVector is the expression indexed by idx_products_search. Queries
+// must use exactly the same expression for Postgres to pick the index.
+const productSearchVector = `(
+	setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'B')
+)`
+
+const (
+	highlightStart = "<b>"
+	highlightStop  = "</b>"
+	snippetLength  = 160
+)
+
+// Matches are first marked with private use characters, which survive HTML
+// escaping of the snippet, and only then turned into highlight tags.
+const (
+	matchStart = "\uE000"
+	matchStop  = "\uE001"
+)
+
+var (
+	matchStripper = strings.NewReplacer(matchStart, "", matchStop, "")
+	matchTagger   = strings.NewReplacer(matchStart, highlightStart, matchStop, highlightStop)
+)
+
+// ProductSearchHit is a product found by a search. Snippet is HTML: the text
+// of the product is escaped and the matches are wrapped in highlight tags.
+type ProductSearchHit struct {
+	Product Product `json:"product"`
+	Rank    float64 `json:"rank" example:"0.6079"`
+	Snippet string  `json:"snippet" example:"Зеленый <b>чай</b> с жасмином"`
+}
+
+// SearchProducts finds published products whose name or description match
+// every word of the query. The last characters of each word may be missing, so
+// the search also works for autocomplete. On Postgres it uses full-text search
+// with Russian and English stemming; other databases fall back to substring
+// matching.
+func SearchProducts(db *gorm.DB, query string, limit int) ([]ProductSearchHit, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []ProductSearchHit{}, nil
+	}
+
+	if db.Dialector.Name() == "postgres" {
+		return searchProductsPostgres(db, terms, limit)
+	}
+	return searchProductsFallback(db, terms, limit)
+}
+
+// searchTerms splits the query into lower-cased words, dropping everything
+// that is not a letter or a digit so that user input cannot inject tsquery
+// operators.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func searchProductsPostgres(db *gorm.DB, terms []string, limit int) ([]ProductSearchHit, error) {
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	tsquery := strings.Join(prefixes, " & ")
+
+	var rows []struct {
+		Product `gorm:"embedded"`
+		Rank    float64
+		Snippet string
+	}
+	err := db.Raw(`
+		WITH q AS (
+			SELECT to_tsquery('russian', @query) || to_tsquery('english', @query) AS query
+		)
+		SELECT products.*,
+			ts_rank(`+productSearchVector+`, q.query) AS rank,
+			ts_headline('russian', translate(coalesce(products.name, '') || '. ' || coalesce(products.description, ''), @marks, ''),
+				q.query, @options) AS snippet
+		FROM products, q
+		WHERE products.deleted_at IS NULL AND products.status = @status
+			AND `+productSearchVector+` @@ q.query
+		ORDER BY rank DESC, products.id ASC
+		LIMIT @limit`,
+		map[string]interface{}{
+			"query":   tsquery,
+			"status":  ProductStatusPublished,
+			"limit":   limit,
+			"marks":   matchStart + matchStop,
+			"options": "StartSel=" + matchStart + ", StopSel=" + matchStop + ", MinWords=5, MaxWords=25",
+		},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]ProductSearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = ProductSearchHit{Product: row.Product, Rank: row.Rank, Snippet: snippetHTML(row.Snippet)}
+	}
+	return hits, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern returns a LIKE pattern matching values that contain s
+// literally. Wildcards in s are escaped with a backslash, so the pattern must
+// be used with ESCAPE '\'.
+func ContainsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+func searchProductsFallback(db *gorm.DB, terms []string, limit int) ([]ProductSearchHit, error) {
+	tx := db.Model(&Product{}).Where("status = ?", ProductStatusPublished)
+	for _, term := range terms {
+		pattern := ContainsPattern(term)
+		tx = tx.Where(`LOWER(name) LIKE ? ESCAPE '\' OR LOWER(description) LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+
+	var products []Product
+	if err := tx.Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]ProductSearchHit, len(products))
+	for i, product := range products {
+		name := strings.ToLower(product.Name)
+		description := strings.ToLower(product.Description)
+		var rank float64
+		for _, term := range terms {
+			// Mirror the A/B weights of the Postgres vector: name matches count more.
+			rank += float64(strings.Count(name, term)) + 0.4*float64(strings.Count(description, term))
+		}
+		hits[i] = ProductSearchHit{
+			Product: product,
+			Rank:    rank,
+			Snippet: snippetHTML(highlight(matchStripper.Replace(strings.TrimSuffix(product.Name+". "+product.Description, ". ")), terms)),
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Product.ID < hits[j].Product.ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// highlight marks every occurrence of the terms in text with matchStart and
+// matchStop and cuts the text down to a window around the first match.
+func highlight(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		return text
+	}
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		termRunes := []rune(term)
+		for i := 0; i+len(termRunes) <= len(lower); i++ {
+			if string(lower[i:i+len(termRunes)]) == term {
+				for j := i; j < i+len(termRunes); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if end > snippetLength {
+		for i := range marked {
+			if marked[i] {
+				start = max(0, i-snippetLength/4)
+				break
+			}
+		}
+		end = min(len(runes), start+snippetLength)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString(matchStart)
+		}
+		b.WriteRune(runes[i])
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString(matchStop)
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// snippetHTML escapes a snippet with marked matches and turns the marks into
+// highlight tags.
+func snippetHTML(snippet string) string {
+	return matchTagger.Replace(html.EscapeString(snippet))
+}
+
+// installSearchIndex creates the GIN index used by product full-text search.
+func installSearchIndex(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_products_search ON products USING GIN (" + productSearchVector + ")").Error
+}
@@ -0,0 +1,198 @@
+package database
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Shipment statuses. A shipment is created pending while it is being packed,
+// then shipped when handed to the carrier and delivered when the customer
+// received it.
+const (
+	ShipmentStatusPending   = "pending"
+	ShipmentStatusShipped   = "shipped"
+	ShipmentStatusDelivered = "delivered"
+)
+
+var (
+	ErrShipmentItemNotFound = errors.New("order item not found in this order")
+	ErrShipmentQuantity     = errors.New("shipment quantity exceeds the quantity not yet shipped")
+	ErrShipmentEmpty        = errors.New("nothing left to ship in this order")
+	ErrShipmentStatus       = errors.New("shipment is not in a status that allows this action")
+	ErrOrderNotShippable    = errors.New("only paid orders can be shipped")
+)
+
+// Shipment is a parcel with some or all units of an order. An order may be
+// split into several shipments.
+type Shipment struct {
+	ID             uint           `gorm:"primaryKey"`
+	OrderID        uint           `gorm:"not null;index"`
+	Status         string         `gorm:"type:varchar(20);not null;index"`
+	Carrier        string         `gorm:"type:varchar(100)"`
+	TrackingNumber string         `gorm:"type:varchar(100)"`
+	TrackingURL    string         `gorm:"type:text"`
+	Items          []ShipmentItem `gorm:"foreignKey:ShipmentID"`
+	ActorID        *uint
+	ShippedAt      *time.Time
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type ShipmentItem struct {
+	ID          uint `gorm:"primaryKey"`
+	ShipmentID  uint `gorm:"not null;index"`
+	OrderItemID uint `gorm:"not null;index"`
+	Quantity    int  `gorm:"not null"`
+}
+
+// ShipmentLine asks to ship units of an order line.
+type ShipmentLine struct {
+	OrderItemID uint
+	Quantity    int
+}
+
+// unshippedQuantities returns for every line of the order the units that are
+// neither refunded nor in a shipment. The order must have its items loaded.
+func unshippedQuantities(tx *gorm.DB, order Order) (map[uint]int, error) {
+	var shipped []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	err := tx.Model(&ShipmentItem{}).
+		Select("shipment_items.order_item_id, SUM(shipment_items.quantity) AS quantity").
+		Joins("JOIN shipments ON shipments.id = shipment_items.shipment_id").
+		Where("shipments.order_id = ?", order.ID).
+		Group("shipment_items.order_item_id").
+		Scan(&shipped).Error
+	if err != nil {
+		return nil, err
+	}
+
+	left := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		left[item.ID] = item.Quantity - item.RefundedQuantity
+	}
+	for _, s := range shipped {
+		left[s.OrderItemID] -= s.Quantity
+	}
+	return left, nil
+}
+
+// PlanShipment checks the lines against the units of the paid order not yet
+// shipped and returns the items of the shipment. Without lines the shipment
+// takes every unit left. The order must have its items loaded.
+func PlanShipment(tx *gorm.DB, order Order, lines []ShipmentLine) ([]ShipmentItem, error) {
+	if order.Status != OrderStatusPaid {
+		return nil, ErrOrderNotShippable
+	}
+	left, err := unshippedQuantities(tx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		for _, item := range order.Items {
+			if left[item.ID] > 0 {
+				lines = append(lines, ShipmentLine{OrderItemID: item.ID, Quantity: left[item.ID]})
+			}
+		}
+		if len(lines) == 0 {
+			return nil, ErrShipmentEmpty
+		}
+	}
+
+	items := make([]ShipmentItem, 0, len(lines))
+	for _, line := range lines {
+		available, ok := left[line.OrderItemID]
+		if !ok {
+			return nil, ErrShipmentItemNotFound
+		}
+		if line.Quantity > available {
+			return nil, ErrShipmentQuantity
+		}
+		left[line.OrderItemID] = available - line.Quantity
+		items = append(items, ShipmentItem{OrderItemID: line.OrderItemID, Quantity: line.Quantity})
+	}
+	return items, nil
+}
+
+// TransitionShipment moves the shipment to the status if it is currently in
+// one of the from statuses, in one statement like TransitionReturn.
+func TransitionShipment(tx *gorm.DB, shipment *Shipment, to string, updates map[string]interface{}, from ...string) error {
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["status"] = to
+	result := tx.Model(&Shipment{}).
+		Where("id = ? AND status IN ?", shipment.ID, from).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShipmentStatus
+	}
+	return tx.Preload("Items").First(shipment, shipment.ID).Error
+}
+
+// UpdateFulfilmentStatus moves a paid order to Shipped once every unit that
+// was not refunded has been shipped, and a shipped order to Delivered once
+// all its shipments have been delivered, taking both steps at once when both
+// apply. Every change is recorded in the status history of the order.
+func UpdateFulfilmentStatus(tx *gorm.DB, orderID uint, actorID *uint) error {
+	var order Order
+	if err := LockOrder(tx, &order, orderID); err != nil {
+		return err
+	}
+
+	for {
+		to, err := nextFulfilmentStatus(tx, order)
+		if err != nil || to == "" {
+			return err
+		}
+		if err := tx.Model(&Order{}).Where("id = ?", order.ID).Update("status", to).Error; err != nil {
+			return err
+		}
+		if err := RecordOrderStatus(tx, order.ID, order.Status, to, actorID); err != nil {
+			return err
+		}
+		order.Status = to
+	}
+}
+
+// nextFulfilmentStatus returns the status the order moves to next, or an empty
+// string if it stays as it is.
+func nextFulfilmentStatus(tx *gorm.DB, order Order) (string, error) {
+	switch order.Status {
+	case OrderStatusPaid:
+		left, err := unshippedQuantities(tx, order)
+		if err != nil {
+			return "", err
+		}
+		for _, quantity := range left {
+			if quantity > 0 {
+				return "", nil
+			}
+		}
+		var pending int64
+		err = tx.Model(&Shipment{}).
+			Where("order_id = ? AND status = ?", order.ID, ShipmentStatusPending).
+			Count(&pending).Error
+		if err != nil || pending > 0 {
+			return "", err
+		}
+		return OrderStatusShipped, nil
+	case OrderStatusShipped:
+		var undelivered int64
+		err := tx.Model(&Shipment{}).
+			Where("order_id = ? AND status <> ?", order.ID, ShipmentStatusDelivered).
+			Count(&undelivered).Error
+		if err != nil || undelivered > 0 {
+			return "", err
+		}
+		return OrderStatusDelivered, nil
+	}
+	return "", nil
+}
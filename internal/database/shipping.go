@@ -0,0 +1,114 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"math"
+	"strings"
+	"time"
+)
+
+// Shipping method types.
+const (
+	ShippingTypeCourier = "courier"
+	ShippingTypePickup  = "pickup"
+	ShippingTypePost    = "post"
+)
+
+// ShippingMethod is a way an order can be delivered. Its cost depends on the
+// shipping zone of the address and the weight of the order, see ShippingRate.
+type ShippingMethod struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"type:varchar(100);not null"`
+	Type        string `gorm:"type:varchar(20);not null"`
+	Description string `gorm:"type:text"`
+	Active      bool   `gorm:"not null;default:false"`
+	Position    int    `gorm:"not null;default:0"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ShippingZone is a group of countries and regions delivered to at the same
+// rates.
+type ShippingZone struct {
+	ID        uint                   `gorm:"primaryKey"`
+	Name      string                 `gorm:"type:varchar(100);not null;uniqueIndex"`
+	Locations []ShippingZoneLocation `gorm:"foreignKey:ZoneID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ShippingZoneLocation is a country or, if Region is set, a region of the
+// country belonging to a zone. A location belongs to at most one zone, and a
+// region's zone takes precedence over its country's.
+type ShippingZoneLocation struct {
+	ID      uint   `gorm:"primaryKey"`
+	ZoneID  uint   `gorm:"not null;index"`
+	Country string `gorm:"type:varchar(2);not null;uniqueIndex:idx_shipping_zone_locations_location"`
+	Region  string `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_shipping_zone_locations_location"`
+}
+
+// ShippingRate is the cost of a shipping method in a zone for orders weighing
+// from MinWeight up to, but not including, MaxWeight kilograms. On top of
+// Price, PricePerKg is charged for every started kilogram above MinWeight.
+// Orders of at least FreeFrom are shipped for free.
+type ShippingRate struct {
+	ID         uint            `gorm:"primaryKey"`
+	MethodID   uint            `gorm:"not null;index"`
+	Method     *ShippingMethod `gorm:"foreignKey:MethodID" json:",omitempty"`
+	ZoneID     uint            `gorm:"not null;index"`
+	MinWeight  float64         `gorm:"not null;default:0"`
+	MaxWeight  *float64
+	Price      float64 `gorm:"not null"`
+	PricePerKg float64 `gorm:"not null;default:0"`
+	FreeFrom   *float64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Covers reports whether the rate applies to an order of the given weight.
+func (r ShippingRate) Covers(weight float64) bool {
+	return weight >= r.MinWeight && (r.MaxWeight == nil || weight < *r.MaxWeight)
+}
+
+// Cost returns the cost of shipping an order of the given weight and amount
+// at this rate.
+func (r ShippingRate) Cost(weight, amount float64) float64 {
+	if r.FreeFrom != nil && amount >= *r.FreeFrom {
+		return 0
+	}
+	cost := r.Price
+	if extra := weight - r.MinWeight; extra > 0 && r.PricePerKg > 0 {
+		cost += math.Ceil(extra) * r.PricePerKg
+	}
+	return RoundMoney(cost)
+}
+
+// FindShippingZone returns the ID of the zone the address belongs to, or nil
+// if it is not in any zone.
+func FindShippingZone(db *gorm.DB, country, region string) (*uint, error) {
+	var locations []ShippingZoneLocation
+	err := db.Where("country = ? AND region IN ?", strings.ToUpper(country), []string{"", NormalizeRegion(region)}).
+		Order("region DESC").
+		Limit(1).
+		Find(&locations).Error
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+	return &locations[0].ZoneID, nil
+}
+
+// ShippingRatesAt returns the rates of active shipping methods in the zone of
+// the address, with their methods loaded.
+func ShippingRatesAt(db *gorm.DB, country, region string) ([]ShippingRate, error) {
+	zoneID, err := FindShippingZone(db, country, region)
+	if err != nil || zoneID == nil {
+		return nil, err
+	}
+
+	var rates []ShippingRate
+	err = db.Joins("Method").
+		Where("shipping_rates.zone_id = ? AND \"Method\".active = ?", *zoneID, true).
+		Order("\"Method\".position ASC, \"Method\".id ASC, shipping_rates.min_weight ASC").
+		Find(&rates).Error
+	return rates, err
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// TaxClass groups products taxed at the same rates, e.g. standard and reduced
+// VAT. Products without a tax class are taxed by the default class.
+type TaxClass struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(100);not null;uniqueIndex"`
+	IsDefault bool   `gorm:"not null;default:false"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TaxRate is the rate of a tax class in a country or, if Region is set, in a
+// region of the country. A regional rate takes precedence over the country
+// one.
+type TaxRate struct {
+	ID         uint    `gorm:"primaryKey"`
+	TaxClassID uint    `gorm:"not null;uniqueIndex:idx_tax_rates_location"`
+	Country    string  `gorm:"type:varchar(2);not null;uniqueIndex:idx_tax_rates_location"`
+	Region     string  `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_tax_rates_location"`
+	Name       string  `gorm:"type:varchar(100);not null"`
+	Rate       float64 `gorm:"not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TaxTable holds the rates that apply at a shipping address.
+type TaxTable struct {
+	DefaultClassID *uint
+	Rates          map[uint]TaxRate
+}
+
+// Rate returns the rate for a product of the tax class, falling back to the
+// default class for products without one.
+func (t TaxTable) Rate(classID *uint) (TaxRate, bool) {
+	if classID == nil {
+		classID = t.DefaultClassID
+	}
+	if classID == nil {
+		return TaxRate{}, false
+	}
+	rate, ok := t.Rates[*classID]
+	return rate, ok
+}
+
+// LoadTaxTable returns the rates of every tax class at the address.
+func LoadTaxTable(db *gorm.DB, country, region string) (TaxTable, error) {
+	table := TaxTable{Rates: make(map[uint]TaxRate)}
+
+	var defaultClass TaxClass
+	result := db.Where("is_default = ?", true).Limit(1).Find(&defaultClass)
+	if result.Error != nil {
+		return table, result.Error
+	}
+	if result.RowsAffected > 0 {
+		table.DefaultClassID = &defaultClass.ID
+	}
+
+	var rates []TaxRate
+	err := db.Where("country = ? AND region IN ?", strings.ToUpper(country), []string{"", NormalizeRegion(region)}).
+		Find(&rates).Error
+	if err != nil {
+		return table, err
+	}
+	for _, rate := range rates {
+		if current, ok := table.Rates[rate.TaxClassID]; ok && current.Region != "" {
+			continue
+		}
+		table.Rates[rate.TaxClassID] = rate
+	}
+	return table, nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ProductOption is a dimension in which variants of a product differ, such as
+// size or colour, together with the values it can take.
+type ProductOption struct {
+	ID        uint       `gorm:"primaryKey"`
+	ProductID uint       `gorm:"not null;uniqueIndex:idx_product_options_name"`
+	Name      string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_product_options_name"`
+	Position  int        `gorm:"not null;default:0"`
+	Values    StringList `gorm:"type:jsonb;not null" swaggertype:"array,string"`
+}
+
+// Variant is a purchasable combination of option values of a product. Price
+// overrides the product price when set.
+type Variant struct {
+	ID        uint    `gorm:"primaryKey"`
+	ProductID uint    `gorm:"not null;index"`
+	SKU       *string `gorm:"type:varchar(64);uniqueIndex"`
+	Price     *float64
+	Stock     int       `gorm:"not null;default:0"`
+	Options   StringMap `gorm:"type:jsonb;not null" swaggertype:"object,string"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index" swaggertype:"string" format:"date-time"`
+}
+
+// EffectivePrice returns the variant price, falling back to the product price.
+func (v Variant) EffectivePrice(product Product) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
+
+// ReserveStock decrements the stock of the variant if enough is available and
+// reports whether it did. The check and the update happen in one statement, so
+// concurrent orders cannot oversell.
+func ReserveStock(tx *gorm.DB, variantID uint, quantity int) (bool, error) {
+	result := tx.Model(&Variant{}).
+		Where("id = ? AND stock >= ?", variantID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	return result.RowsAffected > 0, result.Error
+}
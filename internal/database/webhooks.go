@@ -0,0 +1,241 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Events integrators can subscribe webhooks to.
+const (
+	WebhookEventOrderCreated       = "order.created"
+	WebhookEventOrderStatusChanged = "order.status_changed"
+	WebhookEventProductUpdated     = "product.updated"
+	WebhookEventProductDeleted     = "product.deleted"
+	WebhookEventCustomerRegistered = "customer.registered"
+)
+
+// Delivery statuses. A delivery stays pending while it is retried and fails
+// for good once it has run out of attempts.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+var ErrWebhookDisabled = errors.New("webhook is disabled")
+
+// Webhook is an endpoint of an integrator subscribed to shop events. Requests
+// to it are signed with Secret. A webhook that keeps failing is disabled
+// automatically; its pending deliveries, and those of events raised in the
+// meantime, wait until it is enabled again.
+type Webhook struct {
+	ID                  uint       `gorm:"primaryKey"`
+	URL                 string     `gorm:"type:text;not null"`
+	Description         string     `gorm:"type:varchar(255)"`
+	Events              StringList `gorm:"type:jsonb;not null" swaggertype:"array,string"`
+	Secret              string     `gorm:"type:varchar(255);not null" json:"-"`
+	ConsecutiveFailures int        `gorm:"not null;default:0"`
+	DisabledAt          *time.Time
+	DisabledReason      string `gorm:"type:varchar(255)"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// Subscribed reports whether the webhook receives the event.
+func (w Webhook) Subscribed(event string) bool {
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook, along with
+// the outcome of the last attempt. Replaying a delivery creates a new one with
+// the same payload and event ID, so receivers can tell repeats apart.
+type WebhookDelivery struct {
+	ID             uint      `gorm:"primaryKey"`
+	WebhookID      uint      `gorm:"not null;index"`
+	EventID        string    `gorm:"type:varchar(64);not null;index"`
+	Event          string    `gorm:"type:varchar(50);not null"`
+	Payload        JSON      `gorm:"type:jsonb;not null" swaggertype:"object"`
+	Status         string    `gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_due"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_deliveries_due"`
+	ResponseStatus *int
+	ResponseBody   string `gorm:"type:text"`
+	LastError      string `gorm:"type:text"`
+	ReplayOfID     *uint
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// WebhookPayload is the body of every webhook request: the event and the
+// entity it is about, in the same form as the API returns it.
+type WebhookPayload struct {
+	ID        string      `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	Event     string      `json:"event" example:"order.created"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// OrderStatusChangedData is the data of order.status_changed events.
+type OrderStatusChangedData struct {
+	FromStatus string `json:"from_status" example:"Pending"`
+	ToStatus   string `json:"to_status" example:"Paid"`
+	Order      Order  `json:"order"`
+}
+
+// ProductDeletedData is the data of product.deleted events, sent when a
+// product is deleted for good. Moving a product to the trash is reported as
+// product.updated with DeletedAt set.
+type ProductDeletedData struct {
+	ID  uint    `json:"id" example:"1"`
+	SKU *string `json:"sku" example:"MUG-001"`
+}
+
+// NewWebhookSecret generates a random secret for signing webhook requests.
+func NewWebhookSecret() string {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return hex.EncodeToString(secret)
+}
+
+// EnqueueWebhookEvent creates a delivery of the event for every webhook
+// subscribed to it, disabled ones included: their deliveries wait until the
+// webhook is enabled. It should run in the transaction of the event, so that
+// only events that happened are delivered.
+func EnqueueWebhookEvent(tx *gorm.DB, event string, data interface{}) error {
+	var webhooks []Webhook
+	if err := tx.Find(&webhooks).Error; err != nil {
+		return err
+	}
+	var subscribed []Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribed(event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	eventID := make([]byte, 16)
+	rand.Read(eventID)
+	now := time.Now()
+	payload, err := NewJSON(WebhookPayload{
+		ID:        hex.EncodeToString(eventID),
+		Event:     event,
+		CreatedAt: now,
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]WebhookDelivery, len(subscribed))
+	for i, webhook := range subscribed {
+		deliveries[i] = WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       hex.EncodeToString(eventID),
+			Event:         event,
+			Payload:       payload,
+			Status:        WebhookDeliveryPending,
+			NextAttemptAt: now,
+		}
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// loadWebhookOrder loads the order with its customer and lines for a webhook
+// payload.
+func loadWebhookOrder(tx *gorm.DB, orderID uint) (Order, error) {
+	var order Order
+	err := tx.Preload("Customer").Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&order, orderID).Error
+	return order, err
+}
+
+// EnqueueOrderCreated delivers order.created for a newly placed order.
+func EnqueueOrderCreated(tx *gorm.DB, orderID uint) error {
+	order, err := loadWebhookOrder(tx, orderID)
+	if err != nil {
+		return err
+	}
+	return EnqueueWebhookEvent(tx, WebhookEventOrderCreated, order)
+}
+
+// EnqueueOrderStatusChanged delivers order.status_changed for the order.
+func EnqueueOrderStatusChanged(tx *gorm.DB, orderID uint, from, to string) error {
+	order, err := loadWebhookOrder(tx, orderID)
+	if err != nil {
+		return err
+	}
+	return EnqueueWebhookEvent(tx, WebhookEventOrderStatusChanged, OrderStatusChangedData{
+		FromStatus: from,
+		ToStatus:   to,
+		Order:      order,
+	})
+}
+
+// EnqueueProductUpdated delivers product.updated with the product, its
+// options, variants and images as they are now.
+func EnqueueProductUpdated(tx *gorm.DB, productID uint) error {
+	var product Product
+	err := tx.Unscoped().
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		First(&product, productID).Error
+	if err != nil {
+		return err
+	}
+	return EnqueueWebhookEvent(tx, WebhookEventProductUpdated, product)
+}
+
+// EnqueueProductDeleted delivers product.deleted for a product deleted for
+// good.
+func EnqueueProductDeleted(tx *gorm.DB, product Product) error {
+	return EnqueueWebhookEvent(tx, WebhookEventProductDeleted, ProductDeletedData{ID: product.ID, SKU: product.SKU})
+}
+
+// DueWebhookDeliveries returns up to limit pending deliveries of enabled
+// webhooks whose time to be sent has come, oldest first.
+func DueWebhookDeliveries(tx *gorm.DB, now time.Time, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := tx.Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id").
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", WebhookDeliveryPending, now).
+		Where("webhooks.disabled_at IS NULL").
+		Order("webhook_deliveries.next_attempt_at, webhook_deliveries.id").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ReplayWebhookDelivery queues the payload of the delivery to be sent to its
+// webhook again. The webhook must be enabled.
+func ReplayWebhookDelivery(tx *gorm.DB, delivery WebhookDelivery) (WebhookDelivery, error) {
+	var webhook Webhook
+	if err := tx.First(&webhook, delivery.WebhookID).Error; err != nil {
+		return WebhookDelivery{}, err
+	}
+	if webhook.DisabledAt != nil {
+		return WebhookDelivery{}, ErrWebhookDisabled
+	}
+
+	replay := WebhookDelivery{
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+		ReplayOfID:    &delivery.ID,
+	}
+	return replay, tx.Create(&replay).Error
+}
@@ -0,0 +1,103 @@
+package feed
+
+import (
+	"OnlineShop/internal/database"
+	"fmt"
+	"gorm.io/gorm"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	Yandex = "yandex"
+	Google = "google"
+)
+
+var builders = map[string]func(*gorm.DB, Shop) ([]byte, error){
+	Yandex: BuildYandex,
+	Google: BuildGoogle,
+}
+
+type entry struct {
+	generation uint64
+	version    int64
+	data       []byte
+	builtAt    time.Time
+}
+
+// Cache keeps generated feeds in memory and rebuilds a feed on the first
+// request after the catalogue has changed: after a write through db, or when
+// the catalogue version in the database moved because another process, such
+// as a command line import, changed it.
+type Cache struct {
+	db         *gorm.DB
+	shop       Shop
+	generation atomic.Uint64
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// NewCache creates the cache and registers GORM callbacks that invalidate it
+// whenever products, variants, images or categories are written through db.
+func NewCache(db *gorm.DB, shop Shop) (*Cache, error) {
+	cache := &Cache{db: db, shop: shop, entries: make(map[string]entry)}
+
+	watchedTables := make(map[string]bool, len(database.CatalogTables))
+	for _, table := range database.CatalogTables {
+		watchedTables[table] = true
+	}
+
+	invalidate := func(tx *gorm.DB) {
+		if tx.Error == nil && watchedTables[tx.Statement.Table] {
+			cache.Invalidate()
+		}
+	}
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("feed:invalidate", invalidate); err != nil {
+		return nil, err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("feed:invalidate", invalidate); err != nil {
+		return nil, err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("feed:invalidate", invalidate); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// Invalidate marks all cached feeds as stale.
+func (c *Cache) Invalidate() {
+	c.generation.Add(1)
+}
+
+// Get returns the feed with the given name and the time it was generated.
+func (c *Cache) Get(name string) ([]byte, time.Time, error) {
+	build, ok := builders[name]
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("unknown feed %q", name)
+	}
+
+	// Holding the lock while building makes concurrent requests wait for one
+	// build instead of each querying the whole catalogue.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	generation := c.generation.Load()
+	version, err := database.CurrentCatalogVersion(c.db)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if cached, ok := c.entries[name]; ok && cached.generation == generation && cached.version == version {
+		return cached.data, cached.builtAt, nil
+	}
+
+	data, err := build(c.db, c.shop)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	built := entry{generation: generation, version: version, data: data, builtAt: time.Now()}
+	c.entries[name] = built
+	return built.data, built.builtAt, nil
+}
@@ -0,0 +1,177 @@
+package feed
+
+import (
+	"OnlineShop/internal/database"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPictures is the largest number of images both marketplaces accept per
+// offer.
+const maxPictures = 10
+
+// Shop describes the store in the feed headers and is used to turn relative
+// paths into absolute links.
+type Shop struct {
+	Name     string
+	Company  string
+	URL      string
+	Currency string
+}
+
+// offer is a single purchasable item of the feed: a product without variants
+// or one variant of a product.
+type offer struct {
+	ID          string
+	GroupID     string
+	SKU         string
+	Name        string
+	Price       float64
+	OldPrice    *float64
+	Available   bool
+	Params      map[string]string
+	URL         string
+	Pictures    []string
+	CategoryID  *uint
+	Description string
+}
+
+type catalog struct {
+	categories []database.Category
+	byID       map[uint]database.Category
+	offers     []offer
+}
+
+// loadCatalog reads the categories and every published product with its
+// variants and images.
+func loadCatalog(db *gorm.DB, shop Shop) (*catalog, error) {
+	var categories []database.Category
+	if err := db.Order("id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var products []database.Product
+	err := db.
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Where("status = ?", database.ProductStatusPublished).
+		Order("id ASC").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &catalog{categories: categories, byID: make(map[uint]database.Category, len(categories))}
+	for _, category := range categories {
+		result.byID[category.ID] = category
+	}
+	for _, product := range products {
+		result.offers = append(result.offers, productOffers(product, shop)...)
+	}
+	return result, nil
+}
+
+func productOffers(product database.Product, shop Shop) []offer {
+	base := offer{
+		ID:          strconv.FormatUint(uint64(product.ID), 10),
+		Name:        product.Name,
+		Price:       product.Price,
+		OldPrice:    product.CompareAtPrice,
+		Available:   true,
+		URL:         absoluteURL(shop, "/products/slug/"+product.Slug),
+		CategoryID:  product.CategoryID,
+		Description: product.Description,
+	}
+	if product.SKU != nil {
+		base.SKU = *product.SKU
+	}
+	for i, image := range product.Images {
+		if i == maxPictures {
+			break
+		}
+		base.Pictures = append(base.Pictures, absoluteURL(shop, image.URL))
+	}
+
+	// Products without variants do not track stock and are always available.
+	if len(product.Variants) == 0 {
+		return []offer{base}
+	}
+
+	offers := make([]offer, 0, len(product.Variants))
+	for _, variant := range product.Variants {
+		o := base
+		o.ID = fmt.Sprintf("%dv%d", product.ID, variant.ID)
+		o.GroupID = base.ID
+		o.Price = variant.EffectivePrice(product)
+		// A variant with its own price is not part of the product's sale.
+		if variant.Price != nil {
+			o.OldPrice = nil
+		}
+		o.Available = variant.Stock > 0
+		o.Params = variant.Options
+		if variant.SKU != nil {
+			o.SKU = *variant.SKU
+		}
+		if len(variant.Options) > 0 {
+			o.Name = product.Name + " (" + strings.Join(sortedValues(variant.Options), ", ") + ")"
+		}
+		offers = append(offers, o)
+	}
+	return offers
+}
+
+// categoryPath returns the names of the category and its ancestors from the
+// root down.
+func (c *catalog) categoryPath(id *uint) []string {
+	var path []string
+	for seen := 0; id != nil && seen < len(c.byID); seen++ {
+		category, ok := c.byID[*id]
+		if !ok {
+			break
+		}
+		path = append([]string{category.Name}, path...)
+		id = category.ParentID
+	}
+	return path
+}
+
+func absoluteURL(shop Shop, path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimSuffix(shop.URL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func sortedKeys(options map[string]string) []string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedValues(options map[string]string) []string {
+	names := sortedKeys(options)
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = options[name]
+	}
+	return values
+}
+
+// truncate cuts s to at most limit characters.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit-1]) + "…"
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 2, 64)
+}
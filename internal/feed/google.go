@@ -0,0 +1,99 @@
+package feed
+
+import (
+	"encoding/xml"
+	"gorm.io/gorm"
+	"strings"
+)
+
+const (
+	googleNamespace        = "http://base.google.com/ns/1.0"
+	googleDescriptionLimit = 5000
+	googleTitleLimit       = 150
+)
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	Namespace string     `xml:"xmlns:g,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	Description string       `xml:"description"`
+	Items       []googleItem `xml:"item"`
+}
+
+type googleItem struct {
+	ID                   string   `xml:"g:id"`
+	Title                string   `xml:"g:title"`
+	Description          string   `xml:"g:description"`
+	Link                 string   `xml:"g:link"`
+	ImageLink            string   `xml:"g:image_link,omitempty"`
+	AdditionalImageLinks []string `xml:"g:additional_image_link"`
+	Availability         string   `xml:"g:availability"`
+	Price                string   `xml:"g:price"`
+	SalePrice            string   `xml:"g:sale_price,omitempty"`
+	Condition            string   `xml:"g:condition"`
+	ProductType          string   `xml:"g:product_type,omitempty"`
+	ItemGroupID          string   `xml:"g:item_group_id,omitempty"`
+	MPN                  string   `xml:"g:mpn,omitempty"`
+	IdentifierExists     string   `xml:"g:identifier_exists"`
+}
+
+// BuildGoogle generates the catalogue as a Google Merchant Center RSS feed.
+func BuildGoogle(db *gorm.DB, shop Shop) ([]byte, error) {
+	data, err := loadCatalog(db, shop)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := rss{
+		Version:   "2.0",
+		Namespace: googleNamespace,
+		Channel: rssChannel{
+			Title:       shop.Name,
+			Link:        shop.URL,
+			Description: shop.Company,
+			Items:       make([]googleItem, len(data.offers)),
+		},
+	}
+	for i, o := range data.offers {
+		item := googleItem{
+			ID:          o.ID,
+			Title:       truncate(o.Name, googleTitleLimit),
+			Description: truncate(o.Description, googleDescriptionLimit),
+			Link:        o.URL,
+			Price:       formatPrice(o.Price) + " " + shop.Currency,
+			Condition:   "new",
+			ProductType: strings.Join(data.categoryPath(o.CategoryID), " > "),
+			ItemGroupID: o.GroupID,
+			MPN:         o.SKU,
+			// The shop does not store brands or GTINs.
+			IdentifierExists: "no",
+		}
+		// Google expects the regular price in price and the discounted one
+		// in sale_price.
+		if o.OldPrice != nil && *o.OldPrice > o.Price {
+			item.Price = formatPrice(*o.OldPrice) + " " + shop.Currency
+			item.SalePrice = formatPrice(o.Price) + " " + shop.Currency
+		}
+		if item.Description == "" {
+			item.Description = o.Name
+		}
+		if o.Available {
+			item.Availability = "in_stock"
+		} else {
+			item.Availability = "out_of_stock"
+		}
+		if len(o.Pictures) > 0 {
+			item.ImageLink = o.Pictures[0]
+			item.AdditionalImageLinks = o.Pictures[1:]
+		}
+		feed.Channel.Items[i] = item
+	}
+
+	return marshal(feed)
+}
@@ -0,0 +1,112 @@
+package feed
+
+import (
+	"encoding/xml"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Yandex limits offer descriptions to 3000 characters.
+const yandexDescriptionLimit = 3000
+
+type ymlCatalog struct {
+	XMLName xml.Name `xml:"yml_catalog"`
+	Date    string   `xml:"date,attr"`
+	Shop    ymlShop  `xml:"shop"`
+}
+
+type ymlShop struct {
+	Name       string        `xml:"name"`
+	Company    string        `xml:"company"`
+	URL        string        `xml:"url"`
+	Currencies []ymlCurrency `xml:"currencies>currency"`
+	Categories []ymlCategory `xml:"categories>category"`
+	Offers     []ymlOffer    `xml:"offers>offer"`
+}
+
+type ymlCurrency struct {
+	ID   string `xml:"id,attr"`
+	Rate string `xml:"rate,attr"`
+}
+
+type ymlCategory struct {
+	ID       uint   `xml:"id,attr"`
+	ParentID *uint  `xml:"parentId,attr,omitempty"`
+	Name     string `xml:",chardata"`
+}
+
+type ymlOffer struct {
+	ID          string     `xml:"id,attr"`
+	GroupID     string     `xml:"group_id,attr,omitempty"`
+	Available   bool       `xml:"available,attr"`
+	Name        string     `xml:"name"`
+	URL         string     `xml:"url"`
+	Price       string     `xml:"price"`
+	OldPrice    string     `xml:"oldprice,omitempty"`
+	CurrencyID  string     `xml:"currencyId"`
+	CategoryID  *uint      `xml:"categoryId,omitempty"`
+	VendorCode  string     `xml:"vendorCode,omitempty"`
+	Pictures    []string   `xml:"picture"`
+	Description string     `xml:"description,omitempty"`
+	Params      []ymlParam `xml:"param"`
+}
+
+type ymlParam struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// BuildYandex generates the catalogue in the Yandex Market YML format.
+func BuildYandex(db *gorm.DB, shop Shop) ([]byte, error) {
+	data, err := loadCatalog(db, shop)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := ymlCatalog{
+		Date: time.Now().Format(time.RFC3339),
+		Shop: ymlShop{
+			Name:       shop.Name,
+			Company:    shop.Company,
+			URL:        shop.URL,
+			Currencies: []ymlCurrency{{ID: shop.Currency, Rate: "1"}},
+			Categories: make([]ymlCategory, len(data.categories)),
+			Offers:     make([]ymlOffer, len(data.offers)),
+		},
+	}
+	for i, category := range data.categories {
+		feed.Shop.Categories[i] = ymlCategory{ID: category.ID, ParentID: category.ParentID, Name: category.Name}
+	}
+	for i, o := range data.offers {
+		offer := ymlOffer{
+			ID:          o.ID,
+			GroupID:     o.GroupID,
+			Available:   o.Available,
+			Name:        o.Name,
+			URL:         o.URL,
+			Price:       formatPrice(o.Price),
+			CurrencyID:  shop.Currency,
+			CategoryID:  o.CategoryID,
+			VendorCode:  o.SKU,
+			Pictures:    o.Pictures,
+			Description: truncate(o.Description, yandexDescriptionLimit),
+		}
+		if o.OldPrice != nil && *o.OldPrice > o.Price {
+			offer.OldPrice = formatPrice(*o.OldPrice)
+		}
+		for _, name := range sortedKeys(o.Params) {
+			offer.Params = append(offer.Params, ymlParam{Name: name, Value: o.Params[name]})
+		}
+		feed.Shop.Offers[i] = offer
+	}
+
+	return marshal(feed)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
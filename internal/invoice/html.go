@@ -0,0 +1,55 @@
+package invoice
+
+import (
+	"OnlineShop/internal/database"
+	"html/template"
+	"io"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": formatMoney,
+	"rate":  formatRate,
+	"date":  func(t time.Time) string { return t.Format(dateFormat) },
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 40px; }
+table { border-collapse: collapse; width: 100%; margin: 16px 0; }
+th, td { border: 1px solid #999; padding: 4px 8px; }
+td.num { text-align: right; white-space: nowrap; }
+.totals td { border: none; text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Продавец: {{.Invoice.SellerCompany}} ({{.Invoice.SellerName}})</p>
+<p>Покупатель: {{.Invoice.BillingAddress.FullName}}, {{.Invoice.CustomerEmail}}<br>
+Адрес: {{.Invoice.BillingAddress.Line}}</p>
+<p>Заказ № {{.Invoice.OrderID}} от {{date .Invoice.OrderDate}}. Оплачен.</p>
+<table>
+<tr><th>№</th><th>Наименование</th><th>Артикул</th><th>Кол-во</th><th>Цена</th><th>Скидка</th><th>НДС</th><th>Сумма НДС</th><th>Сумма</th></tr>
+{{range .Invoice.Lines}}<tr><td class="num">{{.Position}}</td><td>{{.Name}}</td><td>{{.SKU}}</td><td class="num">{{.Quantity}}</td><td class="num">{{money .UnitPrice}}</td><td class="num">{{money .Discount}}</td><td class="num">{{rate .TaxRate}}</td><td class="num">{{money .Tax}}</td><td class="num">{{money .Amount}}</td></tr>
+{{end}}</table>
+<table class="totals">
+<tr><td>Товары:</td><td class="num">{{money .Invoice.Subtotal}}</td></tr>
+{{if .Invoice.DiscountTotal}}<tr><td>Скидка:</td><td class="num">-{{money .Invoice.DiscountTotal}}</td></tr>
+{{end}}{{if .Invoice.ShippingMethod}}<tr><td>Доставка ({{.Invoice.ShippingMethod}}):</td><td class="num">{{money .Invoice.ShippingTotal}}</td></tr>
+{{end}}<tr><td>{{.TaxNote}}:</td><td class="num">{{money .Invoice.TaxTotal}}</td></tr>
+<tr><td><b>Итого, {{.Invoice.Currency}}:</b></td><td class="num"><b>{{money .Invoice.Total}}</b></td></tr>
+</table>
+</body>
+</html>
+`))
+
+// RenderHTML writes the invoice as an HTML page.
+func RenderHTML(w io.Writer, invoice database.Invoice) error {
+	return htmlTemplate.Execute(w, struct {
+		Title   string
+		TaxNote string
+		Invoice database.Invoice
+	}{Title(invoice), taxNote(invoice), invoice})
+}
@@ -0,0 +1,40 @@
+// Package invoice renders issued invoices as HTML pages and PDF documents.
+package invoice
+
+import (
+	"OnlineShop/internal/database"
+	"fmt"
+	"strings"
+)
+
+// dateFormat is how dates are shown on invoices.
+const dateFormat = "02.01.2006"
+
+// Title returns the heading of the invoice, e.g. "Счет № 2025-000042 от 01.02.2025".
+func Title(invoice database.Invoice) string {
+	return fmt.Sprintf("Счет № %s от %s", invoice.Number, invoice.IssuedAt.Format(dateFormat))
+}
+
+// Filename returns the name under which the invoice is downloaded.
+func Filename(invoice database.Invoice, ext string) string {
+	return "invoice-" + invoice.Number + "." + ext
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func formatRate(rate float64) string {
+	if rate == 0 {
+		return "без НДС"
+	}
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".") + "%"
+}
+
+// taxNote explains how the tax relates to the total.
+func taxNote(invoice database.Invoice) string {
+	if invoice.PricesIncludeTax {
+		return "В том числе НДС"
+	}
+	return "НДС"
+}
@@ -0,0 +1,112 @@
+package invoice
+
+import (
+	"OnlineShop/internal/database"
+	"fmt"
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"io"
+	"strconv"
+)
+
+// The built-in PDF fonts have no Cyrillic, so the Go fonts are embedded.
+const fontFamily = "go"
+
+// Column widths of the line table in millimetres; they add up to the 180 mm
+// between the margins of an A4 page.
+var pdfColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"№", 8, "R"},
+	{"Наименование", 56, "L"},
+	{"Артикул", 22, "L"},
+	{"Кол-во", 14, "R"},
+	{"Цена", 18, "R"},
+	{"Скидка", 16, "R"},
+	{"НДС", 14, "R"},
+	{"Сумма НДС", 14, "R"},
+	{"Сумма", 18, "R"},
+}
+
+// RenderPDF writes the invoice as an A4 PDF document.
+func RenderPDF(w io.Writer, invoice database.Invoice) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.SetTitle(Title(invoice), true)
+	pdf.SetCreator(invoice.SellerName, true)
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.MultiCell(0, 8, Title(invoice), "", "L", false)
+	pdf.Ln(4)
+
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Продавец: %s (%s)", invoice.SellerCompany, invoice.SellerName), "", "L", false)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Покупатель: %s, %s", invoice.BillingAddress.FullName, invoice.CustomerEmail), "", "L", false)
+	pdf.MultiCell(0, 5, "Адрес: "+invoice.BillingAddress.Line(), "", "L", false)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Заказ № %d от %s. Оплачен.", invoice.OrderID, invoice.OrderDate.Format(dateFormat)), "", "L", false)
+	pdf.Ln(4)
+
+	pdf.SetFont(fontFamily, "B", 8)
+	for _, column := range pdfColumns {
+		pdf.CellFormat(column.width, 6, column.title, "1", 0, "C", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(fontFamily, "", 8)
+	for _, line := range invoice.Lines {
+		cells := []string{
+			strconv.Itoa(line.Position),
+			line.Name,
+			line.SKU,
+			strconv.Itoa(line.Quantity),
+			formatMoney(line.UnitPrice),
+			formatMoney(line.Discount),
+			formatRate(line.TaxRate),
+			formatMoney(line.Tax),
+			formatMoney(line.Amount),
+		}
+		// Long names wrap, so the row is as high as the tallest cell.
+		lines := 1
+		for i, cell := range cells {
+			if n := len(pdf.SplitText(cell, pdfColumns[i].width-2)); n > lines {
+				lines = n
+			}
+		}
+		height := float64(lines) * 5
+		if pdf.GetY()+height > 297-15 {
+			pdf.AddPage()
+		}
+		x, y := pdf.GetXY()
+		for i, cell := range cells {
+			pdf.Rect(x, y, pdfColumns[i].width, height, "D")
+			pdf.MultiCell(pdfColumns[i].width, 5, cell, "", pdfColumns[i].align, false)
+			x += pdfColumns[i].width
+			pdf.SetXY(x, y)
+		}
+		pdf.SetXY(15, y+height)
+	}
+	pdf.Ln(4)
+
+	total := func(label, amount string, style string) {
+		pdf.SetFont(fontFamily, style, 10)
+		pdf.CellFormat(150, 6, label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 6, amount, "", 1, "R", false, 0, "")
+	}
+	total("Товары:", formatMoney(invoice.Subtotal), "")
+	if invoice.DiscountTotal != 0 {
+		total("Скидка:", "-"+formatMoney(invoice.DiscountTotal), "")
+	}
+	if invoice.ShippingMethod != "" {
+		total(fmt.Sprintf("Доставка (%s):", invoice.ShippingMethod), formatMoney(invoice.ShippingTotal), "")
+	}
+	total(taxNote(invoice)+":", formatMoney(invoice.TaxTotal), "")
+	total(fmt.Sprintf("Итого, %s:", invoice.Currency), formatMoney(invoice.Total), "B")
+
+	return pdf.Output(w)
+}
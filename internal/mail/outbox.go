@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// Outbox writes every message to a file in a directory instead of sending it,
+// so that emails can be read during development without an SMTP server. The
+// files are named after the time they were written and open in mail clients.
+type Outbox struct {
+	dir  string
+	next atomic.Uint64
+}
+
+// NewOutbox creates the outbox, creating its directory if needed.
+func NewOutbox(dir string) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Outbox{dir: dir}, nil
+}
+
+// Dir returns the directory messages are written to.
+func (o *Outbox) Dir() string {
+	return o.dir
+}
+
+func (o *Outbox) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	body, err := msg.Bytes(now)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%06d.eml", now.UTC().Format("20060102T150405.000000000"), o.next.Add(1))
+	return os.WriteFile(filepath.Join(o.dir, name), body, 0o644)
+}
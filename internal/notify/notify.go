@@ -0,0 +1,172 @@
+// Package notify sends customers the emails waiting in the notification
+// outbox. Events put notifications into the outbox in their own transaction
+// (see database.EnqueueNotification); the Notifier renders them from
+// localised templates and hands them to a mailer, retrying failed sends.
+package notify
+
+import (
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/mail"
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// Sending is retried with growing delays, starting from retryDelay, until a
+// notification has been tried maxAttempts times.
+const (
+	maxAttempts = 5
+	retryDelay  = time.Minute
+	batchSize   = 50
+)
+
+// Shop is how the shop presents itself in emails.
+type Shop struct {
+	Name     string
+	URL      string
+	Currency string
+}
+
+// Line is an order line in an email.
+type Line struct {
+	Name     string
+	Quantity int
+	Amount   float64
+}
+
+// Data is what the templates are executed with. Order and Lines are set for
+// order notifications, Shipment for order.shipped, where Lines are the lines
+// of the shipment.
+type Data struct {
+	Shop     Shop
+	Email    string
+	Order    *database.Order
+	Shipment *database.Shipment
+	Lines    []Line
+}
+
+// Notifier delivers the notification outbox.
+type Notifier struct {
+	db     *gorm.DB
+	mailer mail.Mailer
+	from   string
+	shop   Shop
+}
+
+func New(db *gorm.DB, mailer mail.Mailer, from string, shop Shop) *Notifier {
+	return &Notifier{db: db, mailer: mailer, from: from, shop: shop}
+}
+
+// Deliver sends the notifications that are due and returns how many were
+// sent. A notification that cannot be sent is retried later, until it runs
+// out of attempts and is marked failed.
+func (n *Notifier) Deliver(ctx context.Context, now time.Time) (int, error) {
+	db := n.db.WithContext(ctx)
+	due, err := database.DueNotifications(db, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, notification := range due {
+		err := n.send(ctx, notification)
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		updates := map[string]interface{}{"attempts": notification.Attempts + 1}
+		switch {
+		case err == nil:
+			updates["status"] = database.NotificationStatusSent
+			updates["sent_at"] = now
+			updates["last_error"] = ""
+			sent++
+		case notification.Attempts+1 >= maxAttempts:
+			log.Printf("Notification %d failed for good: %v", notification.ID, err)
+			updates["status"] = database.NotificationStatusFailed
+			updates["last_error"] = err.Error()
+		default:
+			log.Printf("Notification %d failed, will retry: %v", notification.ID, err)
+			updates["send_after"] = now.Add(retryDelay << notification.Attempts)
+			updates["last_error"] = err.Error()
+		}
+		if err := db.Model(&database.Notification{}).Where("id = ?", notification.ID).Updates(updates).Error; err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// send renders the notification and hands it to the mailer.
+func (n *Notifier) send(ctx context.Context, notification database.Notification) error {
+	data, err := n.load(ctx, notification)
+	if err != nil {
+		return err
+	}
+	subject, text, html, err := render(notification.Locale, notification.Type, data)
+	if err != nil {
+		return err
+	}
+	return n.mailer.Send(ctx, mail.Message{
+		From:    n.from,
+		To:      notification.Recipient,
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+// load gathers the data of the templates: the order with its lines and, for
+// shipped orders, the shipment.
+func (n *Notifier) load(ctx context.Context, notification database.Notification) (Data, error) {
+	data := Data{Shop: n.shop, Email: notification.Recipient}
+	if notification.OrderID == nil {
+		return data, nil
+	}
+
+	db := n.db.WithContext(ctx)
+	var order database.Order
+	err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).
+		Preload("Items.Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Items.Product.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Items.Variant", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		First(&order, *notification.OrderID).Error
+	if err != nil {
+		return data, err
+	}
+	data.Order = &order
+
+	quantities := make(map[uint]int, len(order.Items))
+	if notification.ShipmentID != nil {
+		var shipment database.Shipment
+		if err := db.Preload("Items").First(&shipment, *notification.ShipmentID).Error; err != nil {
+			return data, err
+		}
+		data.Shipment = &shipment
+		for _, item := range shipment.Items {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+	}
+
+	for _, item := range order.Items {
+		line := Line{Name: item.DisplayName(), Quantity: item.Quantity, Amount: item.PaidAmount(order.PricesIncludeTax)}
+		if data.Shipment != nil {
+			if quantities[item.ID] == 0 {
+				continue
+			}
+			line.Quantity = quantities[item.ID]
+			line.Amount = 0
+		}
+		data.Lines = append(data.Lines, line)
+	}
+	return data, nil
+}
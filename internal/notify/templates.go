@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultLocale is the locale of customers who have not chosen one.
+const DefaultLocale = "ru"
+
+// Locales are the locales emails are written in.
+var Locales = []string{"ru", "en"}
+
+// Every locale has a directory of templates, one file per notification type
+// named after it, plus base.tmpl with what they share. A notification template
+// defines "subject", "text" and "html". The text templates are executed with
+// text/template and the HTML one with html/template, which escapes the data.
+//
+//go:embed templates
+var templateFS embed.FS
+
+var funcs = map[string]interface{}{
+	"money": func(amount float64) string {
+		return fmt.Sprintf("%.2f", amount)
+	},
+	"date": func(t time.Time) string {
+		return t.Format("02.01.2006")
+	},
+}
+
+type templateSet struct {
+	text *template.Template
+	html *htmltemplate.Template
+}
+
+// templates are the parsed templates by locale and notification type.
+var templates = mustParseTemplates()
+
+func mustParseTemplates() map[string]map[string]templateSet {
+	sets := make(map[string]map[string]templateSet, len(Locales))
+	for _, locale := range Locales {
+		dir := path.Join("templates", locale)
+		entries, err := templateFS.ReadDir(dir)
+		if err != nil {
+			panic(err)
+		}
+		sets[locale] = make(map[string]templateSet, len(entries))
+		for _, entry := range entries {
+			if entry.Name() == "base.tmpl" {
+				continue
+			}
+			files := []string{path.Join(dir, "base.tmpl"), path.Join(dir, entry.Name())}
+			sets[locale][strings.TrimSuffix(entry.Name(), ".tmpl")] = templateSet{
+				text: template.Must(template.New(entry.Name()).Funcs(funcs).ParseFS(templateFS, files...)),
+				html: htmltemplate.Must(htmltemplate.New(entry.Name()).Funcs(funcs).ParseFS(templateFS, files...)),
+			}
+		}
+	}
+	return sets
+}
+
+// render executes the templates of the notification type in the locale,
+// falling back to the default locale for unknown locales.
+func render(locale, notificationType string, data Data) (subject, text, html string, err error) {
+	set, ok := templates[locale][notificationType]
+	if !ok {
+		set, ok = templates[DefaultLocale][notificationType]
+	}
+	if !ok {
+		return "", "", "", fmt.Errorf("no template for notification %q", notificationType)
+	}
+
+	var buf strings.Builder
+	if err := set.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := set.text.ExecuteTemplate(&buf, "text", data); err != nil {
+		return "", "", "", err
+	}
+	text = strings.TrimSpace(buf.String()) + "\n"
+
+	buf.Reset()
+	if err := set.html.ExecuteTemplate(&buf, "html", data); err != nil {
+		return "", "", "", err
+	}
+	return subject, text, buf.String(), nil
+}
@@ -0,0 +1,69 @@
+// Package packingslip renders the printable list of what goes into a
+// shipment, to be packed with the parcel.
+package packingslip
+
+import (
+	"OnlineShop/internal/database"
+	"html/template"
+	"io"
+)
+
+type line struct {
+	Name     string
+	SKU      string
+	Quantity int
+}
+
+var htmlTemplate = template.Must(template.New("packingslip").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Упаковочный лист № {{.ShipmentID}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 40px; }
+table { border-collapse: collapse; width: 100%; margin: 16px 0; }
+th, td { border: 1px solid #999; padding: 4px 8px; }
+td.num { text-align: right; }
+td.check { width: 24px; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Упаковочный лист № {{.ShipmentID}}</h1>
+<p>{{.Shop}}. Заказ № {{.OrderID}}{{if .Method}}, доставка: {{.Method}}{{end}}</p>
+<p>Получатель: {{.Recipient}}{{if .Phone}}, {{.Phone}}{{end}}<br>
+Адрес: {{.Address}}</p>
+<table>
+<tr><th>Наименование</th><th>Артикул</th><th>Кол-во</th><th>✓</th></tr>
+{{range .Lines}}<tr><td>{{.Name}}</td><td>{{.SKU}}</td><td class="num">{{.Quantity}}</td><td class="check"></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// RenderHTML writes the packing slip of the shipment as an HTML page for
+// printing. The order must have its items loaded with their products, the
+// product options and the variants.
+func RenderHTML(w io.Writer, shop string, order database.Order, shipment database.Shipment) error {
+	items := make(map[uint]database.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+	lines := make([]line, len(shipment.Items))
+	for i, shipped := range shipment.Items {
+		item := items[shipped.OrderItemID]
+		lines[i] = line{Name: item.DisplayName(), SKU: item.DisplaySKU(), Quantity: shipped.Quantity}
+	}
+
+	address := order.ShippingAddress
+	return htmlTemplate.Execute(w, struct {
+		Shop       string
+		ShipmentID uint
+		OrderID    uint
+		Method     string
+		Recipient  string
+		Phone      string
+		Address    string
+		Lines      []line
+	}{shop, shipment.ID, order.ID, order.ShippingMethod, address.FullName, address.Phone, address.Line(), lines})
+}
@@ -0,0 +1,107 @@
+package payment
+
+import (
+	"OnlineShop/internal/database"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the webhook body, hex-encoded
+// and prefixed with "sha256=".
+const SignatureHeader = "X-Signature"
+
+// FakeURLPrefix is the path the router serves the fake provider's
+// confirmation endpoint under.
+const FakeURLPrefix = "/payments/fake"
+
+// Fake is a provider that needs no network: the customer confirms a payment
+// by posting its outcome to the shop's FakeURLPrefix endpoint, which sends
+// itself a webhook signed like a real provider would. Anyone can mark an order
+// paid that way, so the router serves the endpoint only when
+// PAYMENT_FAKE_CONFIRM is set, in development and tests.
+type Fake struct {
+	baseURL string
+	secret  []byte
+}
+
+type fakeEvent struct {
+	PaymentID string `json:"payment_id"`
+	Status    string `json:"status"`
+}
+
+func NewFake(baseURL, secret string) *Fake {
+	return &Fake{baseURL: strings.TrimSuffix(baseURL, "/"), secret: []byte(secret)}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) CreatePayment(_ context.Context, _ Request) (Session, error) {
+	externalID, err := newFakeID("fake_")
+	if err != nil {
+		return Session{}, err
+	}
+	return Session{
+		ExternalID:      externalID,
+		ConfirmationURL: f.baseURL + FakeURLPrefix + "/" + externalID,
+	}, nil
+}
+
+// Refund always succeeds immediately. The refund ID is derived from the
+// idempotency key, so repeating a refund returns the same ID.
+func (f *Fake) Refund(_ context.Context, req RefundRequest) (string, error) {
+	if req.IdempotencyKey == "" {
+		return newFakeID("fake_refund_")
+	}
+	return "fake_refund_" + hex.EncodeToString(f.sign([]byte(req.IdempotencyKey))[:12]), nil
+}
+
+// Webhook returns the signed webhook reporting the status of the payment.
+func (f *Fake) Webhook(externalID, status string) (http.Header, []byte, error) {
+	body, err := json.Marshal(fakeEvent{PaymentID: externalID, Status: status})
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set(SignatureHeader, "sha256="+hex.EncodeToString(f.sign(body)))
+	return header, body, nil
+}
+
+func (f *Fake) ParseWebhook(header http.Header, body []byte) (Event, error) {
+	signature, err := hex.DecodeString(strings.TrimPrefix(header.Get(SignatureHeader), "sha256="))
+	if err != nil || !hmac.Equal(signature, f.sign(body)) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event fakeEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.PaymentID == "" {
+		return Event{}, ErrInvalidEvent
+	}
+	switch event.Status {
+	case database.PaymentStatusSucceeded, database.PaymentStatusFailed, database.PaymentStatusCanceled:
+	default:
+		return Event{}, ErrInvalidEvent
+	}
+	return Event{ExternalID: event.PaymentID, Status: event.Status}, nil
+}
+
+func (f *Fake) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func newFakeID(prefix string) (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(id), nil
+}
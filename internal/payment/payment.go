@@ -0,0 +1,82 @@
+// Package payment talks to payment providers: it registers payments for
+// orders and verifies the webhooks providers send when a payment completes.
+package payment
+
+import (
+	"OnlineShop/config"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrInvalidSignature is returned for webhooks not signed by the provider.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrInvalidEvent is returned for webhooks that cannot be parsed.
+	ErrInvalidEvent = errors.New("invalid webhook event")
+)
+
+// Request describes a payment to register with a provider.
+type Request struct {
+	PaymentID   uint
+	OrderID     uint
+	Amount      float64
+	Currency    string
+	Description string
+}
+
+// Session is a payment registered with a provider. ConfirmationURL is where
+// the customer completes the payment.
+type Session struct {
+	ExternalID      string
+	ConfirmationURL string
+}
+
+// RefundRequest describes money to give back for a completed payment.
+// Providers pay out a refund once per IdempotencyKey, however often it is
+// requested.
+type RefundRequest struct {
+	PaymentExternalID string
+	Amount            float64
+	Currency          string
+	Reason            string
+	IdempotencyKey    string
+}
+
+// Event is a change of a payment's status reported by a provider. Status is
+// one of the final database.PaymentStatus values.
+type Event struct {
+	ExternalID string
+	Status     string
+}
+
+// Provider is a payment gateway.
+type Provider interface {
+	// Name identifies the provider in stored payments.
+	Name() string
+	CreatePayment(ctx context.Context, req Request) (Session, error)
+	// Refund gives money back to the customer and returns the provider's ID
+	// of the refund. An error means nothing was refunded.
+	Refund(ctx context.Context, req RefundRequest) (string, error)
+	// ParseWebhook verifies the signature of a webhook and returns the event
+	// it reports.
+	ParseWebhook(header http.Header, body []byte) (Event, error)
+}
+
+// New creates the provider selected by PAYMENT_PROVIDER. Only "fake", which
+// confirms payments through the shop's own API, is built in. There is no
+// default: a shop must not take orders with a provider it did not choose.
+func New(cfg *config.Config) (Provider, error) {
+	switch cfg.PaymentProvider {
+	case "":
+		return nil, errors.New("PAYMENT_PROVIDER is not set")
+	case "fake":
+		if cfg.PaymentWebhookSecret == "" {
+			return nil, errors.New("PAYMENT_WEBHOOK_SECRET is not set")
+		}
+		return NewFake(cfg.ShopURL, cfg.PaymentWebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.PaymentProvider)
+	}
+}
@@ -0,0 +1,74 @@
+package payment
+
+import (
+	"OnlineShop/internal/database"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrRefundFailed is returned when the provider did not pay out a refund.
+var ErrRefundFailed = errors.New("payment provider failed to refund")
+
+const refundBatchSize = 50
+
+// SendRefund asks the provider to pay out a pending refund and records the
+// outcome. The ID of the refund is the idempotency key of the request, so a
+// refund sent again, e.g. because the shop stopped before recording that it
+// was paid out, is never paid twice.
+func SendRefund(ctx context.Context, db *gorm.DB, provider Provider, refund *database.Refund) error {
+	var paid database.Payment
+	if err := db.First(&paid, refund.PaymentID).Error; err != nil {
+		return err
+	}
+	if paid.ExternalID == nil {
+		return fmt.Errorf("payment %d has no provider ID", paid.ID)
+	}
+
+	externalID, err := provider.Refund(ctx, RefundRequest{
+		PaymentExternalID: *paid.ExternalID,
+		Amount:            refund.Amount,
+		Currency:          paid.Currency,
+		Reason:            refund.Reason,
+		IdempotencyKey:    fmt.Sprintf("refund-%d", refund.ID),
+	})
+	if err != nil {
+		// A cancelled request may still have reached the provider; the
+		// refund stays pending and is sent again later.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := database.FailRefund(db, refund, err.Error()); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		_, err := database.CompleteRefund(tx, refund, externalID, time.Now())
+		return err
+	})
+}
+
+// RetryRefunds sends the refunds that have been pending since before again
+// and returns how many of them completed.
+func RetryRefunds(ctx context.Context, db *gorm.DB, provider Provider, before time.Time) (int, error) {
+	refunds, err := database.StalePendingRefunds(db.WithContext(ctx), before, refundBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	completed := 0
+	for i := range refunds {
+		err := SendRefund(ctx, db.WithContext(ctx), provider, &refunds[i])
+		if errors.Is(err, ErrRefundFailed) {
+			continue
+		}
+		if err != nil {
+			return completed, err
+		}
+		completed++
+	}
+	return completed, nil
+}
@@ -0,0 +1,480 @@
+// Package pricing calculates the discounts and taxes of an order: automatic
+// promotions first, then the coupon, each explained per order line, then the
+// tax on what is left and finally the cost of shipping.
+package pricing
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Tax rounding modes.
+const (
+	// TaxRoundingLine rounds the tax of every line to kopecks.
+	TaxRoundingLine = "line"
+	// TaxRoundingOrder rounds the tax of the order once per rate and splits
+	// it between the lines of that rate.
+	TaxRoundingOrder = "order"
+)
+
+// ErrShippingUnavailable is returned when the chosen shipping method does not
+// deliver to the address or has no rate for the weight of the order.
+var ErrShippingUnavailable = errors.New("shipping method is not available for this order")
+
+// TaxSettings configures how tax is calculated for the whole shop.
+type TaxSettings struct {
+	// PricesIncludeTax means catalogue prices already contain the tax, as
+	// VAT does in Russia. Otherwise the tax is added on top of them.
+	PricesIncludeTax bool
+	Rounding         string
+}
+
+// Options are the rules a basket is priced with.
+type Options struct {
+	// Promotions are applied in the order given, which is expected to be
+	// the one returned by database.ActivePromotions.
+	Promotions []database.Promotion
+	Coupon     *database.Coupon
+	Taxes      database.TaxTable
+	Tax        TaxSettings
+	// ShippingRates are the rates at the shipping address, as returned by
+	// database.ShippingRatesAt. ShippingMethodID is the chosen method, if
+	// any.
+	ShippingRates    []database.ShippingRate
+	ShippingMethodID *uint
+	Now              time.Time
+}
+
+// Line is an order line being priced.
+type Line struct {
+	ProductID   uint         `json:"product_id" example:"1"`
+	VariantID   *uint        `json:"variant_id,omitempty" example:"3"`
+	CategoryID  *uint        `json:"category_id,omitempty" example:"2"`
+	TaxClassID  *uint        `json:"tax_class_id,omitempty" example:"1"`
+	Name        string       `json:"name" example:"Зеленый чай"`
+	UnitPrice   float64      `json:"unit_price" example:"350"`
+	Quantity    int          `json:"quantity" example:"3"`
+	Weight      float64      `json:"weight" example:"0.1"`
+	Subtotal    float64      `json:"subtotal" example:"1050"`
+	Discount    float64      `json:"discount" example:"350"`
+	TaxRate     float64      `json:"tax_rate" example:"20"`
+	Tax         float64      `json:"tax" example:"116.67"`
+	Total       float64      `json:"total" example:"700"`
+	Adjustments []Adjustment `json:"adjustments"`
+}
+
+// Adjustment is the part of a line discount given by one promotion or coupon.
+type Adjustment struct {
+	PromotionID *uint   `json:"promotion_id,omitempty" example:"1"`
+	CouponID    *uint   `json:"coupon_id,omitempty"`
+	Label       string  `json:"label" example:"3 по цене 2"`
+	Amount      float64 `json:"amount" example:"350"`
+}
+
+// AppliedPromotion is a promotion that discounted at least one line.
+type AppliedPromotion struct {
+	ID       uint    `json:"id" example:"1"`
+	Name     string  `json:"name" example:"3 по цене 2"`
+	Discount float64 `json:"discount" example:"350"`
+}
+
+// ShippingOption is a shipping method available for a basket and its cost.
+type ShippingOption struct {
+	MethodID    uint    `json:"method_id" example:"1"`
+	Name        string  `json:"name" example:"Курьер"`
+	Type        string  `json:"type" example:"courier"`
+	Description string  `json:"description,omitempty" example:"Доставка до двери"`
+	Cost        float64 `json:"cost" example:"300"`
+}
+
+// Quote is a priced basket. With PricesIncludeTax the tax is part of the
+// line amounts, otherwise it is added to the total. Shipping is not taxed and
+// is added to the total.
+type Quote struct {
+	Lines            []Line             `json:"lines"`
+	Promotions       []AppliedPromotion `json:"promotions"`
+	CouponCode       string             `json:"coupon_code,omitempty" example:"WELCOME10"`
+	CouponDiscount   float64            `json:"coupon_discount" example:"0"`
+	PricesIncludeTax bool               `json:"prices_include_tax" example:"true"`
+	Subtotal         float64            `json:"subtotal" example:"1050"`
+	DiscountTotal    float64            `json:"discount_total" example:"350"`
+	TaxTotal         float64            `json:"tax_total" example:"116.67"`
+	Weight           float64            `json:"weight" example:"0.3"`
+	ShippingOptions  []ShippingOption   `json:"shipping_options"`
+	ShippingMethodID *uint              `json:"shipping_method_id,omitempty" example:"1"`
+	ShippingMethod   string             `json:"shipping_method,omitempty" example:"Курьер"`
+	ShippingTotal    float64            `json:"shipping_total" example:"300"`
+	Total            float64            `json:"total" example:"1000"`
+}
+
+// line keeps the amounts of a line in kopecks while it is being priced so
+// that discounts split between units and lines add up exactly.
+type line struct {
+	amount   int64
+	discount int64
+	tax      int64
+}
+
+func (l line) net() int64 {
+	return l.amount - l.discount
+}
+
+// unit is a single item of a line with its share of the line's net amount.
+type unit struct {
+	line  int
+	price int64
+}
+
+func toKopecks(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromKopecks(amount int64) float64 {
+	return float64(amount) / 100
+}
+
+// Price applies the promotions and then the coupon to the lines and
+// calculates the tax of the discounted lines. Coupon conditions are checked
+// against the prices after promotions. Shipping is priced last, so that free
+// shipping thresholds apply to the amount actually paid for the goods.
+func Price(lines []Line, opts Options) (Quote, error) {
+	quote := Quote{
+		Lines:            make([]Line, len(lines)),
+		Promotions:       []AppliedPromotion{},
+		PricesIncludeTax: opts.Tax.PricesIncludeTax,
+	}
+	state := make([]line, len(lines))
+	for i, l := range lines {
+		l.Adjustments = []Adjustment{}
+		quote.Lines[i] = l
+		state[i] = line{amount: toKopecks(l.UnitPrice) * int64(l.Quantity)}
+	}
+
+	for _, promotion := range opts.Promotions {
+		if promotion.Exclusive && len(quote.Promotions) > 0 {
+			continue
+		}
+		discounts := promotionDiscounts(promotion, lines, state)
+		total := applyDiscounts(quote.Lines, state, discounts, func(amount int64) Adjustment {
+			id := promotion.ID
+			return Adjustment{PromotionID: &id, Label: promotion.Name, Amount: fromKopecks(amount)}
+		})
+		if total == 0 {
+			continue
+		}
+		quote.Promotions = append(quote.Promotions, AppliedPromotion{
+			ID:       promotion.ID,
+			Name:     promotion.Name,
+			Discount: fromKopecks(total),
+		})
+		if promotion.Exclusive {
+			break
+		}
+	}
+
+	if coupon := opts.Coupon; coupon != nil {
+		couponLines := make([]database.CouponLine, len(lines))
+		for i, l := range lines {
+			couponLines[i] = database.CouponLine{ProductID: l.ProductID, CategoryID: l.CategoryID, Amount: fromKopecks(state[i].net())}
+		}
+		discount, err := database.ValidateCoupon(*coupon, couponLines, opts.Now)
+		if err != nil {
+			return Quote{}, err
+		}
+
+		weights := make([]int64, len(lines))
+		for i, l := range lines {
+			if coupon.AppliesTo(l.ProductID, l.CategoryID) {
+				weights[i] = state[i].net()
+			}
+		}
+		total := applyDiscounts(quote.Lines, state, allocate(toKopecks(discount), weights), func(amount int64) Adjustment {
+			id := coupon.ID
+			return Adjustment{CouponID: &id, Label: "Coupon " + coupon.Code, Amount: fromKopecks(amount)}
+		})
+		quote.CouponCode = coupon.Code
+		quote.CouponDiscount = fromKopecks(total)
+	}
+
+	applyTaxes(quote.Lines, state, opts)
+
+	var subtotal, discount, tax, total int64
+	var weight float64
+	for i, l := range quote.Lines {
+		lineTotal := state[i].net()
+		if !opts.Tax.PricesIncludeTax {
+			lineTotal += state[i].tax
+		}
+		quote.Lines[i].Subtotal = fromKopecks(state[i].amount)
+		quote.Lines[i].Discount = fromKopecks(state[i].discount)
+		quote.Lines[i].Tax = fromKopecks(state[i].tax)
+		quote.Lines[i].Total = fromKopecks(lineTotal)
+		subtotal += state[i].amount
+		discount += state[i].discount
+		tax += state[i].tax
+		total += lineTotal
+		weight += l.Weight * float64(l.Quantity)
+	}
+	quote.Subtotal = fromKopecks(subtotal)
+	quote.DiscountTotal = fromKopecks(discount)
+	quote.TaxTotal = fromKopecks(tax)
+	quote.Weight = math.Round(weight*1000) / 1000
+
+	quote.ShippingOptions = shippingOptions(opts.ShippingRates, quote.Weight, fromKopecks(total))
+	if opts.ShippingMethodID != nil {
+		i := indexOfShippingOption(quote.ShippingOptions, *opts.ShippingMethodID)
+		if i < 0 {
+			return Quote{}, ErrShippingUnavailable
+		}
+		option := quote.ShippingOptions[i]
+		quote.ShippingMethodID = &option.MethodID
+		quote.ShippingMethod = option.Name
+		quote.ShippingTotal = option.Cost
+		total += toKopecks(option.Cost)
+	}
+	quote.Total = fromKopecks(total)
+	return quote, nil
+}
+
+// shippingOptions returns the methods that have a rate for the weight, each
+// with the cost of its cheapest such rate, keeping the order of the rates.
+func shippingOptions(rates []database.ShippingRate, weight, amount float64) []ShippingOption {
+	options := []ShippingOption{}
+	for _, rate := range rates {
+		if rate.Method == nil || !rate.Covers(weight) {
+			continue
+		}
+		cost := rate.Cost(weight, amount)
+		if i := indexOfShippingOption(options, rate.MethodID); i >= 0 {
+			options[i].Cost = min(options[i].Cost, cost)
+			continue
+		}
+		options = append(options, ShippingOption{
+			MethodID:    rate.MethodID,
+			Name:        rate.Method.Name,
+			Type:        rate.Method.Type,
+			Description: rate.Method.Description,
+			Cost:        cost,
+		})
+	}
+	return options
+}
+
+func indexOfShippingOption(options []ShippingOption, methodID uint) int {
+	for i, option := range options {
+		if option.MethodID == methodID {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyTaxes calculates the tax of every line from its discounted amount.
+// This is the only place tax amounts are rounded.
+func applyTaxes(lines []Line, state []line, opts Options) {
+	exact := make([]float64, len(lines))
+	for i, l := range lines {
+		rate, ok := opts.Taxes.Rate(l.TaxClassID)
+		if !ok || rate.Rate <= 0 {
+			continue
+		}
+		lines[i].TaxRate = rate.Rate
+		net := float64(state[i].net())
+		if opts.Tax.PricesIncludeTax {
+			exact[i] = net * rate.Rate / (100 + rate.Rate)
+		} else {
+			exact[i] = net * rate.Rate / 100
+		}
+	}
+
+	if opts.Tax.Rounding != TaxRoundingOrder {
+		for i := range lines {
+			state[i].tax = int64(math.Round(exact[i]))
+		}
+		return
+	}
+
+	// Lines with the same rate are taxed as one amount, which is then split
+	// between them in proportion to their amounts.
+	byRate := make(map[float64][]int)
+	var rates []float64
+	for i, l := range lines {
+		if exact[i] == 0 {
+			continue
+		}
+		if _, ok := byRate[l.TaxRate]; !ok {
+			rates = append(rates, l.TaxRate)
+		}
+		byRate[l.TaxRate] = append(byRate[l.TaxRate], i)
+	}
+	for _, rate := range rates {
+		indexes := byRate[rate]
+		sum := 0.0
+		weights := make([]int64, len(indexes))
+		for n, i := range indexes {
+			sum += exact[i]
+			weights[n] = state[i].net()
+		}
+		for n, amount := range allocate(int64(math.Round(sum)), weights) {
+			state[indexes[n]].tax = amount
+		}
+	}
+}
+
+// applyDiscounts adds the discounts, capped at what is left of each line, to
+// the lines and returns their sum.
+func applyDiscounts(lines []Line, state []line, discounts []int64, adjustment func(int64) Adjustment) int64 {
+	var total int64
+	for i, amount := range discounts {
+		amount = min(amount, state[i].net())
+		if amount <= 0 {
+			continue
+		}
+		state[i].discount += amount
+		lines[i].Adjustments = append(lines[i].Adjustments, adjustment(amount))
+		total += amount
+	}
+	return total
+}
+
+func promotionDiscounts(promotion database.Promotion, lines []Line, state []line) []int64 {
+	switch promotion.Type {
+	case database.PromotionBuyXGetY:
+		return buyXGetY(promotion, lines, state)
+	case database.PromotionTiered:
+		return tiered(promotion, lines, state)
+	case database.PromotionBundle:
+		return bundle(promotion, lines, state)
+	}
+	return make([]int64, len(lines))
+}
+
+// eligibleUnits splits the eligible lines into units. The net amount of a line
+// is spread over its units, the first units taking the remainder kopecks.
+func eligibleUnits(promotion database.Promotion, lines []Line, state []line) []unit {
+	var units []unit
+	for i, l := range lines {
+		if l.Quantity <= 0 || !promotion.AppliesTo(l.ProductID, l.CategoryID) {
+			continue
+		}
+		quantity := int64(l.Quantity)
+		net := state[i].net()
+		for n := int64(0); n < quantity; n++ {
+			price := net / quantity
+			if n < net%quantity {
+				price++
+			}
+			units = append(units, unit{line: i, price: price})
+		}
+	}
+	return units
+}
+
+func buyXGetY(promotion database.Promotion, lines []Line, state []line) []int64 {
+	discounts := make([]int64, len(lines))
+	groupSize := promotion.BuyQuantity + promotion.GetQuantity
+	if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+		return discounts
+	}
+
+	units := eligibleUnits(promotion, lines, state)
+	sort.SliceStable(units, func(i, j int) bool { return units[i].price < units[j].price })
+	discounted := len(units) / groupSize * promotion.GetQuantity
+	for _, u := range units[:discounted] {
+		discounts[u.line] += int64(math.Round(float64(u.price) * promotion.Percent / 100))
+	}
+	return discounts
+}
+
+func tiered(promotion database.Promotion, lines []Line, state []line) []int64 {
+	discounts := make([]int64, len(lines))
+
+	var eligible int64
+	for i, l := range lines {
+		if promotion.AppliesTo(l.ProductID, l.CategoryID) {
+			eligible += state[i].net()
+		}
+	}
+
+	percent := 0.0
+	reached := int64(-1)
+	for _, tier := range promotion.Tiers {
+		threshold := toKopecks(tier.MinSubtotal)
+		if threshold <= eligible && threshold > reached {
+			reached = threshold
+			percent = tier.Percent
+		}
+	}
+	if percent <= 0 {
+		return discounts
+	}
+
+	for i, l := range lines {
+		if promotion.AppliesTo(l.ProductID, l.CategoryID) {
+			discounts[i] = int64(math.Round(float64(state[i].net()) * percent / 100))
+		}
+	}
+	return discounts
+}
+
+func bundle(promotion database.Promotion, lines []Line, state []line) []int64 {
+	discounts := make([]int64, len(lines))
+	size := promotion.BundleQuantity
+	if size <= 0 {
+		return discounts
+	}
+	price := toKopecks(promotion.BundlePrice)
+
+	units := eligibleUnits(promotion, lines, state)
+	sort.SliceStable(units, func(i, j int) bool { return units[i].price > units[j].price })
+	for start := 0; start+size <= len(units); start += size {
+		group := units[start : start+size]
+		weights := make([]int64, len(group))
+		var sum int64
+		for i, u := range group {
+			weights[i] = u.price
+			sum += u.price
+		}
+		if sum <= price {
+			continue
+		}
+		for i, amount := range allocate(sum-price, weights) {
+			discounts[group[i].line] += amount
+		}
+	}
+	return discounts
+}
+
+// allocate splits total between the weights proportionally. Kopecks lost to
+// rounding go to the largest remainders, so the parts always add up to total.
+func allocate(total int64, weights []int64) []int64 {
+	parts := make([]int64, len(weights))
+	var sum int64
+	for _, weight := range weights {
+		sum += weight
+	}
+	if sum <= 0 || total <= 0 {
+		return parts
+	}
+	total = min(total, sum)
+
+	type remainder struct {
+		index int
+		value int64
+	}
+	remainders := make([]remainder, 0, len(weights))
+	var allocated int64
+	for i, weight := range weights {
+		parts[i] = total * weight / sum
+		allocated += parts[i]
+		remainders = append(remainders, remainder{i, total * weight % sum})
+	}
+	sort.SliceStable(remainders, func(i, j int) bool { return remainders[i].value > remainders[j].value })
+	for i := int64(0); i < total-allocated; i++ {
+		parts[remainders[i].index]++
+	}
+	return parts
+}
@@ -0,0 +1,220 @@
+package pricing
+
+import (
+	"OnlineShop/internal/database"
+	"reflect"
+	"testing"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{"even split", 90, []int64{100, 100, 100}, []int64{30, 30, 30}},
+		{"equal remainders go to the first weights", 100, []int64{100, 100, 100}, []int64{34, 33, 33}},
+		{"largest remainder gets the kopeck", 10, []int64{100, 200}, []int64{3, 7}},
+		{"remainders of three parts", 1000, []int64{3000, 2000, 1000}, []int64{500, 333, 167}},
+		{"capped at the weights", 500, []int64{100, 100}, []int64{100, 100}},
+		{"zero weight gets nothing", 4, []int64{0, 5, 3}, []int64{0, 3, 1}},
+		{"nothing to allocate", 0, []int64{1, 2}, []int64{0, 0}},
+		{"no weights", 10, []int64{0, 0}, []int64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocate(tt.total, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+func tieredPromotion(id uint, percent float64, exclusive bool) database.Promotion {
+	return database.Promotion{
+		ID:        id,
+		Name:      "promotion",
+		Type:      database.PromotionTiered,
+		Exclusive: exclusive,
+		Tiers:     database.PromotionTiers{{MinSubtotal: 0, Percent: percent}},
+	}
+}
+
+func TestPricePromotions(t *testing.T) {
+	basket := []Line{{ProductID: 1, UnitPrice: 1000, Quantity: 1}}
+	unqualified := database.Promotion{
+		ID: 9, Name: "3 по цене 2", Type: database.PromotionBuyXGetY, Exclusive: true,
+		BuyQuantity: 2, GetQuantity: 1, Percent: 100,
+	}
+
+	tests := []struct {
+		name       string
+		lines      []Line
+		promotions []database.Promotion
+		wantIDs    []uint
+		discount   float64
+		total      float64
+	}{
+		{
+			name:       "promotions stack on the discounted amount",
+			lines:      basket,
+			promotions: []database.Promotion{tieredPromotion(1, 10, false), tieredPromotion(2, 10, false)},
+			wantIDs:    []uint{1, 2},
+			discount:   190,
+			total:      810,
+		},
+		{
+			name:       "exclusive promotion is skipped after another one applied",
+			lines:      basket,
+			promotions: []database.Promotion{tieredPromotion(1, 10, false), tieredPromotion(2, 50, true)},
+			wantIDs:    []uint{1},
+			discount:   100,
+			total:      900,
+		},
+		{
+			name:       "exclusive promotion stops the ones after it",
+			lines:      basket,
+			promotions: []database.Promotion{tieredPromotion(2, 50, true), tieredPromotion(1, 10, false)},
+			wantIDs:    []uint{2},
+			discount:   500,
+			total:      500,
+		},
+		{
+			name:       "exclusive promotion that does not apply stops nothing",
+			lines:      basket,
+			promotions: []database.Promotion{unqualified, tieredPromotion(1, 10, false)},
+			wantIDs:    []uint{1},
+			discount:   100,
+			total:      900,
+		},
+		{
+			name: "cheapest unit is free",
+			lines: []Line{
+				{ProductID: 1, UnitPrice: 100, Quantity: 1},
+				{ProductID: 2, UnitPrice: 50, Quantity: 1},
+				{ProductID: 3, UnitPrice: 30, Quantity: 1},
+			},
+			promotions: []database.Promotion{{ID: 3, Name: "3 по цене 2", Type: database.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Percent: 100}},
+			wantIDs:    []uint{3},
+			discount:   30,
+			total:      150,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := Price(tt.lines, Options{Promotions: tt.promotions})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []uint
+			for _, promotion := range quote.Promotions {
+				ids = append(ids, promotion.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("applied promotions = %v, want %v", ids, tt.wantIDs)
+			}
+			if quote.DiscountTotal != tt.discount || quote.Total != tt.total {
+				t.Errorf("discount %v, total %v, want %v, %v", quote.DiscountTotal, quote.Total, tt.discount, tt.total)
+			}
+		})
+	}
+}
+
+func TestPriceBundleRemainder(t *testing.T) {
+	lines := []Line{
+		{ProductID: 1, UnitPrice: 10, Quantity: 1},
+		{ProductID: 2, UnitPrice: 20, Quantity: 1},
+		{ProductID: 3, UnitPrice: 30, Quantity: 1},
+	}
+	promotion := database.Promotion{ID: 1, Name: "3 за 50", Type: database.PromotionBundle, BundleQuantity: 3, BundlePrice: 50}
+
+	quote, err := Price(lines, Options{Promotions: []database.Promotion{promotion}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1.67, 3.33, 5}
+	for i, l := range quote.Lines {
+		if l.Discount != want[i] {
+			t.Errorf("line %d discount = %v, want %v", i, l.Discount, want[i])
+		}
+	}
+	if quote.DiscountTotal != 10 || quote.Total != 50 {
+		t.Errorf("discount %v, total %v, want 10, 50", quote.DiscountTotal, quote.Total)
+	}
+}
+
+func TestPriceTaxes(t *testing.T) {
+	taxes := database.TaxTable{
+		DefaultClassID: uintPtr(1),
+		Rates:          map[uint]database.TaxRate{1: {Rate: 20}},
+	}
+	cheap := []Line{
+		{ProductID: 1, UnitPrice: 0.1, Quantity: 1},
+		{ProductID: 2, UnitPrice: 0.1, Quantity: 1},
+		{ProductID: 3, UnitPrice: 0.1, Quantity: 1},
+	}
+
+	tests := []struct {
+		name     string
+		lines    []Line
+		tax      TaxSettings
+		lineTax  []float64
+		taxTotal float64
+		total    float64
+	}{
+		{
+			name:     "tax included in the price",
+			lines:    []Line{{ProductID: 1, UnitPrice: 33.33, Quantity: 3}},
+			tax:      TaxSettings{PricesIncludeTax: true, Rounding: TaxRoundingLine},
+			lineTax:  []float64{16.67},
+			taxTotal: 16.67,
+			total:    99.99,
+		},
+		{
+			name:     "tax added to the price",
+			lines:    []Line{{ProductID: 1, UnitPrice: 100, Quantity: 1}},
+			tax:      TaxSettings{Rounding: TaxRoundingLine},
+			lineTax:  []float64{20},
+			taxTotal: 20,
+			total:    120,
+		},
+		{
+			name:     "included tax rounded per line",
+			lines:    cheap,
+			tax:      TaxSettings{PricesIncludeTax: true, Rounding: TaxRoundingLine},
+			lineTax:  []float64{0.02, 0.02, 0.02},
+			taxTotal: 0.06,
+			total:    0.3,
+		},
+		{
+			name:     "included tax rounded per order and split",
+			lines:    cheap,
+			tax:      TaxSettings{PricesIncludeTax: true, Rounding: TaxRoundingOrder},
+			lineTax:  []float64{0.02, 0.02, 0.01},
+			taxTotal: 0.05,
+			total:    0.3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := Price(tt.lines, Options{Taxes: taxes, Tax: tt.tax})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, l := range quote.Lines {
+				if l.Tax != tt.lineTax[i] {
+					t.Errorf("line %d tax = %v, want %v", i, l.Tax, tt.lineTax[i])
+				}
+			}
+			if quote.TaxTotal != tt.taxTotal || quote.Total != tt.total {
+				t.Errorf("tax %v, total %v, want %v, %v", quote.TaxTotal, quote.Total, tt.taxTotal, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+type AddressInput struct {
+	FullName          string `json:"full_name" binding:"required" example:"Иван Иванов"`
+	Phone             string `json:"phone" example:"+79001234567"`
+	Country           string `json:"country" binding:"required,len=2" example:"RU"`
+	Region            string `json:"region" example:"Москва"`
+	City              string `json:"city" binding:"required" example:"Москва"`
+	Street            string `json:"street" binding:"required" example:"ул. Тверская, д. 1, кв. 1"`
+	PostalCode        string `json:"postal_code" example:"125009"`
+	IsDefaultShipping bool   `json:"is_default_shipping"`
+	IsDefaultBilling  bool   `json:"is_default_billing"`
+}
+
+func (input AddressInput) apply(address *database.Address) {
+	address.FullName = input.FullName
+	address.Phone = input.Phone
+	address.Country = strings.ToUpper(input.Country)
+	address.Region = input.Region
+	address.City = input.City
+	address.Street = input.Street
+	address.PostalCode = input.PostalCode
+	address.IsDefaultShipping = input.IsDefaultShipping
+	address.IsDefaultBilling = input.IsDefaultBilling
+}
+
+// findCustomerAddress loads an address by ID only if it belongs to the customer.
+func findCustomerAddress(db *gorm.DB, customerID uint, addressID interface{}) (database.Address, error) {
+	var address database.Address
+	err := db.Where("customer_id = ?", customerID).First(&address, addressID).Error
+	return address, err
+}
+
+// @Summary      Получить адресную книгу
+// @Description  Возвращает все адреса доставки и оплаты текущего пользователя.
+// @Tags         Адреса (Addresses)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   database.Address  "Список адресов пользователя"
+// @Failure      401  {object}  router.HTTPError  "Ошибка аутентификации"
+// @Failure      500  {object}  router.HTTPError  "Внутренняя ошибка сервера"
+// @Router       /users/me/addresses [get]
+func getAddresses(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var addresses []database.Address
+	if err := database.DB.Where("customer_id = ?", userID).Order("id ASC").Find(&addresses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch addresses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// @Summary      Получить адрес по ID
+// @Description  Возвращает один адрес из адресной книги текущего пользователя.
+// @Tags         Адреса (Addresses)
+// @Produce      json
+// @Param        id   path      int  true  "ID адреса"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Address
+// @Failure      401  {object}  router.HTTPError  "Ошибка аутентификации"
+// @Failure      404  {object}  router.HTTPError  "Адрес не найден"
+// @Router       /users/me/addresses/{id} [get]
+func getAddress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	address, err := findCustomerAddress(database.DB, userID.(uint), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Address not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// @Summary      Добавить адрес
+// @Description  Добавляет адрес в адресную книгу. Первый адрес автоматически становится адресом доставки и оплаты по умолчанию.
+// @Tags         Адреса (Addresses)
+// @Accept       json
+// @Produce      json
+// @Param        address  body      router.AddressInput  true  "Данные адреса"
+// @Security     BearerAuth
+// @Success      201      {object}  database.Address
+// @Failure      400      {object}  router.HTTPError  "Ошибка валидации входных данных"
+// @Failure      401      {object}  router.HTTPError  "Ошибка аутентификации"
+// @Failure      500      {object}  router.HTTPError  "Внутренняя ошибка сервера"
+// @Router       /users/me/addresses [post]
+func createAddress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var input AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	address := database.Address{CustomerID: userID.(uint)}
+	input.apply(&address)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.Address{}).Where("customer_id = ?", address.CustomerID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefaultShipping = true
+			address.IsDefaultBilling = true
+		}
+
+		if err := tx.Create(&address).Error; err != nil {
+			return err
+		}
+		return database.ClearDefaultAddresses(tx, address.CustomerID, address.ID, address.IsDefaultShipping, address.IsDefaultBilling)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create address"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// @Summary      Обновить адрес
+// @Description  Полностью обновляет адрес из адресной книги. Изменения не затрагивают уже оформленные заказы.
+// @Tags         Адреса (Addresses)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                  true  "ID адреса"
+// @Param        address  body      router.AddressInput  true  "Новые данные адреса"
+// @Security     BearerAuth
+// @Success      200      {object}  database.Address
+// @Failure      400      {object}  router.HTTPError  "Ошибка валидации входных данных"
+// @Failure      401      {object}  router.HTTPError  "Ошибка аутентификации"
+// @Failure      404      {object}  router.HTTPError  "Адрес не найден"
+// @Failure      500      {object}  router.HTTPError  "Внутренняя ошибка сервера"
+// @Router       /users/me/addresses/{id} [put]
+func updateAddress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	address, err := findCustomerAddress(database.DB, userID.(uint), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Address not found"})
+		return
+	}
+
+	var input AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	input.apply(&address)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&address).Error; err != nil {
+			return err
+		}
+		return database.ClearDefaultAddresses(tx, address.CustomerID, address.ID, address.IsDefaultShipping, address.IsDefaultBilling)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update address"})
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// @Summary      Удалить адрес
+// @Description  Удаляет адрес из адресной книги. Снимки адреса в оформленных заказах сохраняются.
+// @Tags         Адреса (Addresses)
+// @Produce      json
+// @Param        id   path      int  true  "ID адреса"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      401  {object}  router.HTTPError  "Ошибка аутентификации"
+// @Failure      404  {object}  router.HTTPError  "Адрес не найден"
+// @Failure      500  {object}  router.HTTPError  "Внутренняя ошибка сервера"
+// @Router       /users/me/addresses/{id} [delete]
+func deleteAddress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	address, err := findCustomerAddress(database.DB, userID.(uint), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Address not found"})
+		return
+	}
+
+	if err := database.DB.Delete(&address).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete address"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Address deleted successfully"})
+}
@@ -0,0 +1,200 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"time"
+)
+
+const auditContextKey = "auditChange"
+
+// auditChange describes what a handler did, for AuditMiddleware to record.
+type auditChange struct {
+	action     string
+	entityType string
+	entityID   string
+	actorID    *uint
+	before     interface{}
+	after      interface{}
+}
+
+type AuditLogQuery struct {
+	PaginationQuery
+	ActorID    *uint     `form:"actor_id" example:"1"`
+	Action     string    `form:"action" example:"product.update"`
+	EntityType string    `form:"entity_type" example:"product"`
+	EntityID   string    `form:"entity_id" example:"42"`
+	From       time.Time `form:"from" time_format:"2006-01-02" example:"2025-01-01"`
+	To         time.Time `form:"to" time_format:"2006-01-02" example:"2025-12-31"`
+}
+
+// setAudit tells AuditMiddleware which action the handler performed and on
+// which entity. before and after are serialized to JSON as is.
+func setAudit(c *gin.Context, action, entityType string, entityID interface{}, before, after interface{}) {
+	c.Set(auditContextKey, &auditChange{
+		action:     action,
+		entityType: entityType,
+		entityID:   fmt.Sprint(entityID),
+		before:     before,
+		after:      after,
+	})
+}
+
+// setAuditActor overrides the actor of the recorded action, for handlers such
+// as login where the user is not yet known to AuthMiddleware.
+func setAuditActor(c *gin.Context, actorID uint) {
+	if value, exists := c.Get(auditContextKey); exists {
+		value.(*auditChange).actorID = &actorID
+	}
+}
+
+// currentActor returns the ID of the authenticated user, or nil for anonymous
+// requests.
+func currentActor(c *gin.Context) *uint {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return nil
+	}
+	id := userID.(uint)
+	return &id
+}
+
+// AuditMiddleware appends an audit log entry for every mutating request, every
+// denied request and every request whose handler called setAudit.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		value, hasChange := c.Get(auditContextKey)
+		status := c.Writer.Status()
+		mutating := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+		if !hasChange && !mutating && status != http.StatusForbidden {
+			return
+		}
+
+		change := &auditChange{action: c.Request.Method + " " + c.FullPath()}
+		if hasChange {
+			change = value.(*auditChange)
+		}
+		if status == http.StatusForbidden && !hasChange {
+			change.action = "auth.access_denied"
+		}
+
+		entry := database.AuditLog{
+			Action:     change.action,
+			EntityType: change.entityType,
+			EntityID:   change.entityID,
+			Method:     c.Request.Method,
+			Path:       truncate(c.Request.URL.Path, 255),
+			StatusCode: status,
+			IP:         truncate(c.ClientIP(), 64),
+			UserAgent:  truncate(c.Request.UserAgent(), 512),
+			ActorID:    change.actorID,
+		}
+		if entry.ActorID == nil {
+			entry.ActorID = currentActor(c)
+		}
+
+		var err error
+		if entry.Before, err = database.NewJSON(change.before); err != nil {
+			log.Printf("Failed to encode audit log entry: %v", err)
+		}
+		if entry.After, err = database.NewJSON(change.after); err != nil {
+			log.Printf("Failed to encode audit log entry: %v", err)
+		}
+
+		if err := database.AppendAudit(database.DB, &entry); err != nil {
+			log.Printf("Failed to write audit log entry %q: %v", entry.Action, err)
+		}
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+// @Summary      Получить журнал аудита
+// @Description  Возвращает записи журнала действий администраторов и событий безопасности, начиная с новых.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Param        actor_id     query     int     false  "ID пользователя, выполнившего действие"
+// @Param        action       query     string  false  "Действие, например product.update"
+// @Param        entity_type  query     string  false  "Тип сущности, например product"
+// @Param        entity_id    query     string  false  "ID сущности"
+// @Param        from         query     string  false  "С даты (YYYY-MM-DD)"
+// @Param        to           query     string  false  "По дату (YYYY-MM-DD), включительно"
+// @Param        page         query     int     false  "Номер страницы"  default(1)
+// @Param        page_size    query     int     false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200  {object}  router.Page[database.AuditLog]  "Страница журнала аудита"
+// @Failure      400  {object}  router.HTTPError               "Некорректные параметры запроса"
+// @Failure      403  {object}  router.HTTPError               "Недостаточно прав"
+// @Failure      500  {object}  router.HTTPError               "Внутренняя ошибка сервера"
+// @Router       /audit [get]
+func getAuditLogs(c *gin.Context) {
+	var query AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.AuditLog{})
+	if query.ActorID != nil {
+		db = db.Where("actor_id = ?", *query.ActorID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.EntityType != "" {
+		db = db.Where("entity_type = ?", query.EntityType)
+	}
+	if query.EntityID != "" {
+		db = db.Where("entity_id = ?", query.EntityID)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch audit log"})
+		return
+	}
+
+	var entries []database.AuditLog
+	if err := db.Order("id DESC").Offset(query.offset()).Limit(query.PageSize).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(entries, query.PaginationQuery, total))
+}
+
+// @Summary      Проверить целостность журнала аудита
+// @Description  Пересчитывает цепочку хешей журнала аудита и возвращает ID первой измененной или удаленной записи, если такая есть.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  database.AuditVerification  "Результат проверки"
+// @Failure      403  {object}  router.HTTPError            "Недостаточно прав"
+// @Failure      500  {object}  router.HTTPError            "Внутренняя ошибка сервера"
+// @Router       /audit/verify [get]
+func verifyAuditLog(c *gin.Context) {
+	result, err := database.VerifyAuditChain(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to verify audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
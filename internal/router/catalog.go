@@ -0,0 +1,145 @@
+package router
+
+import (
+	"OnlineShop/internal/catalog"
+	"OnlineShop/internal/database"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"time"
+)
+
+// maxImportSize limits the size of uploaded import files.
+const maxImportSize = 50 << 20
+
+type ProductImportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type ProductExportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json"`
+	Status string `form:"status" binding:"omitempty,oneof=draft published archived"`
+}
+
+// @Summary      Импортировать товары
+// @Description  CSV должен содержать заголовок с колонками sku, name, price и, по желанию, description, slug, status, attributes (JSON-объект). Цена обязательна в каждой строке, нулевую цену нужно указать явно.
+// @Description  CSV должен содержать заголовок с колонками sku, name, price и, по желанию, description, slug, status, attributes (JSON-объект).
+// @Description  Импорт выполняется в одной транзакции: если хотя бы одна строка содержит ошибку, ничего не сохраняется и возвращается отчет об ошибках по строкам.
+// @Description  С параметром dry_run файл только проверяется.
+// @Tags         Товары (Products)
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file     formData  file    true   "Файл с товарами"
+// @Param        format   query     string  false  "Формат файла (csv, json)"
+// @Param        dry_run  query     bool    false  "Только проверить файл, не сохраняя изменения"
+// @Security     BearerAuth
+// @Success      200      {object}  catalog.Report    "Отчет об импорте"
+// @Failure      400      {object}  router.HTTPError  "Файл не передан или не читается"
+// @Failure      403      {object}  router.HTTPError
+// @Failure      413      {object}  router.HTTPError  "Файл слишком большой"
+// @Failure      422      {object}  catalog.Report    "Отчет с ошибками, изменения не сохранены"
+// @Failure      500      {object}  router.HTTPError
+// @Router       /products/import [post]
+func importProducts(c *gin.Context) {
+	var query ProductImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Import file is required"})
+		return
+	}
+	if header.Size > maxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, HTTPError{Message: fmt.Sprintf("Import file must not exceed %d bytes", maxImportSize)})
+		return
+	}
+
+	formatName := query.Format
+	if formatName == "" {
+		formatName = header.Filename
+	}
+	format, err := catalog.ParseFormat(formatName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Unknown file format, pass format=csv or format=json"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Failed to read import file"})
+		return
+	}
+	defer file.Close()
+
+	records, err := catalog.Parse(file, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	report, err := catalog.Import(database.DB, records, catalog.ImportOptions{DryRun: query.DryRun, ActorID: currentActor(c)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to import products"})
+		return
+	}
+	if report.Failed > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	if report.Applied {
+		setAudit(c, "product.import", "product", "", nil, gin.H{
+			"file":    header.Filename,
+			"created": report.Created,
+			"updated": report.Updated,
+		})
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary      Экспортировать товары
+// @Description  Выгружает все товары, кроме удаленных, в CSV или JSON в том же формате, который принимает импорт.
+// @Description  Ответ передается потоком, поэтому подходит для больших каталогов.
+// @Tags         Товары (Products)
+// @Produce      text/csv
+// @Produce      json
+// @Param        format  query     string  false  "Формат файла (csv, json)"  default(csv)
+// @Param        status  query     string  false  "Статус (draft, published, archived)"
+// @Security     BearerAuth
+// @Success      200     {array}   catalog.Row
+// @Failure      400     {object}  router.HTTPError
+// @Failure      403     {object}  router.HTTPError
+// @Router       /products/export [get]
+func exportProducts(c *gin.Context) {
+	var query ProductExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	format := catalog.FormatCSV
+	contentType := "text/csv; charset=utf-8"
+	if query.Format == string(catalog.FormatJSON) {
+		format = catalog.FormatJSON
+		contentType = "application/json; charset=utf-8"
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// The status line has already been sent, so a failure can only cut the
+	// response short.
+	if err := catalog.Export(database.DB, c.Writer, format, catalog.ExportOptions{Status: query.Status}); err != nil {
+		log.Printf("Failed to export products: %v", err)
+		c.Abort()
+		return
+	}
+	setAudit(c, "product.export", "product", "", nil, gin.H{"format": format, "status": query.Status})
+}
@@ -0,0 +1,205 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+var (
+	errCategoryNotFound = errors.New("category not found")
+	errCategoryInUse    = errors.New("category has subcategories or products")
+	errCategoryDiscount = errors.New("category is referenced by discounts")
+)
+
+type CategoryInput struct {
+	Name     string `json:"name" binding:"required,max=255" example:"Чай"`
+	ParentID *uint  `json:"parent_id" example:"1"`
+}
+
+// checkCategoryExists returns errCategoryNotFound unless id is nil or refers to
+// an existing category.
+func checkCategoryExists(db *gorm.DB, id *uint) error {
+	if id == nil {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&database.Category{}).Where("id = ?", *id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errCategoryNotFound
+	}
+	return nil
+}
+
+func respondCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errCategoryNotFound):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Parent category not found"})
+	case errors.Is(err, database.ErrCategoryCycle):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save category"})
+	}
+}
+
+// @Summary      Получить список категорий
+// @Description  Возвращает все категории товаров. Дерево категорий строится по полю ParentID.
+// @Tags         Категории (Categories)
+// @Produce      json
+// @Success      200  {array}   database.Category
+// @Failure      500  {object}  router.HTTPError
+// @Router       /categories [get]
+func getCategories(c *gin.Context) {
+	var categories []database.Category
+	if err := database.DB.Order("name ASC").Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch categories"})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// @Summary      Создать категорию
+// @Description  Создает категорию товаров, при необходимости вложенную в родительскую.
+// @Tags         Категории (Categories)
+// @Accept       json
+// @Produce      json
+// @Param        category  body      router.CategoryInput  true  "Данные категории"
+// @Security     BearerAuth
+// @Success      201       {object}  database.Category
+// @Failure      400       {object}  router.HTTPError
+// @Failure      403       {object}  router.HTTPError
+// @Failure      500       {object}  router.HTTPError
+// @Router       /categories [post]
+func createCategory(c *gin.Context) {
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	category := database.Category{Name: input.Name, ParentID: input.ParentID}
+	if err := checkCategoryExists(database.DB, category.ParentID); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+	if err := database.DB.Create(&category).Error; err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+	setAudit(c, "category.create", "category", category.ID, nil, category)
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// @Summary      Обновить категорию
+// @Description  Переименовывает категорию или переносит ее в другую родительскую категорию.
+// @Tags         Категории (Categories)
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                   true  "ID категории"
+// @Param        category  body      router.CategoryInput  true  "Данные категории"
+// @Security     BearerAuth
+// @Success      200       {object}  database.Category
+// @Failure      400       {object}  router.HTTPError  "Некорректные данные или циклическая вложенность"
+// @Failure      403       {object}  router.HTTPError
+// @Failure      404       {object}  router.HTTPError
+// @Failure      500       {object}  router.HTTPError
+// @Router       /categories/{id} [put]
+func updateCategory(c *gin.Context) {
+	var category database.Category
+	if err := database.DB.First(&category, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Category not found"})
+		return
+	}
+
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := category
+	category.Name = input.Name
+	category.ParentID = input.ParentID
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryExists(tx, category.ParentID); err != nil {
+			return err
+		}
+		if err := database.CheckCategoryParent(tx, category.ID, category.ParentID); err != nil {
+			return err
+		}
+		return tx.Save(&category).Error
+	})
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+	setAudit(c, "category.update", "category", category.ID, before, category)
+
+	c.JSON(http.StatusOK, category)
+}
+
+// @Summary      Удалить категорию
+// @Description  Удаляет категорию, в которой нет подкатегорий и товаров (включая товары в корзине) и которой не ограничены
+// @Description  купоны и акции.
+// @Tags         Категории (Categories)
+// @Produce      json
+// @Param        id   path      int  true  "ID категории"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      409  {object}  router.HTTPError  "В категории есть подкатегории или товары, или ею ограничены купоны и акции"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /categories/{id} [delete]
+func deleteCategory(c *gin.Context) {
+	var category database.Category
+	if err := database.DB.First(&category, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Category not found"})
+		return
+	}
+
+	var discounts []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var children, products int64
+		if err := tx.Model(&database.Category{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&database.Product{}).Where("category_id = ?", category.ID).Count(&products).Error; err != nil {
+			return err
+		}
+		if children > 0 || products > 0 {
+			return errCategoryInUse
+		}
+		var err error
+		discounts, err = database.RestrictingDiscounts(tx, database.RestrictionCategory, category.ID)
+		if err != nil {
+			return err
+		}
+		if len(discounts) > 0 {
+			return errCategoryDiscount
+		}
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		if errors.Is(err, errCategoryInUse) {
+			c.JSON(http.StatusConflict, HTTPError{Message: "Category has subcategories or products"})
+			return
+		}
+		if errors.Is(err, errCategoryDiscount) {
+			c.JSON(http.StatusConflict, HTTPError{Message: "Category is referenced by discounts: " + strings.Join(discounts, ", ")})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete category"})
+		return
+	}
+	setAudit(c, "category.delete", "category", category.ID, category, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Category deleted successfully"})
+}
@@ -0,0 +1,333 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	errCouponCodeTaken     = errors.New("coupon code is already used by another coupon")
+	errCouponInvalidValue  = errors.New("percent discount must not exceed 100")
+	errCouponInvalidPeriod = errors.New("expires_at must be after starts_at")
+	errRestrictedProducts  = errors.New("one or more restricted products not found")
+	errRestrictedCategory  = errors.New("one or more restricted categories not found")
+	errCouponRedeemed      = errors.New("coupon has been redeemed and cannot be deleted, deactivate it instead")
+)
+
+type CouponInput struct {
+	Code             string     `json:"code" binding:"required,max=64" example:"WELCOME10"`
+	Description      string     `json:"description" binding:"max=255" example:"Скидка 10% на первый заказ"`
+	Type             string     `json:"type" binding:"required,oneof=percent fixed" example:"percent"`
+	Value            float64    `json:"value" binding:"required,gt=0" example:"10"`
+	MinOrderTotal    float64    `json:"min_order_total" binding:"gte=0" example:"1000"`
+	StartsAt         *time.Time `json:"starts_at" example:"2025-01-01T00:00:00Z"`
+	ExpiresAt        *time.Time `json:"expires_at" example:"2025-12-31T23:59:59Z"`
+	UsageLimit       *int       `json:"usage_limit" binding:"omitempty,min=1" example:"100"`
+	PerCustomerLimit *int       `json:"per_customer_limit" binding:"omitempty,min=1" example:"1"`
+	Active           *bool      `json:"active" example:"true"`
+	ProductIDs       []uint     `json:"product_ids" example:"1,2"`
+	CategoryIDs      []uint     `json:"category_ids" example:"3"`
+}
+
+type CouponListQuery struct {
+	PaginationQuery
+	Code   string `form:"code" example:"WELCOME"`
+	Active *bool  `form:"active"`
+}
+
+// applyCouponInput validates the input and copies it onto the coupon,
+// replacing its product and category restrictions.
+func applyCouponInput(db *gorm.DB, coupon *database.Coupon, input CouponInput) error {
+	if input.Type == database.CouponTypePercent && input.Value > 100 {
+		return errCouponInvalidValue
+	}
+	if input.StartsAt != nil && input.ExpiresAt != nil && !input.ExpiresAt.After(*input.StartsAt) {
+		return errCouponInvalidPeriod
+	}
+
+	code := database.NormalizeCouponCode(input.Code)
+	var count int64
+	if err := db.Model(&database.Coupon{}).Where("code = ? AND id <> ?", code, coupon.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errCouponCodeTaken
+	}
+
+	products, categories, err := loadRestrictions(db, input.ProductIDs, input.CategoryIDs)
+	if err != nil {
+		return err
+	}
+
+	coupon.Code = code
+	coupon.Description = input.Description
+	coupon.Type = input.Type
+	coupon.Value = input.Value
+	coupon.MinOrderTotal = input.MinOrderTotal
+	coupon.StartsAt = input.StartsAt
+	coupon.ExpiresAt = input.ExpiresAt
+	coupon.UsageLimit = input.UsageLimit
+	coupon.PerCustomerLimit = input.PerCustomerLimit
+	if input.Active != nil {
+		coupon.Active = *input.Active
+	}
+	coupon.Products = products
+	coupon.Categories = categories
+	return nil
+}
+
+// saveCoupon stores the coupon together with its restrictions.
+func saveCoupon(tx *gorm.DB, coupon *database.Coupon) error {
+	if err := tx.Omit("Products", "Categories").Save(coupon).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(coupon).Association("Products").Replace(coupon.Products); err != nil {
+		return err
+	}
+	return tx.Model(coupon).Association("Categories").Replace(coupon.Categories)
+}
+
+// loadRestrictions loads the products and categories a coupon or promotion
+// is restricted to, failing if any of them does not exist.
+func loadRestrictions(db *gorm.DB, productIDs, categoryIDs []uint) ([]database.Product, []database.Category, error) {
+	var products []database.Product
+	if len(productIDs) > 0 {
+		if err := db.Find(&products, productIDs).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(products) != len(uniqueIDs(productIDs)) {
+			return nil, nil, errRestrictedProducts
+		}
+	}
+	var categories []database.Category
+	if len(categoryIDs) > 0 {
+		if err := db.Find(&categories, categoryIDs).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(categories) != len(uniqueIDs(categoryIDs)) {
+			return nil, nil, errRestrictedCategory
+		}
+	}
+	return products, categories, nil
+}
+
+func uniqueIDs(ids []uint) map[uint]bool {
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
+
+func respondCouponError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errCouponCodeTaken):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	case errors.Is(err, errCouponInvalidValue), errors.Is(err, errCouponInvalidPeriod),
+		errors.Is(err, errRestrictedProducts), errors.Is(err, errRestrictedCategory):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save coupon"})
+	}
+}
+
+// @Summary      Получить список купонов
+// @Description  Возвращает постраничный список купонов с поиском по коду и фильтром по активности.
+// @Tags         Купоны (Coupons)
+// @Produce      json
+// @Param        code       query     string  false  "Часть кода купона"
+// @Param        active     query     bool    false  "Только активные или только отключенные"
+// @Param        page       query     int     false  "Номер страницы"  default(1)
+// @Param        page_size  query     int     false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200        {object}  router.Page[database.Coupon]
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /coupons [get]
+func getCoupons(c *gin.Context) {
+	var query CouponListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.Coupon{})
+	if query.Code != "" {
+		db = db.Where(`code LIKE ? ESCAPE '\'`, database.ContainsPattern(strings.ToUpper(query.Code)))
+	}
+	if query.Active != nil {
+		db = db.Where("active = ?", *query.Active)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch coupons"})
+		return
+	}
+	var coupons []database.Coupon
+	err := db.Preload("Products").Preload("Categories").
+		Order("created_at DESC, id DESC").
+		Offset(query.offset()).Limit(query.PageSize).
+		Find(&coupons).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch coupons"})
+		return
+	}
+	c.JSON(http.StatusOK, newPage(coupons, query.PaginationQuery, total))
+}
+
+// @Summary      Получить купон
+// @Description  Возвращает купон с ограничениями по товарам и категориям и числом использований.
+// @Tags         Купоны (Coupons)
+// @Produce      json
+// @Param        id   path      int  true  "ID купона"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Coupon
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Router       /coupons/{id} [get]
+func getCoupon(c *gin.Context) {
+	var coupon database.Coupon
+	if err := database.DB.Preload("Products").Preload("Categories").First(&coupon, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Coupon not found"})
+		return
+	}
+	c.JSON(http.StatusOK, coupon)
+}
+
+// @Summary      Создать купон
+// @Description  Создает купон со скидкой в процентах (percent) или фиксированной суммой (fixed).
+// @Description  Код купона не зависит от регистра. Если указаны product_ids или category_ids, скидка действует только на эти товары и категории.
+// @Description  Незаданные лимиты использования не ограничены.
+// @Tags         Купоны (Coupons)
+// @Accept       json
+// @Produce      json
+// @Param        coupon  body      router.CouponInput  true  "Данные купона"
+// @Security     BearerAuth
+// @Success      201     {object}  database.Coupon
+// @Failure      400     {object}  router.HTTPError
+// @Failure      403     {object}  router.HTTPError
+// @Failure      409     {object}  router.HTTPError  "Купон с таким кодом уже существует"
+// @Failure      500     {object}  router.HTTPError
+// @Router       /coupons [post]
+func createCoupon(c *gin.Context) {
+	var input CouponInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	coupon := database.Coupon{Active: true}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyCouponInput(tx, &coupon, input); err != nil {
+			return err
+		}
+		return saveCoupon(tx, &coupon)
+	})
+	if err != nil {
+		respondCouponError(c, err)
+		return
+	}
+	setAudit(c, "coupon.create", "coupon", coupon.ID, nil, coupon)
+
+	c.JSON(http.StatusCreated, coupon)
+}
+
+// @Summary      Обновить купон
+// @Description  Полностью заменяет условия купона. Счетчик использований не меняется.
+// @Tags         Купоны (Coupons)
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                 true  "ID купона"
+// @Param        coupon  body      router.CouponInput  true  "Данные купона"
+// @Security     BearerAuth
+// @Success      200     {object}  database.Coupon
+// @Failure      400     {object}  router.HTTPError
+// @Failure      403     {object}  router.HTTPError
+// @Failure      404     {object}  router.HTTPError
+// @Failure      409     {object}  router.HTTPError  "Купон с таким кодом уже существует"
+// @Failure      500     {object}  router.HTTPError
+// @Router       /coupons/{id} [put]
+func updateCoupon(c *gin.Context) {
+	var coupon database.Coupon
+	if err := database.DB.Preload("Products").Preload("Categories").First(&coupon, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Coupon not found"})
+		return
+	}
+
+	var input CouponInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := coupon
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyCouponInput(tx, &coupon, input); err != nil {
+			return err
+		}
+		return saveCoupon(tx, &coupon)
+	})
+	if err != nil {
+		respondCouponError(c, err)
+		return
+	}
+	setAudit(c, "coupon.update", "coupon", coupon.ID, before, coupon)
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// @Summary      Удалить купон
+// @Description  Удаляет купон, который еще ни разу не использовался. Использованные купоны можно только отключить.
+// @Tags         Купоны (Coupons)
+// @Produce      json
+// @Param        id   path      int  true  "ID купона"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      409  {object}  router.HTTPError  "Купон уже использовался"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /coupons/{id} [delete]
+func deleteCoupon(c *gin.Context) {
+	var coupon database.Coupon
+	if err := database.DB.First(&coupon, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Coupon not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var redemptions int64
+		if err := tx.Model(&database.CouponRedemption{}).Where("coupon_id = ?", coupon.ID).Count(&redemptions).Error; err != nil {
+			return err
+		}
+		if redemptions > 0 {
+			return errCouponRedeemed
+		}
+		if err := tx.Model(&coupon).Association("Products").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(&coupon).Association("Categories").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&coupon).Error
+	})
+	if err != nil {
+		if errors.Is(err, errCouponRedeemed) {
+			c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete coupon"})
+		return
+	}
+	setAudit(c, "coupon.delete", "coupon", coupon.ID, coupon, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Coupon deleted successfully"})
+}
@@ -0,0 +1,226 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CustomerListQuery struct {
+	PaginationQuery
+	Email          string    `form:"email" example:"gmail.com"`
+	Role           string    `form:"role" example:"support"`
+	RegisteredFrom time.Time `form:"registered_from" time_format:"2006-01-02" example:"2025-01-01"`
+	RegisteredTo   time.Time `form:"registered_to" time_format:"2006-01-02" example:"2025-12-31"`
+	Disabled       *bool     `form:"disabled"`
+}
+
+type CustomerDetails struct {
+	database.Customer
+	OrderCount    int64   `json:"order_count" example:"3"`
+	LifetimeSpend float64 `json:"lifetime_spend" example:"1520.5"`
+}
+
+// @Summary      Получить список пользователей
+// @Description  Возвращает постраничный список пользователей с поиском по email и фильтрами по роли и дате регистрации.
+// @Description  Роль "user" выбирает пользователей без ролей сотрудников.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Param        email            query     string  false  "Часть email"
+// @Param        role             query     string  false  "Роль (admin, catalog_manager, order_fulfiller, support, user)"
+// @Param        registered_from  query     string  false  "Дата регистрации с (YYYY-MM-DD)"
+// @Param        registered_to    query     string  false  "Дата регистрации по (YYYY-MM-DD), включительно"
+// @Param        disabled         query     bool    false  "Только заблокированные или только активные"
+// @Param        page             query     int     false  "Номер страницы"  default(1)
+// @Param        page_size        query     int     false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200  {object}  router.Page[database.Customer]  "Страница пользователей"
+// @Failure      400  {object}  router.HTTPError               "Некорректные параметры запроса"
+// @Failure      403  {object}  router.HTTPError               "Недостаточно прав"
+// @Failure      500  {object}  router.HTTPError               "Внутренняя ошибка сервера"
+// @Router       /users [get]
+func getCustomers(c *gin.Context) {
+	var query CustomerListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.Customer{})
+	if query.Email != "" {
+		db = db.Where(`LOWER(email) LIKE ? ESCAPE '\'`, database.ContainsPattern(strings.ToLower(query.Email)))
+	}
+	if query.Role == "user" {
+		db = db.Where("NOT EXISTS (SELECT 1 FROM customer_roles WHERE customer_roles.customer_id = customers.id)")
+	} else if query.Role != "" {
+		db = db.Where(
+			"EXISTS (SELECT 1 FROM customer_roles JOIN roles ON roles.id = customer_roles.role_id "+
+				"WHERE customer_roles.customer_id = customers.id AND roles.name = ?)", query.Role)
+	}
+	if !query.RegisteredFrom.IsZero() {
+		db = db.Where("registration_date >= ?", query.RegisteredFrom)
+	}
+	if !query.RegisteredTo.IsZero() {
+		db = db.Where("registration_date < ?", query.RegisteredTo.AddDate(0, 0, 1))
+	}
+	if query.Disabled != nil {
+		if *query.Disabled {
+			db = db.Where("disabled_at IS NOT NULL")
+		} else {
+			db = db.Where("disabled_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch users"})
+		return
+	}
+
+	var customers []database.Customer
+	err := db.Preload("Roles").
+		Order("registration_date DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&customers).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(customers, query.PaginationQuery, total))
+}
+
+// @Summary      Получить пользователя по ID
+// @Description  Возвращает данные пользователя, его роли, адреса, количество заказов и общую сумму покупок: сумму оплаченных
+// @Description  заказов за вычетом возвратов.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "ID пользователя"
+// @Security     BearerAuth
+// @Success      200  {object}  router.CustomerDetails  "Данные пользователя"
+// @Failure      400  {object}  router.HTTPError        "Некорректный ID пользователя"
+// @Failure      403  {object}  router.HTTPError        "Недостаточно прав"
+// @Failure      404  {object}  router.HTTPError        "Пользователь не найден"
+// @Failure      500  {object}  router.HTTPError        "Внутренняя ошибка сервера"
+// @Router       /users/{id} [get]
+func getCustomer(c *gin.Context) {
+	targetUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid user ID"})
+		return
+	}
+
+	var details CustomerDetails
+	if err := database.DB.Preload("Roles").Preload("Addresses").First(&details.Customer, targetUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "User not found"})
+		return
+	}
+
+	if err := database.DB.Model(&database.Order{}).Where("customer_id = ?", details.ID).Count(&details.OrderCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch user statistics"})
+		return
+	}
+
+	err = database.DB.Model(&database.Order{}).
+		Where("customer_id = ? AND status IN ?", details.ID, database.PaidOrderStatuses).
+		Select("COALESCE(SUM(total - refunded_total), 0)").
+		Scan(&details.LifetimeSpend).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch user statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// @Summary      Заблокировать пользователя
+// @Description  Блокирует аккаунт: пользователь не может войти, а выданные ему токены перестают действовать.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "ID пользователя"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage "Пользователь заблокирован"
+// @Failure      400  {object}  router.HTTPError      "Некорректный ID пользователя"
+// @Failure      403  {object}  router.HTTPError      "Недостаточно прав или попытка заблокировать самого себя"
+// @Failure      404  {object}  router.HTTPError      "Пользователь не найден"
+// @Failure      409  {object}  router.HTTPError      "Пользователь уже заблокирован"
+// @Failure      500  {object}  router.HTTPError      "Внутренняя ошибка сервера"
+// @Router       /users/{id}/disable [post]
+func disableCustomer(c *gin.Context) {
+	targetUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid user ID"})
+		return
+	}
+
+	var user database.Customer
+	if err := database.DB.First(&user, targetUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "User not found"})
+		return
+	}
+
+	if currentUserID, _ := c.Get("userID"); currentUserID == user.ID {
+		c.JSON(http.StatusForbidden, HTTPError{Message: "You cannot disable yourself"})
+		return
+	}
+
+	if user.DisabledAt != nil {
+		c.JSON(http.StatusConflict, HTTPError{Message: "User is already disabled"})
+		return
+	}
+
+	disabledAt := time.Now()
+	if err := database.DB.Model(&user).Update("disabled_at", disabledAt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to disable user"})
+		return
+	}
+	setAudit(c, "customer.disable", "customer", user.ID, gin.H{"disabled_at": nil}, gin.H{"disabled_at": disabledAt})
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "User disabled successfully"})
+}
+
+// @Summary      Разблокировать пользователя
+// @Description  Снимает блокировку с аккаунта пользователя.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "ID пользователя"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage "Пользователь разблокирован"
+// @Failure      400  {object}  router.HTTPError      "Некорректный ID пользователя"
+// @Failure      403  {object}  router.HTTPError      "Недостаточно прав"
+// @Failure      404  {object}  router.HTTPError      "Пользователь не найден"
+// @Failure      409  {object}  router.HTTPError      "Пользователь не заблокирован"
+// @Failure      500  {object}  router.HTTPError      "Внутренняя ошибка сервера"
+// @Router       /users/{id}/enable [post]
+func enableCustomer(c *gin.Context) {
+	targetUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid user ID"})
+		return
+	}
+
+	var user database.Customer
+	if err := database.DB.First(&user, targetUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "User not found"})
+		return
+	}
+
+	if user.DisabledAt == nil {
+		c.JSON(http.StatusConflict, HTTPError{Message: "User is not disabled"})
+		return
+	}
+
+	before := gin.H{"disabled_at": user.DisabledAt}
+	if err := database.DB.Model(&user).Update("disabled_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to enable user"})
+		return
+	}
+	setAudit(c, "customer.enable", "customer", user.ID, before, gin.H{"disabled_at": nil})
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "User enabled successfully"})
+}
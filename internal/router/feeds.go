@@ -0,0 +1,49 @@
+package router
+
+import (
+	"OnlineShop/internal/feed"
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+var feedCache *feed.Cache
+
+func serveFeed(c *gin.Context, name, filename string) {
+	data, builtAt, err := feedCache.Get(name)
+	if err != nil {
+		log.Printf("Failed to generate %s feed: %v", name, err)
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to generate feed"})
+		return
+	}
+
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	// ServeContent answers If-Modified-Since, so marketplaces polling the feed
+	// only download it after the catalogue has changed.
+	http.ServeContent(c.Writer, c.Request, filename, builtAt, bytes.NewReader(data))
+}
+
+// @Summary      Фид для Яндекс Маркета
+// @Description  Каталог опубликованных товаров в формате YML с категориями, ценами, наличием и изображениями.
+// @Description  Фид кешируется и формируется заново после изменения товаров или категорий.
+// @Tags         Фиды (Feeds)
+// @Produce      xml
+// @Success      200  {string}  string  "YML-документ"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /feeds/yandex.yml [get]
+func getYandexFeed(c *gin.Context) {
+	serveFeed(c, feed.Yandex, "yandex.yml")
+}
+
+// @Summary      Фид для Google Merchant Center
+// @Description  Каталог опубликованных товаров в формате RSS 2.0 для Google Merchant Center.
+// @Description  Фид кешируется и формируется заново после изменения товаров или категорий.
+// @Tags         Фиды (Feeds)
+// @Produce      xml
+// @Success      200  {string}  string  "RSS-документ"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /feeds/google.xml [get]
+func getGoogleFeed(c *gin.Context) {
+	serveFeed(c, feed.Google, "google.xml")
+}
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize limits the request bodies read into memory to
+	// fingerprint them.
+	maxIdempotentBodySize = 1 << 20
+)
+
+var idempotencyKeyTTL time.Duration
+
+// responseRecorder keeps a copy of the response body written by the handler.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestFingerprint identifies the request a key was used with, so that the
+// key cannot be reused for a different one. The query is part of it, as
+// requests such as a dry run and the real import differ only there.
+func requestFingerprint(method, path, query string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "?" + query + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IdempotencyMiddleware makes mutating requests sent with an Idempotency-Key
+// header safe to retry: the first request with the key is executed and its
+// response stored, and later requests with the same key get the stored
+// response without executing the handler again. Reusing a key for a different
+// request is rejected, as is a retry while the first request is still in
+// progress. Responses with server errors are not stored, so such requests can
+// be retried with the same key. Keys are scoped to the authenticated user and
+// are forgotten after IDEMPOTENCY_KEY_TTL. Must run after AuthMiddleware.
+//
+// Uploads are not read into memory: a multipart request is fingerprinted by
+// its method, path and query only, so reusing a key for another file of the same
+// endpoint replays the first response instead of being rejected.
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(idempotencyKeyHeader)
+		if value == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		if len(value) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, HTTPError{Message: "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		var body []byte
+		if !strings.HasPrefix(c.ContentType(), "multipart/") {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, HTTPError{Message: "Request body is too large"})
+				return
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, HTTPError{Message: "Failed to read request body"})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		var actorID uint
+		if actor := currentActor(c); actor != nil {
+			actorID = *actor
+		}
+		now := time.Now()
+		key := database.IdempotencyKey{
+			ActorID:     actorID,
+			Key:         value,
+			Method:      c.Request.Method,
+			Path:        truncate(c.Request.URL.Path, 255),
+			Fingerprint: requestFingerprint(c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery, body),
+			ExpiresAt:   now.Add(idempotencyKeyTTL),
+		}
+		fingerprint := key.Fingerprint
+
+		reserved, err := database.ReserveIdempotencyKey(database.DB, &key, now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The first request failed and released the key in between.
+			c.AbortWithStatusJSON(http.StatusConflict, HTTPError{Message: "A request with this Idempotency-Key is being processed, retry later"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, HTTPError{Message: "Failed to check Idempotency-Key"})
+			return
+		}
+
+		if !reserved {
+			switch {
+			case key.Fingerprint != fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, HTTPError{Message: "Idempotency-Key was already used with a different request"})
+			case key.CompletedAt == nil:
+				c.AbortWithStatusJSON(http.StatusConflict, HTTPError{Message: "A request with this Idempotency-Key is being processed, retry later"})
+			default:
+				c.Header(idempotentReplayHeader, "true")
+				c.Data(key.StatusCode, key.ContentType, key.Response)
+				c.Abort()
+			}
+			return
+		}
+
+		// A panicking handler is answered by gin.Recovery with a server
+		// error; the key is released so that the request can be retried.
+		defer func() {
+			if r := recover(); r != nil {
+				if err := database.ReleaseIdempotencyKey(database.DB, key); err != nil {
+					log.Printf("Failed to release Idempotency-Key %q: %v", key.Key, err)
+				}
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			err = database.ReleaseIdempotencyKey(database.DB, key)
+		} else {
+			err = database.CompleteIdempotencyKey(database.DB, &key, status, c.Writer.Header().Get("Content-Type"), recorder.body.Bytes(), time.Now())
+		}
+		if err != nil {
+			log.Printf("Failed to store response for Idempotency-Key %q: %v", key.Key, err)
+			// Without the stored response retries would be rejected as in
+			// progress until the key expires.
+			if err := database.ReleaseIdempotencyKey(database.DB, key); err != nil {
+				log.Printf("Failed to release Idempotency-Key %q: %v", key.Key, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/imaging"
+	"OnlineShop/internal/storage"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+)
+
+// thumbnailSize is the largest side of generated thumbnails, in pixels.
+const thumbnailSize = 320
+
+var (
+	imageStorage storage.Storage
+	maxImageSize int64
+)
+
+var errImageOrderMismatch = errors.New("image_ids must list every image of the product exactly once")
+
+type ImageOrderInput struct {
+	ImageIDs []uint `json:"image_ids" binding:"required" example:"3,1,2"`
+}
+
+// preloadImages loads product images in display order.
+func preloadImages(db *gorm.DB) *gorm.DB {
+	return db.Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") })
+}
+
+// attachSearchImages loads the images of the products found by a search.
+func attachSearchImages(db *gorm.DB, hits []database.ProductSearchHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Product.ID
+	}
+
+	var images []database.ProductImage
+	if err := db.Where("product_id IN ?", ids).Order("position ASC, id ASC").Find(&images).Error; err != nil {
+		return err
+	}
+	for i := range hits {
+		for _, image := range images {
+			if image.ProductID == hits[i].Product.ID {
+				hits[i].Product.Images = append(hits[i].Product.Images, image)
+			}
+		}
+	}
+	return nil
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// deleteStoredImages removes image files after their rows are gone. A failure
+// only leaves an orphaned file behind, so it is logged rather than reported.
+func deleteStoredImages(ctx context.Context, images ...database.ProductImage) {
+	for _, image := range images {
+		for _, key := range []string{image.Key, image.ThumbnailKey} {
+			if err := imageStorage.Delete(ctx, key); err != nil {
+				log.Printf("Failed to delete image file %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// @Summary      Загрузить изображение товара
+// @Description  Загружает изображение (JPEG, PNG, GIF или WebP) и создает для него миниатюру.
+// @Description  Тип файла определяется по содержимому. Новое изображение добавляется в конец списка.
+// @Tags         Товары (Products)
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id     path      int   true  "ID Товара"
+// @Param        image  formData  file  true  "Файл изображения"
+// @Security     BearerAuth
+// @Success      201    {object}  database.ProductImage
+// @Failure      400    {object}  router.HTTPError  "Файл не передан"
+// @Failure      403    {object}  router.HTTPError
+// @Failure      404    {object}  router.HTTPError
+// @Failure      413    {object}  router.HTTPError  "Файл или изображение слишком большие"
+// @Failure      415    {object}  router.HTTPError  "Неподдерживаемый формат"
+// @Failure      500    {object}  router.HTTPError
+// @Router       /products/{id}/images [post]
+func uploadProductImage(c *gin.Context) {
+	var product database.Product
+	if err := database.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Image file is required"})
+		return
+	}
+	if header.Size > maxImageSize {
+		c.JSON(http.StatusRequestEntityTooLarge, HTTPError{Message: fmt.Sprintf("Image must not exceed %d bytes", maxImageSize)})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Failed to read image"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Failed to read image"})
+		return
+	}
+	if int64(len(data)) > maxImageSize {
+		c.JSON(http.StatusRequestEntityTooLarge, HTTPError{Message: fmt.Sprintf("Image must not exceed %d bytes", maxImageSize)})
+		return
+	}
+
+	info, err := imaging.Inspect(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, HTTPError{Message: "Image dimensions are too large"})
+			return
+		}
+		c.JSON(http.StatusUnsupportedMediaType, HTTPError{Message: "Only JPEG, PNG, GIF and WebP images are supported"})
+		return
+	}
+
+	thumbnail, err := imaging.Thumbnail(data, thumbnailSize)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, HTTPError{Message: "Failed to decode image"})
+		return
+	}
+
+	name, err := randomName()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to store image"})
+		return
+	}
+
+	image := database.ProductImage{
+		ProductID:    product.ID,
+		Key:          fmt.Sprintf("products/%d/%s%s", product.ID, name, info.Extension),
+		ThumbnailKey: fmt.Sprintf("products/%d/%s_thumb.jpg", product.ID, name),
+		ContentType:  info.ContentType,
+		Size:         int64(len(data)),
+		Width:        info.Width,
+		Height:       info.Height,
+	}
+	image.URL = imageStorage.URL(image.Key)
+	image.ThumbnailURL = imageStorage.URL(image.ThumbnailKey)
+
+	ctx := c.Request.Context()
+	if err := imageStorage.Put(ctx, image.Key, bytes.NewReader(data), int64(len(data)), info.ContentType); err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to store image"})
+		return
+	}
+	if err := imageStorage.Put(ctx, image.ThumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+		deleteStoredImages(ctx, image)
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to store image"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.ProductImage{}).Where("product_id = ?", product.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		image.Position = int(count)
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, product.ID)
+	})
+	if err != nil {
+		deleteStoredImages(ctx, image)
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save image"})
+		return
+	}
+	setAudit(c, "product_image.create", "product_image", image.ID, nil, image)
+
+	c.JSON(http.StatusCreated, image)
+}
+
+// @Summary      Изменить порядок изображений товара
+// @Description  Задает порядок изображений. Список должен содержать каждое изображение товара ровно один раз.
+// @Tags         Товары (Products)
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                     true  "ID Товара"
+// @Param        input  body      router.ImageOrderInput  true  "ID изображений в новом порядке"
+// @Security     BearerAuth
+// @Success      200    {array}   database.ProductImage
+// @Failure      400    {object}  router.HTTPError
+// @Failure      403    {object}  router.HTTPError
+// @Failure      404    {object}  router.HTTPError
+// @Failure      500    {object}  router.HTTPError
+// @Router       /products/{id}/images [put]
+func reorderProductImages(c *gin.Context) {
+	var product database.Product
+	if err := database.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+
+	var input ImageOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var before, images []database.ProductImage
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Order("position ASC, id ASC").Find(&before).Error; err != nil {
+			return err
+		}
+		if len(before) != len(input.ImageIDs) {
+			return errImageOrderMismatch
+		}
+
+		positions := make(map[uint]int, len(input.ImageIDs))
+		for i, id := range input.ImageIDs {
+			if _, ok := positions[id]; ok {
+				return errImageOrderMismatch
+			}
+			positions[id] = i
+		}
+
+		for _, image := range before {
+			position, ok := positions[image.ID]
+			if !ok {
+				return errImageOrderMismatch
+			}
+			if err := tx.Model(&image).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("product_id = ?", product.ID).Order("position ASC, id ASC").Find(&images).Error; err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, product.ID)
+	})
+	if err != nil {
+		if errors.Is(err, errImageOrderMismatch) {
+			c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to reorder images"})
+		return
+	}
+	setAudit(c, "product_image.reorder", "product", product.ID, before, images)
+
+	c.JSON(http.StatusOK, images)
+}
+
+// @Summary      Удалить изображение товара
+// @Description  Удаляет изображение и его миниатюру из хранилища.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id       path      int  true  "ID Товара"
+// @Param        imageId  path      int  true  "ID Изображения"
+// @Security     BearerAuth
+// @Success      200      {object}  router.SuccessMessage
+// @Failure      403      {object}  router.HTTPError
+// @Failure      404      {object}  router.HTTPError
+// @Failure      500      {object}  router.HTTPError
+// @Router       /products/{id}/images/{imageId} [delete]
+func deleteProductImage(c *gin.Context) {
+	var image database.ProductImage
+	if err := database.DB.Where("product_id = ?", c.Param("id")).First(&image, c.Param("imageId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Image not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&database.ProductImage{}).
+			Where("product_id = ? AND position > ?", image.ProductID, image.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+		if err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, image.ProductID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete image"})
+		return
+	}
+	deleteStoredImages(c.Request.Context(), image)
+	setAudit(c, "product_image.delete", "product_image", image.ID, image, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Image deleted successfully"})
+}
@@ -2,7 +2,14 @@ package router
 
 import (
 	"OnlineShop/config"
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/feed"
+	"OnlineShop/internal/payment"
+	"OnlineShop/internal/pricing"
+	"OnlineShop/internal/storage"
+	"context"
 	"github.com/gin-gonic/gin"
+	"log"
 )
 
 var jwtKey []byte
@@ -15,39 +22,247 @@ type SuccessMessage struct {
 	Message string `json:"message" example:"Product deleted successfully"`
 }
 
-func SetupRouter(cfg *config.Config) *gin.Engine {
+// SetupRouter builds the HTTP router. The payment provider is shared with the
+// background jobs, so it is created by the caller.
+func SetupRouter(cfg *config.Config, provider payment.Provider) *gin.Engine {
 	jwtKey = cfg.JWTSecretKey
+	maxImageSize = cfg.MaxImageSize
+	taxSettings = pricing.TaxSettings{
+		PricesIncludeTax: cfg.TaxPricesIncludeTax,
+		Rounding:         cfg.TaxRounding,
+	}
+
+	shopCurrency = cfg.ShopCurrency
+	invoiceSeller = database.InvoiceSeller{Name: cfg.ShopName, Company: cfg.ShopCompany}
+	shopName = cfg.ShopName
+	idempotencyKeyTTL = cfg.IdempotencyKeyTTL
+
+	paymentProvider = provider
+
+	var err error
+	imageStorage, err = storage.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
+	feedCache, err = feed.NewCache(database.DB, feed.Shop{
+		Name:     cfg.ShopName,
+		Company:  cfg.ShopCompany,
+		URL:      cfg.ShopURL,
+		Currency: cfg.ShopCurrency,
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize feed cache:", err)
+	}
 
 	r := gin.Default()
 
+	if local, ok := imageStorage.(*storage.Local); ok && cfg.StorageBaseURL == "" {
+		r.Static(storage.LocalURLPrefix, local.Dir())
+	}
+
+	if _, ok := paymentProvider.(*payment.Fake); ok && cfg.PaymentFakeConfirm {
+		log.Println("Warning: PAYMENT_FAKE_CONFIRM is set, anyone can confirm payments without paying")
+		r.POST(payment.FakeURLPrefix+"/:externalId", AuditMiddleware(), confirmFakePayment)
+	}
+
 	publicRoutes := r.Group("/")
 	{
 		publicRoutes.GET("products", getProducts)
+		publicRoutes.GET("products/search", searchProducts)
+		publicRoutes.GET("products/slug/:slug", getProductBySlug)
 		publicRoutes.GET("products/:id", getProduct)
+		publicRoutes.GET("products/:id/variants", getProductVariants)
+		publicRoutes.GET("categories", getCategories)
+
+		publicRoutes.GET("feeds/yandex.yml", getYandexFeed)
+		publicRoutes.GET("feeds/google.xml", getGoogleFeed)
+
+		publicRoutes.POST("users/login", AuditMiddleware(), loginUser)
+		publicRoutes.POST("users/register", AuditMiddleware(), registerUser)
 
-		publicRoutes.POST("users/login", loginUser)
-		publicRoutes.POST("users/register", registerUser)
+		publicRoutes.POST("payments/webhook", AuditMiddleware(), paymentWebhook)
 	}
 
 	protectedRoutes := r.Group("/")
-	protectedRoutes.Use(AuthMiddleware())
+	protectedRoutes.Use(AuthMiddleware(), IdempotencyMiddleware())
 	{
 		protectedRoutes.GET("users/me", SayHello)
+		protectedRoutes.PUT("users/me/password", AuditMiddleware(), changePassword)
+		protectedRoutes.GET("users/me/notifications", getNotificationPreferences)
+		protectedRoutes.PUT("users/me/notifications", updateNotificationPreferences)
+
+		protectedRoutes.GET("users/me/addresses", getAddresses)
+		protectedRoutes.POST("users/me/addresses", createAddress)
+		protectedRoutes.GET("users/me/addresses/:id", getAddress)
+		protectedRoutes.PUT("users/me/addresses/:id", updateAddress)
+		protectedRoutes.DELETE("users/me/addresses/:id", deleteAddress)
 
 		protectedRoutes.POST("orders", createOrder)
+		protectedRoutes.POST("orders/preview", previewOrder)
 		protectedRoutes.GET("orders", getOrders)
+		protectedRoutes.GET("orders/:id", getOrder)
+		protectedRoutes.POST("orders/:id/cancel", AuditMiddleware(), cancelOrder)
+		protectedRoutes.GET("orders/:id/invoice", getInvoice)
+		protectedRoutes.GET("orders/:id/invoice.pdf", getInvoicePDF)
+		protectedRoutes.GET("orders/:id/invoice.html", getInvoiceHTML)
+		protectedRoutes.GET("orders/:id/shipments", getOrderShipments)
+		protectedRoutes.POST("orders/:id/payments", createPayment)
+		protectedRoutes.POST("orders/:id/returns", createReturnRequest)
+		protectedRoutes.GET("orders/:id/returns", getOrderReturnRequests)
+
+		protectedRoutes.POST("shipping/quote", quoteShipping)
 	}
 
-	adminRoutes := r.Group("/")
-	adminRoutes.Use(AuthMiddleware(), AdminMiddleware())
+	catalogRoutes := r.Group("/")
+	catalogRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionProductsManage), IdempotencyMiddleware())
 	{
-		adminRoutes.POST("users/:id/promote", promoteUserToAdmin)
+		catalogRoutes.POST("products", createProduct)
+		catalogRoutes.PUT("products/:id", updateProduct)
+		catalogRoutes.DELETE("products/:id", deleteProduct)
+
+		catalogRoutes.GET("products/all", getAllProducts)
+		catalogRoutes.GET("products/trash", getDeletedProducts)
+		catalogRoutes.POST("products/:id/restore", restoreProduct)
+		catalogRoutes.DELETE("products/:id/purge", purgeProduct)
+
+		catalogRoutes.PUT("products/:id/options", setProductOptions)
+		catalogRoutes.POST("products/:id/variants", createVariant)
+		catalogRoutes.PUT("products/:id/variants/:variantId", updateVariant)
+		catalogRoutes.DELETE("products/:id/variants/:variantId", deleteVariant)
 
-		adminRoutes.POST("products", createProduct)
-		adminRoutes.PUT("products/:id", updateProduct)
-		adminRoutes.DELETE("products/:id", deleteProduct)
+		catalogRoutes.POST("products/:id/images", uploadProductImage)
+		catalogRoutes.PUT("products/:id/images", reorderProductImages)
+		catalogRoutes.DELETE("products/:id/images/:imageId", deleteProductImage)
 
-		adminRoutes.GET("orders/pending", getPendingOrders)
+		catalogRoutes.GET("products/:id/prices", getPriceHistory)
+		catalogRoutes.GET("products/:id/price-schedules", getPriceSchedules)
+		catalogRoutes.POST("products/:id/price-schedules", createPriceSchedule)
+		catalogRoutes.DELETE("products/:id/price-schedules/:scheduleId", cancelPriceSchedule)
+
+		catalogRoutes.POST("products/import", importProducts)
+		catalogRoutes.GET("products/export", exportProducts)
+
+		catalogRoutes.POST("categories", createCategory)
+		catalogRoutes.PUT("categories/:id", updateCategory)
+		catalogRoutes.DELETE("categories/:id", deleteCategory)
+	}
+
+	couponRoutes := r.Group("/")
+	couponRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionCouponsManage), IdempotencyMiddleware())
+	{
+		couponRoutes.GET("coupons", getCoupons)
+		couponRoutes.GET("coupons/:id", getCoupon)
+		couponRoutes.POST("coupons", createCoupon)
+		couponRoutes.PUT("coupons/:id", updateCoupon)
+		couponRoutes.DELETE("coupons/:id", deleteCoupon)
+
+		couponRoutes.GET("promotions", getPromotions)
+		couponRoutes.GET("promotions/:id", getPromotion)
+		couponRoutes.POST("promotions", createPromotion)
+		couponRoutes.PUT("promotions/:id", updatePromotion)
+		couponRoutes.DELETE("promotions/:id", deletePromotion)
+	}
+
+	settingsRoutes := r.Group("/")
+	settingsRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionSettingsManage), IdempotencyMiddleware())
+	{
+		settingsRoutes.GET("tax-classes", getTaxClasses)
+		settingsRoutes.POST("tax-classes", createTaxClass)
+		settingsRoutes.PUT("tax-classes/:id", updateTaxClass)
+		settingsRoutes.DELETE("tax-classes/:id", deleteTaxClass)
+
+		settingsRoutes.GET("tax-rates", getTaxRates)
+		settingsRoutes.POST("tax-rates", createTaxRate)
+		settingsRoutes.PUT("tax-rates/:id", updateTaxRate)
+		settingsRoutes.DELETE("tax-rates/:id", deleteTaxRate)
+
+		settingsRoutes.GET("shipping-methods", getShippingMethods)
+		settingsRoutes.POST("shipping-methods", createShippingMethod)
+		settingsRoutes.PUT("shipping-methods/:id", updateShippingMethod)
+		settingsRoutes.DELETE("shipping-methods/:id", deleteShippingMethod)
+
+		settingsRoutes.GET("shipping-zones", getShippingZones)
+		settingsRoutes.POST("shipping-zones", createShippingZone)
+		settingsRoutes.PUT("shipping-zones/:id", updateShippingZone)
+		settingsRoutes.DELETE("shipping-zones/:id", deleteShippingZone)
+
+		settingsRoutes.GET("shipping-rates", getShippingRates)
+		settingsRoutes.POST("shipping-rates", createShippingRate)
+		settingsRoutes.PUT("shipping-rates/:id", updateShippingRate)
+		settingsRoutes.DELETE("shipping-rates/:id", deleteShippingRate)
+	}
+
+	orderAdminRoutes := r.Group("/")
+	orderAdminRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionOrdersView))
+	{
+		orderAdminRoutes.GET("orders/all", getAllOrders)
+		orderAdminRoutes.GET("orders/:id/refunds", getRefunds)
+		orderAdminRoutes.GET("payments", getPayments)
+		orderAdminRoutes.GET("returns", getReturnRequests)
+		orderAdminRoutes.GET("returns/:id", getReturnRequest)
+		orderAdminRoutes.GET("shipments/:id/packing-slip", getPackingSlip)
+	}
+
+	orderManageRoutes := r.Group("/")
+	orderManageRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionOrdersManage), IdempotencyMiddleware())
+	{
+		orderManageRoutes.POST("orders/:id/refunds", createRefund)
+		orderManageRoutes.POST("payments/:id/refund", refundPayment)
+		orderManageRoutes.POST("orders/:id/invoice", issueInvoice)
+		orderManageRoutes.POST("orders/:id/shipments", createShipment)
+		orderManageRoutes.POST("shipments/:id/ship", shipShipment)
+		orderManageRoutes.POST("shipments/:id/deliver", deliverShipment)
+		orderManageRoutes.DELETE("shipments/:id", deleteShipment)
+		orderManageRoutes.POST("returns/:id/approve", approveReturnRequest)
+		orderManageRoutes.POST("returns/:id/reject", rejectReturnRequest)
+		orderManageRoutes.POST("returns/:id/receive", receiveReturnRequest)
+		orderManageRoutes.POST("returns/:id/refund", refundReturnRequest)
+	}
+
+	userAdminRoutes := r.Group("/")
+	userAdminRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionUsersView))
+	{
+		userAdminRoutes.GET("users", getCustomers)
+		userAdminRoutes.GET("users/:id", getCustomer)
+	}
+
+	userManageRoutes := r.Group("/")
+	userManageRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionUsersManage), IdempotencyMiddleware())
+	{
+		userManageRoutes.POST("users/:id/disable", disableCustomer)
+		userManageRoutes.POST("users/:id/enable", enableCustomer)
+	}
+
+	roleRoutes := r.Group("/")
+	roleRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionRolesManage), IdempotencyMiddleware())
+	{
+		roleRoutes.GET("roles", getRoles)
+		roleRoutes.GET("users/:id/roles", getUserRoles)
+		roleRoutes.POST("users/:id/roles", assignUserRole)
+		roleRoutes.DELETE("users/:id/roles/:role", revokeUserRole)
+		roleRoutes.POST("users/:id/promote", promoteUserToAdmin)
+	}
+
+	webhookRoutes := r.Group("/")
+	webhookRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionWebhooksManage), IdempotencyMiddleware())
+	{
+		webhookRoutes.GET("webhooks", getWebhooks)
+		webhookRoutes.POST("webhooks", createWebhook)
+		webhookRoutes.GET("webhooks/:id", getWebhook)
+		webhookRoutes.PUT("webhooks/:id", updateWebhook)
+		webhookRoutes.DELETE("webhooks/:id", deleteWebhook)
+		webhookRoutes.POST("webhooks/:id/enable", enableWebhook)
+		webhookRoutes.POST("webhooks/:id/disable", disableWebhook)
+		webhookRoutes.GET("webhooks/:id/deliveries", getWebhookDeliveries)
+		webhookRoutes.POST("webhook-deliveries/:id/replay", replayWebhookDelivery)
+	}
+
+	auditRoutes := r.Group("/")
+	auditRoutes.Use(AuthMiddleware(), AuditMiddleware(), RequirePermission(database.PermissionAuditView))
+	{
+		auditRoutes.GET("audit", getAuditLogs)
+		auditRoutes.GET("audit/verify", verifyAuditLog)
 	}
 
 	return r
@@ -0,0 +1,181 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/invoice"
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// invoiceSeller is the shop as shown on new invoices, set from the
+// configuration.
+var invoiceSeller database.InvoiceSeller
+
+// findInvoice loads the invoice of the order in the path if the user may see
+// the order, writing the error response otherwise.
+func findInvoice(c *gin.Context) (database.Invoice, bool) {
+	var found database.Invoice
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return found, false
+	}
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid order ID"})
+		return found, false
+	}
+
+	viewable, err := viewableOrders(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to check permissions"})
+		return found, false
+	}
+
+	err = database.DB.Scopes(viewable).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where("order_id = ?", orderID).
+		First(&found).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Invoice not found"})
+		return found, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch invoice"})
+		return found, false
+	}
+	return found, true
+}
+
+// @Summary      Получить счет по заказу
+// @Description  Возвращает счет, выставленный на заказ при его оплате. Счет нумеруется последовательно в пределах года и после
+// @Description  выставления не меняется. Покупателю доступны только счета по его заказам, сотрудникам с правом orders.view — любые.
+// @Tags         Счета (Invoices)
+// @Produce      json
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Invoice
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID заказа"
+// @Failure      401  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Заказ не найден или счет еще не выставлен"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /orders/{id}/invoice [get]
+func getInvoice(c *gin.Context) {
+	found, ok := findInvoice(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, found)
+}
+
+// @Summary      Скачать счет в PDF
+// @Description  Возвращает счет по заказу в виде PDF-документа.
+// @Tags         Счета (Invoices)
+// @Produce      application/pdf
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {file}    file
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID заказа"
+// @Failure      401  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Заказ не найден или счет еще не выставлен"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /orders/{id}/invoice.pdf [get]
+func getInvoicePDF(c *gin.Context) {
+	found, ok := findInvoice(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := invoice.RenderPDF(&buf, found); err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to render invoice"})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+invoice.Filename(found, "pdf")+`"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// @Summary      Получить счет в HTML
+// @Description  Возвращает счет по заказу в виде HTML-страницы для просмотра и печати.
+// @Tags         Счета (Invoices)
+// @Produce      html
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {string}  string  "HTML-страница счета"
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID заказа"
+// @Failure      401  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Заказ не найден или счет еще не выставлен"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /orders/{id}/invoice.html [get]
+func getInvoiceHTML(c *gin.Context) {
+	found, ok := findInvoice(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := invoice.RenderHTML(&buf, found); err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to render invoice"})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// @Summary      Выставить счет по заказу
+// @Description  Выставляет счет на оплаченный заказ, у которого его еще нет, например на заказ, оплаченный до появления счетов.
+// @Description  Если счет уже выставлен, возвращает его без изменений.
+// @Tags         Счета (Invoices)
+// @Produce      json
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Invoice
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID заказа"
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Заказ не найден"
+// @Failure      409  {object}  router.HTTPError  "Заказ не оплачен"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /orders/{id}/invoice [post]
+func issueInvoice(c *gin.Context) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid order ID"})
+		return
+	}
+
+	var issued database.Invoice
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var order database.Order
+		if err := database.LockOrder(tx, &order, orderID); err != nil {
+			return err
+		}
+		paid, err := database.SucceededPayment(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		issued, err = database.IssueInvoice(tx, order.ID, invoiceSeller, paid.Currency, time.Now())
+		return err
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+		return
+	case errors.Is(err, database.ErrOrderNotPaid):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to issue invoice"})
+		return
+	}
+	setAudit(c, "invoice.issue", "order", issued.OrderID, nil, issued)
+
+	c.JSON(http.StatusOK, issued)
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/notify"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+type NotificationPreferenceInput struct {
+	Locale   string   `json:"locale" binding:"omitempty,oneof=ru en" example:"ru"`
+	Disabled []string `json:"disabled" binding:"dive,oneof=order.placed order.paid order.shipped order.cancelled" example:"order.placed"`
+}
+
+// NotificationPreferences are the email choices of a customer as shown to
+// them, with the default locale filled in.
+type NotificationPreferences struct {
+	Locale   string   `json:"locale" example:"ru"`
+	Disabled []string `json:"disabled" example:"order.placed"`
+	Optional []string `json:"optional" example:"order.placed,order.paid,order.shipped,order.cancelled"`
+}
+
+func newNotificationPreferences(preference database.NotificationPreference) NotificationPreferences {
+	locale := preference.Locale
+	if locale == "" {
+		locale = notify.DefaultLocale
+	}
+	disabled := []string(preference.Disabled)
+	if disabled == nil {
+		disabled = []string{}
+	}
+	return NotificationPreferences{Locale: locale, Disabled: disabled, Optional: database.OptionalNotifications}
+}
+
+// @Summary      Получить настройки уведомлений
+// @Description  Возвращает язык писем и отключенные пользователем уведомления. В optional перечислены уведомления, которые можно отключить;
+// @Description  письма о регистрации и смене пароля отправляются всегда.
+// @Tags         Пользователи (Auth)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  router.NotificationPreferences
+// @Failure      401  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /users/me/notifications [get]
+func getNotificationPreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	preference, err := database.FindNotificationPreference(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newNotificationPreferences(preference))
+}
+
+// @Summary      Изменить настройки уведомлений
+// @Description  Задает язык писем (ru, en) и список отключенных уведомлений (order.placed, order.paid, order.shipped, order.cancelled).
+// @Description  Без языка письма приходят на языке по умолчанию. Настройки применяются к уведомлениям о событиях после изменения.
+// @Tags         Пользователи (Auth)
+// @Accept       json
+// @Produce      json
+// @Param        preferences  body      router.NotificationPreferenceInput  true  "Настройки уведомлений"
+// @Security     BearerAuth
+// @Success      200          {object}  router.NotificationPreferences
+// @Failure      400          {object}  router.HTTPError
+// @Failure      401          {object}  router.HTTPError
+// @Failure      500          {object}  router.HTTPError
+// @Router       /users/me/notifications [put]
+func updateNotificationPreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var input NotificationPreferenceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	preference := database.NotificationPreference{
+		CustomerID: userID.(uint),
+		Locale:     input.Locale,
+		Disabled:   database.StringList(input.Disabled),
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"locale", "disabled", "updated_at"}),
+	}).Create(&preference).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newNotificationPreferences(preference))
+}
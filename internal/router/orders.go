@@ -2,33 +2,284 @@ package router
 
 import (
 	"OnlineShop/internal/database"
+	"OnlineShop/internal/pricing"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type CreateOrderItemInput struct {
-	ProductID uint `json:"product_id" binding:"required"`
-	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+	ProductID uint  `json:"product_id" binding:"required"`
+	VariantID *uint `json:"variant_id" example:"3"`
+	Quantity  int   `json:"quantity" binding:"required,gt=0"`
+}
+
+type PreviewOrderInput struct {
+	Items             []CreateOrderItemInput `json:"items" binding:"required,min=1,dive"`
+	ShippingAddressID *uint                  `json:"shipping_address_id" example:"1"`
+	ShippingMethodID  *uint                  `json:"shipping_method_id" example:"1"`
+	CouponCode        string                 `json:"coupon_code" binding:"max=64" example:"WELCOME10"`
 }
 
 type CreateOrderInput struct {
-	Items []CreateOrderItemInput `json:"items" binding:"required,min=1"`
+	Items             []CreateOrderItemInput `json:"items" binding:"required,min=1,dive"`
+	ShippingAddressID *uint                  `json:"shipping_address_id" example:"1"`
+	BillingAddressID  *uint                  `json:"billing_address_id" example:"1"`
+	ShippingMethodID  *uint                  `json:"shipping_method_id" example:"1"`
+	CouponCode        string                 `json:"coupon_code" binding:"max=64" example:"WELCOME10"`
+}
+
+type CustomerOrderListQuery struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=Pending Paid Shipped Delivered Refunded Cancelled" example:"Paid"`
+}
+
+type OrderListQuery struct {
+	PaginationQuery
+	Status    string    `form:"status" binding:"omitempty,oneof=Pending Paid Shipped Delivered Refunded Cancelled" example:"Paid"`
+	Email     string    `form:"email" example:"gmail.com"`
+	From      time.Time `form:"from" time_format:"2006-01-02" example:"2025-01-01"`
+	To        time.Time `form:"to" time_format:"2006-01-02" example:"2025-12-31"`
+	MinTotal  *float64  `form:"min_total" binding:"omitempty,gte=0" example:"1000"`
+	MaxTotal  *float64  `form:"max_total" binding:"omitempty,gte=0" example:"5000"`
+	ProductID *uint     `form:"product_id" example:"1"`
+	Sort      string    `form:"sort" binding:"omitempty,oneof=order_date -order_date total -total" example:"-order_date"`
+}
+
+// orderSorts maps the sort values accepted by the admin order list to
+// ORDER BY clauses.
+var orderSorts = map[string]string{
+	"order_date":  "order_date ASC",
+	"-order_date": "order_date DESC",
+	"total":       "total ASC",
+	"-total":      "total DESC",
+}
+
+// taxSettings are the shop-wide tax rules, set from the configuration.
+var taxSettings pricing.TaxSettings
+
+var (
+	errProductNotFound = errors.New("product not found")
+	errAddressNotFound = errors.New("address not found")
+	errAddressRequired = errors.New("shipping address is required")
+	errMethodRequired  = errors.New("shipping method is required")
+	errVariantRequired = errors.New("variant must be chosen for this product")
+	errVariantNotFound = errors.New("variant does not belong to the product")
+	errOutOfStock      = errors.New("variant is out of stock")
+)
+
+// withDeleted makes order items resolve their product and variant even after
+// they have been deleted.
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// preloadOrderItems loads order items with their products, variants and
+// discounts.
+func preloadOrderItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items.Product", withDeleted).Preload("Items.Variant", withDeleted).Preload("Items.Adjustments")
+}
+
+// viewableOrders returns a scope limiting orders, or records with a
+// customer_id of their own such as invoices, to those the user may see: staff
+// with the orders.view permission see all of them, customers only their own.
+func viewableOrders(userID uint) (func(*gorm.DB) *gorm.DB, error) {
+	staff, err := database.CustomerHasPermission(database.DB, userID, database.PermissionOrdersView)
+	if err != nil {
+		return nil, err
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if staff {
+			return db
+		}
+		return db.Where("customer_id = ?", userID)
+	}, nil
+}
+
+// resolveOrderAddress returns the customer's address with the given ID or, if
+// no ID was passed, the address marked as default by defaultColumn.
+func resolveOrderAddress(tx *gorm.DB, customerID uint, addressID *uint, defaultColumn string) (*database.Address, error) {
+	var address database.Address
+	if addressID != nil {
+		found, err := findCustomerAddress(tx, customerID, *addressID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errAddressNotFound
+			}
+			return nil, err
+		}
+		return &found, nil
+	}
+
+	err := tx.Where("customer_id = ? AND "+defaultColumn+" = ?", customerID, true).First(&address).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// resolveOrderLines loads the published products and variants of the items
+// and turns them into lines to price. With reserve the stock of the variants
+// is reserved, otherwise it is only checked.
+func resolveOrderLines(tx *gorm.DB, items []CreateOrderItemInput, reserve bool) ([]pricing.Line, error) {
+	lines := make([]pricing.Line, 0, len(items))
+	for _, itemInput := range items {
+		var product database.Product
+		err := tx.Where("status = ?", database.ProductStatusPublished).First(&product, itemInput.ProductID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errProductNotFound
+			}
+			return nil, err
+		}
+
+		line := pricing.Line{
+			ProductID:  product.ID,
+			CategoryID: product.CategoryID,
+			TaxClassID: product.TaxClassID,
+			Name:       product.Name,
+			UnitPrice:  product.Price,
+			Quantity:   itemInput.Quantity,
+			Weight:     product.Weight,
+		}
+
+		if itemInput.VariantID == nil {
+			var variants int64
+			if err := tx.Model(&database.Variant{}).Where("product_id = ?", product.ID).Count(&variants).Error; err != nil {
+				return nil, err
+			}
+			if variants > 0 {
+				return nil, errVariantRequired
+			}
+		} else {
+			var variant database.Variant
+			err := tx.Where("product_id = ?", product.ID).First(&variant, *itemInput.VariantID).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, errVariantNotFound
+				}
+				return nil, err
+			}
+
+			available := variant.Stock >= itemInput.Quantity
+			if reserve {
+				available, err = database.ReserveStock(tx, variant.ID, itemInput.Quantity)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !available {
+				return nil, errOutOfStock
+			}
+
+			line.VariantID = &variant.ID
+			line.UnitPrice = variant.EffectivePrice(product)
+		}
+
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// quoteOrder prices the lines with the running promotions, the coupon, if a
+// code is given, and the taxes and shipping methods at the shipping address.
+// Without an address neither tax nor shipping is calculated. The coupon is
+// returned so that the caller can redeem it.
+func quoteOrder(tx *gorm.DB, customerID uint, lines []pricing.Line, couponCode string, shipping *database.AddressSnapshot, shippingMethodID *uint, now time.Time) (pricing.Quote, *database.Coupon, error) {
+	opts := pricing.Options{Tax: taxSettings, ShippingMethodID: shippingMethodID, Now: now}
+	if shipping == nil && shippingMethodID != nil {
+		return pricing.Quote{}, nil, errAddressRequired
+	}
+
+	var err error
+	opts.Promotions, err = database.ActivePromotions(tx, now)
+	if err != nil {
+		return pricing.Quote{}, nil, err
+	}
+	if shipping != nil {
+		opts.Taxes, err = database.LoadTaxTable(tx, shipping.Country, shipping.Region)
+		if err != nil {
+			return pricing.Quote{}, nil, err
+		}
+		opts.ShippingRates, err = database.ShippingRatesAt(tx, shipping.Country, shipping.Region)
+		if err != nil {
+			return pricing.Quote{}, nil, err
+		}
+	}
+
+	if couponCode != "" {
+		found, err := database.FindCoupon(tx, couponCode)
+		if err != nil {
+			return pricing.Quote{}, nil, err
+		}
+		if err := database.CheckCouponUsage(tx, found, customerID); err != nil {
+			return pricing.Quote{}, nil, err
+		}
+		opts.Coupon = &found
+	}
+
+	quote, err := pricing.Price(lines, opts)
+	return quote, opts.Coupon, err
+}
+
+// respondOrderError maps errors of pricing and creating an order to responses.
+func respondOrderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errProductNotFound):
+		c.JSON(http.StatusNotFound, HTTPError{Message: "One or more products not found"})
+	case errors.Is(err, errAddressNotFound):
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Address not found"})
+	case errors.Is(err, errAddressRequired):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Shipping address is required"})
+	case errors.Is(err, errMethodRequired), errors.Is(err, pricing.ErrShippingUnavailable):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	case errors.Is(err, errVariantRequired), errors.Is(err, errVariantNotFound):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	case errors.Is(err, errOutOfStock):
+		c.JSON(http.StatusConflict, HTTPError{Message: "One or more variants are out of stock"})
+	case errors.Is(err, database.ErrCouponUsageLimit), errors.Is(err, database.ErrCouponCustomerLimit):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	case errors.Is(err, database.ErrCouponNotFound), errors.Is(err, database.ErrCouponInactive),
+		errors.Is(err, database.ErrCouponExpired), errors.Is(err, database.ErrCouponMinTotal),
+		errors.Is(err, database.ErrCouponNotApplicable):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create order"})
+	}
 }
 
 // @Summary      Создать новый заказ
 // @Description  Создает новый заказ для аутентифицированного пользователя. Требует список ID товаров и их количество.
+// @Description  Для товаров с вариантами необходимо указать variant_id; остаток варианта резервируется.
+// @Description  Если адрес доставки не указан, используется адрес доставки по умолчанию. Адреса копируются в заказ.
+// @Description  К заказу автоматически применяются действующие акции, затем купон (coupon_code), если он указан.
+// @Description  Скидки сохраняются в позициях заказа (Discount и Adjustments) и в поле DiscountTotal заказа.
+// @Description  Налог рассчитывается по ставкам региона доставки и сохраняется в позициях (TaxRate, Tax) и в поле TaxTotal заказа.
+// @Description  Если цены включают налог (PricesIncludeTax), налог уже входит в Total, иначе добавляется к нему.
+// @Description  Если в магазине есть активные способы доставки, shipping_method_id обязателен; стоимость доставки
+// @Description  сохраняется в поле ShippingTotal и добавляется к Total. Доступные способы возвращает POST /shipping/quote.
+// @Description  Покупателю отправляется письмо с подтверждением заказа, если он не отключил такие уведомления.
+// @Description  Повтор запроса с тем же заголовком Idempotency-Key не создает новый заказ, а возвращает ответ на первый запрос
+// @Description  (с заголовком Idempotent-Replayed: true). Ключ действует IDEMPOTENCY_KEY_TTL; с другим телом запроса его использовать нельзя.
 // @Tags         Заказы (Orders)
 // @Accept       json
 // @Produce      json
-// @Param        order  body      CreateOrderInput  true  "Данные для создания нового заказа"
+// @Param        Idempotency-Key  header    string            false  "Уникальный ключ запроса для безопасных повторов"
+// @Param        order            body      CreateOrderInput  true   "Данные для создания нового заказа"
 // @Security     BearerAuth
 // @Success      201  {object}  database.Order "Возвращает созданный заказ со всеми позициями"
-// @Failure      400  {object}  HTTPError      "Ошибка валидации входных данных"
+// @Failure      400  {object}  HTTPError      "Ошибка валидации входных данных, не выбран вариант товара или способ доставки, купон недействителен"
 // @Failure      401  {object}  HTTPError      "Ошибка аутентификации"
-// @Failure      404  {object}  HTTPError      "Один или несколько товаров не найдены"
+// @Failure      404  {object}  HTTPError      "Один или несколько товаров или адрес не найдены"
+// @Failure      409  {object}  HTTPError      "Недостаточно товара на складе, исчерпан лимит использования купона или запрос с тем же Idempotency-Key еще выполняется"
+// @Failure      422  {object}  HTTPError      "Idempotency-Key уже использован с другим запросом"
 // @Failure      500  {object}  HTTPError      "Внутренняя ошибка сервера"
 // @Router       /orders [post]
 func createOrder(c *gin.Context) {
@@ -47,49 +298,114 @@ func createOrder(c *gin.Context) {
 	orderToCreate := database.Order{
 		CustomerID: userID.(uint),
 		OrderDate:  time.Now(),
-		Status:     "Pending",
+		Status:     database.OrderStatusPending,
 	}
 
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(&orderToCreate).Error; err != nil {
+		shipping, err := resolveOrderAddress(tx, orderToCreate.CustomerID, input.ShippingAddressID, "is_default_shipping")
+		if err != nil {
 			return err
 		}
+		if shipping == nil {
+			return errAddressRequired
+		}
 
-		for _, itemInput := range input.Items {
-			var product database.Product
-			if err := tx.First(&product, itemInput.ProductID).Error; err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return errors.New("product not found")
-				}
+		billing, err := resolveOrderAddress(tx, orderToCreate.CustomerID, input.BillingAddressID, "is_default_billing")
+		if err != nil {
+			return err
+		}
+		if billing == nil {
+			billing = shipping
+		}
+
+		orderToCreate.ShippingAddress = shipping.Snapshot()
+		orderToCreate.BillingAddress = billing.Snapshot()
+
+		if input.ShippingMethodID == nil {
+			var methods int64
+			if err := tx.Model(&database.ShippingMethod{}).Where("active = ?", true).Count(&methods).Error; err != nil {
 				return err
 			}
+			if methods > 0 {
+				return errMethodRequired
+			}
+		}
+
+		lines, err := resolveOrderLines(tx, input.Items, true)
+		if err != nil {
+			return err
+		}
+		quote, coupon, err := quoteOrder(tx, orderToCreate.CustomerID, lines, input.CouponCode, &orderToCreate.ShippingAddress, input.ShippingMethodID, orderToCreate.OrderDate)
+		if err != nil {
+			return err
+		}
+
+		orderToCreate.Subtotal = quote.Subtotal
+		orderToCreate.DiscountTotal = quote.DiscountTotal
+		orderToCreate.TaxTotal = quote.TaxTotal
+		orderToCreate.PricesIncludeTax = quote.PricesIncludeTax
+		orderToCreate.ShippingMethodID = quote.ShippingMethodID
+		orderToCreate.ShippingMethod = quote.ShippingMethod
+		orderToCreate.ShippingTotal = quote.ShippingTotal
+		orderToCreate.Total = quote.Total
+		if coupon != nil {
+			orderToCreate.CouponCode = &coupon.Code
+		}
+		if err := tx.Create(&orderToCreate).Error; err != nil {
+			return err
+		}
+		if err := database.RecordOrderStatus(tx, orderToCreate.ID, "", orderToCreate.Status, &orderToCreate.CustomerID); err != nil {
+			return err
+		}
 
+		for _, line := range quote.Lines {
 			orderItem := database.OrderItem{
 				OrderID:   orderToCreate.ID,
-				ProductID: itemInput.ProductID,
-				Quantity:  itemInput.Quantity,
-				Price:     product.Price,
+				ProductID: line.ProductID,
+				VariantID: line.VariantID,
+				Quantity:  line.Quantity,
+				Price:     line.UnitPrice,
+				Discount:  line.Discount,
+				TaxRate:   line.TaxRate,
+				Tax:       line.Tax,
 			}
-
 			if err := tx.Create(&orderItem).Error; err != nil {
 				return err
 			}
+			for _, adjustment := range line.Adjustments {
+				err := tx.Create(&database.OrderAdjustment{
+					OrderID:     orderToCreate.ID,
+					OrderItemID: orderItem.ID,
+					PromotionID: adjustment.PromotionID,
+					CouponID:    adjustment.CouponID,
+					Label:       adjustment.Label,
+					Amount:      adjustment.Amount,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
 		}
 
-		return nil
+		if coupon != nil {
+			err := database.RedeemCoupon(tx, *coupon, orderToCreate.CustomerID, orderToCreate.ID, quote.CouponDiscount)
+			if err != nil {
+				return err
+			}
+		}
+		if err := database.EnqueueOrderNotification(tx, database.NotificationOrderPlaced, orderToCreate.ID, nil); err != nil {
+			return err
+		}
+		return database.EnqueueOrderCreated(tx, orderToCreate.ID)
 	})
 
 	if err != nil {
-		if err.Error() == "product not found" {
-			c.JSON(http.StatusNotFound, HTTPError{Message: "One or more products not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create order"})
+		respondOrderError(c, err)
 		return
 	}
 
 	var finalOrder database.Order
-	if err := database.DB.Preload("Items.Product").First(&finalOrder, orderToCreate.ID).Error; err != nil {
+	if err := database.DB.Scopes(preloadOrderItems).First(&finalOrder, orderToCreate.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch created order"})
 		return
 	}
@@ -97,14 +413,74 @@ func createOrder(c *gin.Context) {
 	c.JSON(http.StatusCreated, finalOrder)
 }
 
+// @Summary      Рассчитать стоимость корзины
+// @Description  Рассчитывает цены, скидки по акциям и купону для набора товаров так же, как при создании заказа, но не создает заказ,
+// @Description  не резервирует товар и не расходует купон. Для каждой позиции указано, какие акции и купон к ней применены.
+// @Description  Налог и доступные способы доставки (shipping_options) рассчитываются по адресу доставки (shipping_address_id или адрес по умолчанию);
+// @Description  без адреса они не считаются. Если указан shipping_method_id, стоимость доставки добавляется к итогу.
+// @Tags         Заказы (Orders)
+// @Accept       json
+// @Produce      json
+// @Param        basket  body      PreviewOrderInput  true  "Товары и код купона"
+// @Security     BearerAuth
+// @Success      200     {object}  pricing.Quote      "Расчет стоимости"
+// @Failure      400     {object}  HTTPError          "Ошибка валидации входных данных, купон недействителен или способ доставки недоступен"
+// @Failure      401     {object}  HTTPError          "Ошибка аутентификации"
+// @Failure      404     {object}  HTTPError          "Один или несколько товаров или адрес не найдены"
+// @Failure      409     {object}  HTTPError          "Недостаточно товара на складе или исчерпан лимит использования купона"
+// @Failure      500     {object}  HTTPError          "Внутренняя ошибка сервера"
+// @Router       /orders/preview [post]
+func previewOrder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var input PreviewOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	shipping, err := resolveOrderAddress(database.DB, userID.(uint), input.ShippingAddressID, "is_default_shipping")
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+	var snapshot *database.AddressSnapshot
+	if shipping != nil {
+		address := shipping.Snapshot()
+		snapshot = &address
+	}
+
+	lines, err := resolveOrderLines(database.DB, input.Items, false)
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+	quote, _, err := quoteOrder(database.DB, userID.(uint), lines, input.CouponCode, snapshot, input.ShippingMethodID, time.Now())
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
 // @Summary      Получить список заказов пользователя
-// @Description  Возвращает все заказы, сделанные аутентифицированным пользователем, с полной информацией о товарах.
+// @Description  Возвращает постраничный список заказов аутентифицированного пользователя, начиная с последних,
+// @Description  с полной информацией о товарах. Заказы можно отфильтровать по статусу.
 // @Tags         Заказы (Orders)
 // @Produce      json
+// @Param        status     query     string  false  "Статус заказа (Pending, Paid, Shipped, Delivered, Refunded, Cancelled)"
+// @Param        page       query     int     false  "Номер страницы"  default(1)
+// @Param        page_size  query     int     false  "Размер страницы"  default(20)
 // @Security     BearerAuth
-// @Success      200  {array}   database.Order "Массив заказов пользователя"
-// @Failure      401  {object}  HTTPError      "Ошибка аутентификации"
-// @Failure      500  {object}  HTTPError      "Внутренняя ошибка сервера"
+// @Success      200  {object}  router.Page[database.Order]  "Страница заказов пользователя"
+// @Failure      400  {object}  HTTPError                    "Некорректные параметры запроса"
+// @Failure      401  {object}  HTTPError                    "Ошибка аутентификации"
+// @Failure      500  {object}  HTTPError                    "Внутренняя ошибка сервера"
 // @Router       /orders [get]
 func getOrders(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -113,44 +489,231 @@ func getOrders(c *gin.Context) {
 		return
 	}
 
-	var orders []database.Order
+	var query CustomerOrderListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
 
-	err := database.DB.
-		Preload("Items.Product").
-		Where("customer_id = ?", userID).
-		Order("order_date DESC").
-		Find(&orders).Error
+	db := database.DB.Model(&database.Order{}).Where("customer_id = ?", userID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
 
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch orders"})
+		return
+	}
+
+	var orders []database.Order
+	err := db.Scopes(preloadOrderItems).
+		Order("order_date DESC, id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&orders).Error
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch orders"})
 		return
 	}
 
-	c.JSON(http.StatusOK, orders)
+	c.JSON(http.StatusOK, newPage(orders, query.PaginationQuery, total))
 }
 
-// @Summary      Получить список всех незавершенных заказов
-// @Description  Возвращает список всех заказов в статусе "Pending". Доступно только для администраторов.
+// @Summary      Получить список заказов магазина
+// @Description  Возвращает постраничный список всех заказов с покупателями. Заказы можно отфильтровать по статусу, части email
+// @Description  покупателя, дате оформления, сумме и товару в заказе и отсортировать по дате или сумме (с "-" — по убыванию).
 // @Tags         Администрирование (Admin)
 // @Produce      json
+// @Param        status      query     string  false  "Статус заказа (Pending, Paid, Shipped, Delivered, Refunded, Cancelled)"
+// @Param        email       query     string  false  "Часть email покупателя"
+// @Param        from        query     string  false  "Дата оформления с (YYYY-MM-DD)"
+// @Param        to          query     string  false  "Дата оформления по (YYYY-MM-DD), включительно"
+// @Param        min_total   query     number  false  "Минимальная сумма заказа"
+// @Param        max_total   query     number  false  "Максимальная сумма заказа"
+// @Param        product_id  query     int     false  "ID товара в заказе"
+// @Param        sort        query     string  false  "Сортировка (order_date, -order_date, total, -total)"  default(-order_date)
+// @Param        page        query     int     false  "Номер страницы"  default(1)
+// @Param        page_size   query     int     false  "Размер страницы"  default(20)
 // @Security     BearerAuth
-// @Success      200  {array}   database.Order "Список незавершенных заказов"
-// @Failure      500  {object}  HTTPError      "Внутренняя ошибка сервера"
-// @Router       /orders/pending [get]
-func getPendingOrders(c *gin.Context) {
+// @Success      200  {object}  router.Page[database.Order]  "Страница заказов"
+// @Failure      400  {object}  router.HTTPError             "Некорректные параметры запроса"
+// @Failure      403  {object}  router.HTTPError             "Недостаточно прав"
+// @Failure      500  {object}  router.HTTPError             "Внутренняя ошибка сервера"
+// @Router       /orders/all [get]
+func getAllOrders(c *gin.Context) {
+	var query OrderListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+	if query.Sort == "" {
+		query.Sort = "-order_date"
+	}
+
+	db := database.DB.Model(&database.Order{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Email != "" {
+		db = db.Where(`customer_id IN (SELECT id FROM customers WHERE LOWER(email) LIKE ? ESCAPE '\')`, database.ContainsPattern(strings.ToLower(query.Email)))
+	}
+	if !query.From.IsZero() {
+		db = db.Where("order_date >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("order_date < ?", query.To.AddDate(0, 0, 1))
+	}
+	if query.MinTotal != nil {
+		db = db.Where("total >= ?", *query.MinTotal)
+	}
+	if query.MaxTotal != nil {
+		db = db.Where("total <= ?", *query.MaxTotal)
+	}
+	if query.ProductID != nil {
+		db = db.Where("EXISTS (SELECT 1 FROM order_items WHERE order_items.order_id = orders.id AND order_items.product_id = ?)", *query.ProductID)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch orders"})
+		return
+	}
+
 	var orders []database.Order
+	err := db.Preload("Customer").
+		Order(orderSorts[query.Sort]).
+		Order("id DESC").
+		Offset(query.offset()).
+		Limit(query.PageSize).
+		Find(&orders).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(orders, query.PaginationQuery, total))
+}
+
+// @Summary      Получить заказ по ID
+// @Description  Возвращает заказ с покупателем, позициями, платежами, отправлениями и историей статусов. Покупателю доступны только
+// @Description  его собственные заказы, чужие для него не существуют (404); сотрудникам с правом orders.view — любые.
+// @Tags         Заказы (Orders)
+// @Produce      json
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Order    "Заказ"
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID заказа"
+// @Failure      401  {object}  router.HTTPError  "Ошибка аутентификации"
+// @Failure      404  {object}  router.HTTPError  "Заказ не найден"
+// @Failure      500  {object}  router.HTTPError  "Внутренняя ошибка сервера"
+// @Router       /orders/{id} [get]
+func getOrder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid order ID"})
+		return
+	}
 
-	err := database.DB.
-		Preload("Items.Product").
+	viewable, err := viewableOrders(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to check permissions"})
+		return
+	}
+
+	var order database.Order
+	err = database.DB.
+		Scopes(viewable, preloadOrderItems).
 		Preload("Customer").
-		Where("status = ?", "Pending").
-		Order("order_date ASC").
-		Find(&orders).Error
+		Preload("Payments").
+		Preload("Shipments.Items").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		First(&order, orderID).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary      Отменить заказ
+// @Description  Отменяет заказ, ожидающий оплаты (Pending): зарезервированный товар возвращается на склад, использование купона
+// @Description  отменяется. Если покупатель все же завершит начатый платеж, платеж помечается как требующий возврата (NeedsRefund).
+// @Description  Покупатель может отменить свой заказ, сотрудник с правом orders.manage — любой.
+// @Description  Покупателю отправляется письмо об отмене. Оплаченный заказ отменить нельзя — по нему оформляется возврат.
+// @Tags         Заказы (Orders)
+// @Produce      json
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Order    "Отмененный заказ"
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID заказа"
+// @Failure      401  {object}  router.HTTPError  "Ошибка аутентификации"
+// @Failure      404  {object}  router.HTTPError  "Заказ не найден"
+// @Failure      409  {object}  router.HTTPError  "Заказ уже оплачен или отменен"
+// @Failure      500  {object}  router.HTTPError  "Внутренняя ошибка сервера"
+// @Router       /orders/{id}/cancel [post]
+func cancelOrder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid order ID"})
+		return
+	}
+
+	staff, err := database.CustomerHasPermission(database.DB, userID.(uint), database.PermissionOrdersManage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to check permissions"})
+		return
+	}
 
+	query := database.DB
+	if !staff {
+		query = query.Where("customer_id = ?", userID)
+	}
+	var order database.Order
+	if err := query.First(&order, orderID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+		return
+	}
+
+	before := order
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := database.CancelOrder(tx, &order, currentActor(c)); err != nil {
+			return err
+		}
+		return database.EnqueueOrderNotification(tx, database.NotificationOrderCancelled, order.ID, nil)
+	})
+	if errors.Is(err, database.ErrOrderNotCancellable) {
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch pending orders"})
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to cancel order"})
+		return
+	}
+	setAudit(c, "order.cancel", "order", order.ID, before, order)
+
+	var finalOrder database.Order
+	if err := database.DB.Scopes(preloadOrderItems).First(&finalOrder, order.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch cancelled order"})
 		return
 	}
 
-	c.JSON(http.StatusOK, orders)
+	c.JSON(http.StatusOK, finalOrder)
 }
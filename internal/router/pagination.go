@@ -0,0 +1,42 @@
+package router
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type PaginationQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1" example:"1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100" example:"20"`
+}
+
+// Page is the envelope returned by list endpoints that support pagination.
+type Page[T any] struct {
+	Items    []T   `json:"items"`
+	Page     int   `json:"page" example:"1"`
+	PageSize int   `json:"page_size" example:"20"`
+	Total    int64 `json:"total" example:"42"`
+}
+
+func (q *PaginationQuery) normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+}
+
+func (q PaginationQuery) offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+func newPage[T any](items []T, q PaginationQuery, total int64) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, Page: q.Page, PageSize: q.PageSize, Total: total}
+}
@@ -0,0 +1,369 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/payment"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxWebhookSize limits the body of payment webhooks read into memory.
+const maxWebhookSize = 1 << 20
+
+// paymentCreateTimeout is how long a payment may wait for the provider to
+// register it. A pending payment without a provider ID after that was never
+// shown to the customer and is failed to let them pay again.
+const paymentCreateTimeout = 5 * time.Minute
+
+var errOrderNotAwaitingPayment = errors.New("order is not awaiting payment")
+
+var (
+	paymentProvider payment.Provider
+	shopCurrency    string
+)
+
+type PaymentListQuery struct {
+	PaginationQuery
+	Status      string `form:"status" binding:"omitempty,oneof=pending succeeded failed canceled refunded"`
+	NeedsRefund *bool  `form:"needs_refund"`
+	OrderID     uint   `form:"order_id"`
+}
+
+type FakePaymentInput struct {
+	Status string `json:"status" binding:"required,oneof=succeeded failed canceled" example:"succeeded"`
+}
+
+// @Summary      Оплатить заказ
+// @Description  Создает платеж на сумму заказа у платежного провайдера и возвращает его. Для оплаты покупателя нужно
+// @Description  перенаправить по адресу ConfirmationURL. Заказ становится оплаченным (Paid), когда провайдер сообщит об успешной оплате.
+// @Description  Оплатить можно только заказ в статусе Pending. У заказа может быть только один незавершенный платеж: если он уже есть,
+// @Description  возвращается он (200), чтобы покупатель не оплатил заказ дважды; после неудачного платежа можно создать новый.
+// @Description  Повтор запроса с тем же заголовком Idempotency-Key возвращает уже созданный платеж.
+// @Tags         Платежи (Payments)
+// @Produce      json
+// @Param        id               path      int     true   "ID заказа"
+// @Param        Idempotency-Key  header    string  false  "Уникальный ключ запроса для безопасных повторов"
+// @Security     BearerAuth
+// @Success      200              {object}  database.Payment  "Незавершенный платеж заказа"
+// @Success      201              {object}  database.Payment
+// @Failure      401              {object}  router.HTTPError
+// @Failure      404              {object}  router.HTTPError  "Заказ не найден"
+// @Failure      409              {object}  router.HTTPError  "Заказ не ожидает оплаты, платеж еще создается или запрос с тем же Idempotency-Key еще выполняется"
+// @Failure      422              {object}  router.HTTPError  "Idempotency-Key уже использован с другим запросом"
+// @Failure      502              {object}  router.HTTPError  "Платежный провайдер недоступен"
+// @Failure      500              {object}  router.HTTPError
+// @Router       /orders/{id}/payments [post]
+func createPayment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var order database.Order
+	var paymentToCreate database.Payment
+	var pending *database.Payment
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// The order stays locked until the transaction ends, so concurrent
+		// requests cannot both create a payment.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_id = ?", userID).
+			First(&order, c.Param("id")).Error
+		if err != nil {
+			return err
+		}
+		if order.Status != database.OrderStatusPending {
+			return errOrderNotAwaitingPayment
+		}
+
+		pending, err = database.PendingPayment(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if pending != nil && pending.ExternalID == nil && time.Since(pending.CreatedAt) > paymentCreateTimeout {
+			if err := tx.Model(pending).Update("status", database.PaymentStatusFailed).Error; err != nil {
+				return err
+			}
+			pending = nil
+		}
+		if pending != nil {
+			return nil
+		}
+		paymentToCreate = database.Payment{
+			OrderID:  order.ID,
+			Provider: paymentProvider.Name(),
+			Amount:   order.Total,
+			Currency: shopCurrency,
+			Status:   database.PaymentStatusPending,
+		}
+		return tx.Create(&paymentToCreate).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+		return
+	case errors.Is(err, errOrderNotAwaitingPayment):
+		c.JSON(http.StatusConflict, HTTPError{Message: "Order is not awaiting payment"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create payment"})
+		return
+	}
+
+	if pending != nil {
+		// Without an external ID another request is still registering the
+		// payment with the provider.
+		if pending.ExternalID == nil {
+			c.JSON(http.StatusConflict, HTTPError{Message: database.ErrPaymentInProgress.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, pending)
+		return
+	}
+
+	session, err := paymentProvider.CreatePayment(c.Request.Context(), payment.Request{
+		PaymentID:   paymentToCreate.ID,
+		OrderID:     order.ID,
+		Amount:      paymentToCreate.Amount,
+		Currency:    paymentToCreate.Currency,
+		Description: fmt.Sprintf("Order #%d", order.ID),
+	})
+	if err != nil {
+		database.DB.Model(&paymentToCreate).Update("status", database.PaymentStatusFailed)
+		c.JSON(http.StatusBadGateway, HTTPError{Message: "Payment provider is unavailable"})
+		return
+	}
+
+	paymentToCreate.ExternalID = &session.ExternalID
+	paymentToCreate.ConfirmationURL = session.ConfirmationURL
+	if err := database.DB.Save(&paymentToCreate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create payment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, paymentToCreate)
+}
+
+// handlePaymentWebhook verifies a webhook of the payment provider and records
+// the payment outcome it reports. Webhooks for payments that have already
+// completed are acknowledged without changes, as providers resend them until
+// they are acknowledged.
+func handlePaymentWebhook(c *gin.Context, header http.Header, body []byte) {
+	event, err := paymentProvider.ParseWebhook(header, body)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var paymentToUpdate database.Payment
+	err = database.DB.Where("provider = ? AND external_id = ?", paymentProvider.Name(), event.ExternalID).
+		First(&paymentToUpdate).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Payment not found"})
+		return
+	}
+
+	before := paymentToUpdate
+	var changed, orderPaid bool
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		changed, orderPaid, err = database.CompletePayment(tx, &paymentToUpdate, event.Status, now)
+		// Only the payment that moved the order to Paid paid for it.
+		if err != nil || !orderPaid {
+			return err
+		}
+		if _, err := database.IssueInvoice(tx, paymentToUpdate.OrderID, invoiceSeller, paymentToUpdate.Currency, now); err != nil {
+			return err
+		}
+		return database.EnqueueOrderNotification(tx, database.NotificationOrderPaid, paymentToUpdate.OrderID, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update payment"})
+		return
+	}
+	if changed {
+		setAudit(c, "payment."+event.Status, "payment", paymentToUpdate.ID, before, paymentToUpdate)
+	}
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Webhook processed"})
+}
+
+// @Summary      Webhook платежного провайдера
+// @Description  Принимает уведомление провайдера об изменении статуса платежа. Тело должно быть подписано HMAC-SHA256
+// @Description  секретом PAYMENT_WEBHOOK_SECRET (заголовок X-Signature: sha256=<hex>). При успешной оплате заказ становится оплаченным (Paid).
+// @Description  Повторные уведомления по уже завершенному платежу подтверждаются без изменений. На оплаченный заказ выставляется счет,
+// @Description  покупателю отправляется письмо об оплате. Успешный платеж за заказ, который уже не ожидает оплаты, не меняет заказ
+// @Description  и помечается как требующий возврата (NeedsRefund).
+// @Tags         Платежи (Payments)
+// @Accept       json
+// @Produce      json
+// @Param        X-Signature  header    string  true  "Подпись тела запроса"
+// @Success      200          {object}  router.SuccessMessage
+// @Failure      400          {object}  router.HTTPError  "Некорректное уведомление"
+// @Failure      401          {object}  router.HTTPError  "Неверная подпись"
+// @Failure      404          {object}  router.HTTPError  "Платеж не найден"
+// @Failure      500          {object}  router.HTTPError
+// @Router       /payments/webhook [post]
+func paymentWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Failed to read request body"})
+		return
+	}
+	handlePaymentWebhook(c, c.Request.Header, body)
+}
+
+// @Summary      Подтвердить тестовый платеж
+// @Description  Страница оплаты встроенного тестового провайдера (PAYMENT_PROVIDER=fake): завершает платеж с указанным статусом,
+// @Description  отправляя подписанное уведомление так же, как это сделал бы настоящий провайдер. Доступно только с тестовым провайдером
+// @Description  и только при PAYMENT_FAKE_CONFIRM=true: эндпоинт позволяет оплатить заказ без денег и предназначен для разработки и тестов.
+// @Tags         Платежи (Payments)
+// @Accept       json
+// @Produce      json
+// @Param        externalId  path      string                   true  "ID платежа у провайдера"
+// @Param        payment     body      router.FakePaymentInput  true  "Результат оплаты"
+// @Success      200         {object}  router.SuccessMessage
+// @Failure      400         {object}  router.HTTPError
+// @Failure      404         {object}  router.HTTPError  "Платеж не найден"
+// @Failure      500         {object}  router.HTTPError
+// @Router       /payments/fake/{externalId} [post]
+func confirmFakePayment(c *gin.Context) {
+	var input FakePaymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	header, body, err := paymentProvider.(*payment.Fake).Webhook(c.Param("externalId"), input.Status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to sign webhook"})
+		return
+	}
+	handlePaymentWebhook(c, header, body)
+}
+
+// @Summary      Получить платежи
+// @Description  Возвращает постраничный список платежей, новые первыми, с фильтрами по статусу и заказу. С needs_refund=true
+// @Description  возвращаются успешные платежи, которые не оплатили заказ (например, второй платеж за уже оплаченный или отмененный заказ):
+// @Description  деньги по ним нужно вернуть покупателю.
+// @Tags         Платежи (Payments)
+// @Produce      json
+// @Param        status        query     string  false  "Статус платежа"  Enums(pending, succeeded, failed, canceled, refunded)
+// @Param        needs_refund  query     bool    false  "Только платежи, требующие возврата"
+// @Param        order_id      query     int     false  "ID заказа"
+// @Param        page          query     int     false  "Номер страницы"  default(1)
+// @Param        page_size     query     int     false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200           {object}  router.Page[database.Payment]
+// @Failure      400           {object}  router.HTTPError
+// @Failure      403           {object}  router.HTTPError
+// @Failure      500           {object}  router.HTTPError
+// @Router       /payments [get]
+func getPayments(c *gin.Context) {
+	var query PaymentListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.Payment{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.NeedsRefund != nil {
+		db = db.Where("needs_refund = ?", *query.NeedsRefund)
+	}
+	if query.OrderID != 0 {
+		db = db.Where("order_id = ?", query.OrderID)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch payments"})
+		return
+	}
+	var payments []database.Payment
+	err := db.Order("created_at DESC, id DESC").
+		Offset(query.offset()).Limit(query.PageSize).
+		Find(&payments).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch payments"})
+		return
+	}
+	c.JSON(http.StatusOK, newPage(payments, query.PaginationQuery, total))
+}
+
+// @Summary      Вернуть платеж, не оплативший заказ
+// @Description  Возвращает покупателю всю сумму успешного платежа, помеченного как требующий возврата (NeedsRefund), через провайдера,
+// @Description  и переводит платеж в статус refunded. Провайдер выплачивает возврат по платежу один раз, поэтому запрос можно безопасно повторить.
+// @Tags         Платежи (Payments)
+// @Produce      json
+// @Param        id   path      int  true  "ID платежа"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Payment
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID"
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Платеж не найден"
+// @Failure      409  {object}  router.HTTPError  "Платеж не требует возврата или принят другим провайдером"
+// @Failure      502  {object}  router.HTTPError  "Платежный провайдер не выполнил возврат"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /payments/{id}/refund [post]
+func refundPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid payment ID"})
+		return
+	}
+
+	var paid database.Payment
+	if err := database.DB.First(&paid, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Payment not found"})
+		return
+	}
+	if !paid.NeedsRefund {
+		c.JSON(http.StatusConflict, HTTPError{Message: database.ErrPaymentNoRefund.Error()})
+		return
+	}
+	if paid.Provider != paymentProvider.Name() || paid.ExternalID == nil {
+		c.JSON(http.StatusConflict, HTTPError{Message: "Payment was taken through another payment provider"})
+		return
+	}
+
+	before := paid
+	refundExternalID, err := paymentProvider.Refund(c.Request.Context(), payment.RefundRequest{
+		PaymentExternalID: *paid.ExternalID,
+		Amount:            paid.Amount,
+		Currency:          paid.Currency,
+		Reason:            fmt.Sprintf("Payment #%d did not pay for order #%d", paid.ID, paid.OrderID),
+		IdempotencyKey:    fmt.Sprintf("payment-%d", paid.ID),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, HTTPError{Message: fmt.Sprintf("%v: %v", payment.ErrRefundFailed, err)})
+		return
+	}
+
+	err = database.MarkPaymentRefunded(database.DB, &paid, refundExternalID)
+	if errors.Is(err, database.ErrPaymentNoRefund) {
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update payment"})
+		return
+	}
+	setAudit(c, "payment.refund", "payment", paid.ID, before, paid)
+
+	c.JSON(http.StatusOK, paid)
+}
@@ -0,0 +1,225 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+var (
+	errInvalidSchedulePeriod = errors.New("ends_at must be after starts_at")
+	errScheduleOverlap       = errors.New("the period overlaps another scheduled price of the product")
+	errScheduleNotPending    = errors.New("only pending or active scheduled prices can be cancelled")
+)
+
+type ScheduledPriceInput struct {
+	Price    float64    `json:"price" binding:"required,gt=0" example:"990"`
+	StartsAt time.Time  `json:"starts_at" binding:"required" example:"2025-11-28T00:00:00Z"`
+	EndsAt   *time.Time `json:"ends_at" example:"2025-12-01T00:00:00Z"`
+}
+
+type PriceHistoryQuery struct {
+	PaginationQuery
+}
+
+// checkScheduleOverlap rejects a period that intersects a pending or active
+// schedule of the same product. A schedule without ends_at lasts forever.
+func checkScheduleOverlap(tx *gorm.DB, productID uint, startsAt time.Time, endsAt *time.Time) error {
+	query := tx.Model(&database.ScheduledPrice{}).
+		Where("product_id = ? AND status IN ?", productID, []string{database.ScheduleStatusPending, database.ScheduleStatusActive}).
+		Where("ends_at IS NULL OR ends_at > ?", startsAt)
+	if endsAt != nil {
+		query = query.Where("starts_at < ?", *endsAt)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errScheduleOverlap
+	}
+	return nil
+}
+
+// @Summary      История цен товара
+// @Description  Возвращает изменения цены товара от новых к старым: кто и когда изменил цену и каким способом (manual, import, schedule).
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id         path      int  true   "ID товара"
+// @Param        page       query     int  false  "Номер страницы"  default(1)
+// @Param        page_size  query     int  false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200        {object}  router.Page[database.PriceChange]
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      404        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /products/{id}/prices [get]
+func getPriceHistory(c *gin.Context) {
+	var product database.Product
+	if err := database.DB.Unscoped().First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+
+	var query PriceHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.PriceChange{}).Where("product_id = ?", product.ID)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch price history"})
+		return
+	}
+	var changes []database.PriceChange
+	if err := db.Order("changed_at DESC, id DESC").Offset(query.offset()).Limit(query.PageSize).Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch price history"})
+		return
+	}
+	c.JSON(http.StatusOK, newPage(changes, query.PaginationQuery, total))
+}
+
+// @Summary      Запланированные цены товара
+// @Description  Возвращает все запланированные изменения цены товара, включая завершенные и отмененные.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id   path      int  true  "ID товара"
+// @Security     BearerAuth
+// @Success      200  {array}   database.ScheduledPrice
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /products/{id}/price-schedules [get]
+func getPriceSchedules(c *gin.Context) {
+	var product database.Product
+	if err := database.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+
+	var schedules []database.ScheduledPrice
+	if err := database.DB.Where("product_id = ?", product.ID).Order("starts_at DESC").Find(&schedules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch scheduled prices"})
+		return
+	}
+	c.JSON(http.StatusOK, schedules)
+}
+
+// @Summary      Запланировать изменение цены
+// @Description  Планирует новую цену товара с момента starts_at. Если указан ends_at, это распродажа:
+// @Description  на время ее действия прежняя цена показывается в CompareAtPrice, а после окончания восстанавливается, если цену не изменили вручную во время распродажи.
+// @Description  Без ends_at цена меняется навсегда. Периоды запланированных цен одного товара не должны пересекаться.
+// @Tags         Товары (Products)
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                          true  "ID товара"
+// @Param        schedule  body      router.ScheduledPriceInput  true  "Цена и период действия"
+// @Security     BearerAuth
+// @Success      201       {object}  database.ScheduledPrice
+// @Failure      400       {object}  router.HTTPError
+// @Failure      403       {object}  router.HTTPError
+// @Failure      404       {object}  router.HTTPError
+// @Failure      409       {object}  router.HTTPError  "Период пересекается с другой запланированной ценой"
+// @Failure      500       {object}  router.HTTPError
+// @Router       /products/{id}/price-schedules [post]
+func createPriceSchedule(c *gin.Context) {
+	var product database.Product
+	if err := database.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+
+	var input ScheduledPriceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	if input.EndsAt != nil && !input.EndsAt.After(input.StartsAt) {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: errInvalidSchedulePeriod.Error()})
+		return
+	}
+	if input.EndsAt != nil && !input.EndsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "ends_at must be in the future"})
+		return
+	}
+
+	schedule := database.ScheduledPrice{
+		ProductID: product.ID,
+		Price:     input.Price,
+		StartsAt:  input.StartsAt,
+		EndsAt:    input.EndsAt,
+		Status:    database.ScheduleStatusPending,
+		CreatedBy: currentActor(c),
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkScheduleOverlap(tx, product.ID, schedule.StartsAt, schedule.EndsAt); err != nil {
+			return err
+		}
+		return tx.Create(&schedule).Error
+	})
+	if err != nil {
+		if errors.Is(err, errScheduleOverlap) {
+			c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to schedule price"})
+		return
+	}
+	setAudit(c, "product.price_schedule.create", "product", product.ID, nil, schedule)
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// @Summary      Отменить запланированную цену
+// @Description  Отменяет запланированное изменение цены. Если распродажа уже идет, она завершается досрочно и товару возвращается прежняя цена.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id          path      int  true  "ID товара"
+// @Param        scheduleId  path      int  true  "ID запланированной цены"
+// @Security     BearerAuth
+// @Success      200         {object}  router.SuccessMessage
+// @Failure      403         {object}  router.HTTPError
+// @Failure      404         {object}  router.HTTPError
+// @Failure      409         {object}  router.HTTPError  "Запланированная цена уже применена или отменена"
+// @Failure      500         {object}  router.HTTPError
+// @Router       /products/{id}/price-schedules/{scheduleId} [delete]
+func cancelPriceSchedule(c *gin.Context) {
+	var schedule database.ScheduledPrice
+	if err := database.DB.Where("product_id = ?", c.Param("id")).First(&schedule, c.Param("scheduleId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Scheduled price not found"})
+		return
+	}
+
+	before := schedule
+	cancelled := false
+	var err error
+	switch schedule.Status {
+	case database.ScheduleStatusPending:
+		result := database.DB.Model(&database.ScheduledPrice{}).
+			Where("id = ? AND status = ?", schedule.ID, database.ScheduleStatusPending).
+			Update("status", database.ScheduleStatusCancelled)
+		err, cancelled = result.Error, result.RowsAffected > 0
+	case database.ScheduleStatusActive:
+		cancelled, err = database.EndScheduledPrice(database.DB, schedule, database.ScheduleStatusCancelled)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to cancel scheduled price"})
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusConflict, HTTPError{Message: errScheduleNotPending.Error()})
+		return
+	}
+	schedule.Status = database.ScheduleStatusCancelled
+	setAudit(c, "product.price_schedule.cancel", "product", schedule.ProductID, before, schedule)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Scheduled price cancelled successfully"})
+}
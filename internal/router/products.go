@@ -2,29 +2,206 @@ package router
 
 import (
 	"OnlineShop/internal/database"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
+	"strings"
 )
 
-type UpdateProductInput struct {
-	Name  string  `json:"name" binding:"required"`
-	Price float64 `json:"price" binding:"gte=0"`
+var (
+	errProductInOrders    = errors.New("product is referenced by orders")
+	errProductInDiscounts = errors.New("product is referenced by discounts")
+	errSKUTaken        = errors.New("sku is already used by another product")
+	errSlugTaken       = errors.New("slug is already used by another product")
+)
+
+type ProductInput struct {
+	Name        string                 `json:"name" binding:"required" example:"Зеленый чай"`
+	Description string                 `json:"description" example:"Китайский зеленый чай с жасмином"`
+	SKU         string                 `json:"sku" binding:"max=64" example:"TEA-GREEN-100"`
+	Slug        string                 `json:"slug" binding:"max=255" example:"zelenyy-chay"`
+	Price       float64                `json:"price" binding:"gte=0" example:"350"`
+	Weight      float64                `json:"weight" binding:"gte=0" example:"0.1"`
+	CategoryID  *uint                  `json:"category_id" example:"1"`
+	TaxClassID  *uint                  `json:"tax_class_id" example:"1"`
+	Status      string                 `json:"status" binding:"omitempty,oneof=draft published archived" example:"draft"`
+	Attributes  map[string]interface{} `json:"attributes" swaggertype:"object"`
+}
+
+type ProductSearchQuery struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type ProductListQuery struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=draft published archived"`
+}
+
+// applyProductInput copies the input onto the product, checking that the SKU
+// and slug are not taken by another product. An empty slug keeps the current
+// one; new products without a slug get one generated from the name on save.
+func applyProductInput(db *gorm.DB, product *database.Product, input ProductInput) error {
+	product.Name = input.Name
+	product.Description = input.Description
+	product.Price = input.Price
+	product.Weight = input.Weight
+	if input.Status != "" {
+		product.Status = input.Status
+	}
+
+	product.SKU = nil
+	if input.SKU != "" {
+		var count int64
+		err := db.Unscoped().Model(&database.Product{}).
+			Where("sku = ? AND id <> ?", input.SKU, product.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errSKUTaken
+		}
+		sku := input.SKU
+		product.SKU = &sku
+	}
+
+	if slug := database.Slugify(input.Slug); slug != "" {
+		var count int64
+		err := db.Unscoped().Model(&database.Product{}).
+			Where("slug = ? AND id <> ?", slug, product.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errSlugTaken
+		}
+		product.Slug = slug
+	}
+
+	if err := checkCategoryExists(db, input.CategoryID); err != nil {
+		return err
+	}
+	product.CategoryID = input.CategoryID
+
+	if err := checkTaxClassExists(db, input.TaxClassID); err != nil {
+		return err
+	}
+	product.TaxClassID = input.TaxClassID
+
+	attributes, err := database.NewJSON(input.Attributes)
+	if err != nil {
+		return err
+	}
+	product.Attributes = attributes
+
+	return nil
+}
+
+func respondProductInputError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errSKUTaken), errors.Is(err, errSlugTaken):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	case errors.Is(err, errCategoryNotFound), errors.Is(err, errTaxClassNotFound):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save product"})
+	}
 }
 
 // @Summary      Получить список всех товаров
-// @Description  Возвращает массив всех товаров, доступных в магазине
+// @Description  Возвращает массив всех опубликованных товаров, доступных в магазине
 // @Tags         Товары (Products)
 // @Produce      json
 // @Success      200  {array}   database.Product
 // @Router       /products [get]
 func getProducts(c *gin.Context) {
 	var products []database.Product
-	database.DB.Find(&products)
+	database.DB.Scopes(preloadImages).Where("status = ?", database.ProductStatusPublished).Find(&products)
 	c.JSON(http.StatusOK, products)
 }
 
+// @Summary      Получить товары для администрирования
+// @Description  Возвращает постраничный список товаров в любом статусе, включая черновики и архивные.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        status     query     string  false  "Статус (draft, published, archived)"
+// @Param        page       query     int     false  "Номер страницы"  default(1)
+// @Param        page_size  query     int     false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200  {object}  router.Page[database.Product]
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /products/all [get]
+func getAllProducts(c *gin.Context) {
+	var query ProductListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.Product{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch products"})
+		return
+	}
+
+	var products []database.Product
+	if err := db.Scopes(preloadImages).Order("updated_at DESC").Offset(query.offset()).Limit(query.PageSize).Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPage(products, query.PaginationQuery, total))
+}
+
+// @Summary      Поиск товаров
+// @Description  Полнотекстовый поиск по названию и описанию товара с учетом морфологии русского и английского языков.
+// @Description  Последнее слово может быть введено не полностью, что позволяет использовать поиск для автодополнения.
+// @Description  Результаты отсортированы по релевантности, фрагмент (snippet) — HTML: текст товара экранирован,
+// @Description  найденные слова выделены тегом <b>.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        q      query     string  true   "Поисковый запрос"
+// @Param        limit  query     int     false  "Максимальное число результатов"  default(20)
+// @Success      200    {array}   database.ProductSearchHit
+// @Failure      400    {object}  router.HTTPError
+// @Failure      500    {object}  router.HTTPError
+// @Router       /products/search [get]
+func searchProducts(c *gin.Context) {
+	var query ProductSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPageSize
+	}
+
+	hits, err := database.SearchProducts(database.DB, query.Query, query.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to search products"})
+		return
+	}
+	if err := attachSearchImages(database.DB, hits); err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to search products"})
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
+
 // @Summary      Получить товар по ID
-// @Description  Получает информацию о конкретном товаре по его ID
+// @Description  Получает информацию о конкретном опубликованном товаре по его ID
+// @Description  Во время распродажи Price содержит текущую цену, а CompareAtPrice — цену до скидки.
 // @Tags         Товары (Products)
 // @Produce      json
 // @Param        id   path      int  true  "ID Товара"
@@ -34,7 +211,28 @@ func getProducts(c *gin.Context) {
 func getProduct(c *gin.Context) {
 	id := c.Param("id")
 	var product database.Product
-	if err := database.DB.First(&product, id).Error; err != nil {
+	if err := database.DB.Scopes(preloadVariants, preloadImages).Where("status = ?", database.ProductStatusPublished).First(&product, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// @Summary      Получить товар по slug
+// @Description  Получает информацию об опубликованном товаре по его адресу (slug)
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        slug  path      string  true  "Slug товара"
+// @Success      200   {object}  database.Product
+// @Failure      404   {object}  router.HTTPError
+// @Router       /products/slug/{slug} [get]
+func getProductBySlug(c *gin.Context) {
+	var product database.Product
+	err := database.DB.
+		Scopes(preloadVariants, preloadImages).
+		Where("slug = ? AND status = ?", c.Param("slug"), database.ProductStatusPublished).
+		First(&product).Error
+	if err != nil {
 		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
 		return
 	}
@@ -42,36 +240,60 @@ func getProduct(c *gin.Context) {
 }
 
 // @Summary      Создать новый товар
-// @Description  Добавляет новый товар в базу данных. ID в теле запроса игнорируется.
+// @Description  Добавляет новый товар в базу данных. Если статус не указан, товар создается как черновик и не виден в каталоге.
+// @Description  Вес (weight) указывается в килограммах и используется для расчета стоимости доставки.
+// @Description  Если slug не указан, он формируется из названия.
 // @Tags         Товары (Products)
 // @Accept       json
 // @Produce      json
-// @Param        product  body      database.Product  true  "Данные для создания нового товара"
+// @Param        product  body      router.ProductInput  true  "Данные для создания нового товара"
 // @Security     BearerAuth
 // @Success      201      {object}  database.Product
 // @Failure      400      {object}  router.HTTPError
+// @Failure      409      {object}  router.HTTPError  "SKU или slug уже используется"
+// @Failure      500      {object}  router.HTTPError
 // @Router       /products [post]
 func createProduct(c *gin.Context) {
-	var product database.Product
-	if err := c.ShouldBindJSON(&product); err != nil {
+	var input ProductInput
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
 		return
 	}
-	database.DB.Create(&product)
+
+	product := database.Product{Status: database.ProductStatusDraft}
+	if err := applyProductInput(database.DB, &product, input); err != nil {
+		respondProductInputError(c, err)
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+		return database.RecordPriceChange(tx, product.ID, nil, product.Price, currentActor(c), database.PriceSourceManual)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create product"})
+		return
+	}
+	setAudit(c, "product.create", "product", product.ID, nil, product)
 	c.JSON(http.StatusCreated, product)
 }
 
 // @Summary      Обновить существующий товар
-// @Description  Полностью обновляет информацию о товаре с указанным ID
+// @Description  Полностью обновляет информацию о товаре с указанным ID. Если статус не указан, он не меняется.
+// @Description  Изменение цены записывается в историю цен.
 // @Tags         Товары (Products)
 // @Accept       json
 // @Produce      json
-// @Param        id       path      int                   true  "ID Товара для обновления"
-// @Param        product  body      router.UpdateProductInput  true  "Новые данные для товара"
+// @Param        id       path      int                  true  "ID Товара для обновления"
+// @Param        product  body      router.ProductInput  true  "Новые данные для товара"
 // @Security     BearerAuth
 // @Success      200      {object}  database.Product
 // @Failure      400      {object}  router.HTTPError
 // @Failure      404      {object}  router.HTTPError
+// @Failure      409      {object}  router.HTTPError  "SKU или slug уже используется"
+// @Failure      500      {object}  router.HTTPError
 // @Router       /products/{id} [put]
 func updateProduct(c *gin.Context) {
 	id := c.Param("id")
@@ -82,22 +304,38 @@ func updateProduct(c *gin.Context) {
 		return
 	}
 
-	var input UpdateProductInput
+	var input ProductInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
 		return
 	}
 
-	product.Name = input.Name
-	product.Price = input.Price
+	before := product
+	if err := applyProductInput(database.DB, &product, input); err != nil {
+		respondProductInputError(c, err)
+		return
+	}
 
-	database.DB.Save(&product)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+		if err := database.RecordPriceChange(tx, product.ID, &before.Price, product.Price, currentActor(c), database.PriceSourceManual); err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update product"})
+		return
+	}
+	setAudit(c, "product.update", "product", product.ID, before, product)
 
 	c.JSON(http.StatusOK, product)
 }
 
 // @Summary      Удалить товар
-// @Description  Удаляет товар из базы данных по его ID
+// @Description  Перемещает товар в корзину: он пропадает из каталога, но остается доступен в истории заказов и может быть восстановлен.
 // @Tags         Товары (Products)
 // @Produce      json
 // @Param        id   path      int  true  "ID Товара для удаления"
@@ -115,10 +353,148 @@ func deleteProduct(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Delete(&product).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, product.ID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete product"})
 		return
 	}
+	setAudit(c, "product.delete", "product", product.ID, product, nil)
 
 	c.JSON(http.StatusOK, SuccessMessage{Message: "Product deleted successfully"})
 }
+
+// @Summary      Получить корзину товаров
+// @Description  Возвращает удаленные товары, которые можно восстановить или удалить окончательно.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   database.Product
+// @Failure      403  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /products/trash [get]
+func getDeletedProducts(c *gin.Context) {
+	var products []database.Product
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch deleted products"})
+		return
+	}
+	c.JSON(http.StatusOK, products)
+}
+
+// @Summary      Восстановить товар
+// @Description  Возвращает товар из корзины в каталог.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id   path      int  true  "ID Товара"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Product
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError "Товар не найден в корзине"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /products/{id}/restore [post]
+func restoreProduct(c *gin.Context) {
+	id := c.Param("id")
+
+	var product database.Product
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&product, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Deleted product not found"})
+		return
+	}
+
+	before := product
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&product).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to restore product"})
+		return
+	}
+	product.DeletedAt = gorm.DeletedAt{}
+	setAudit(c, "product.restore", "product", product.ID, before, product)
+
+	c.JSON(http.StatusOK, product)
+}
+
+// @Summary      Удалить товар окончательно
+// @Description  Безвозвратно удаляет товар из корзины вместе с вариантами и изображениями. Запрещено, пока на товар ссылаются заказы
+// @Description  или им ограничены купоны и акции: их нужно сначала изменить, иначе они стали бы действовать на все товары.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id   path      int  true  "ID Товара"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError "Товар не найден в корзине"
+// @Failure      409  {object}  router.HTTPError "Товар присутствует в заказах, купонах или акциях"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /products/{id}/purge [delete]
+func purgeProduct(c *gin.Context) {
+	id := c.Param("id")
+
+	var product database.Product
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&product, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Deleted product not found"})
+		return
+	}
+
+	var images []database.ProductImage
+	var discounts []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var references int64
+		if err := tx.Model(&database.OrderItem{}).Where("product_id = ?", product.ID).Count(&references).Error; err != nil {
+			return err
+		}
+		if references > 0 {
+			return errProductInOrders
+		}
+		var err error
+		discounts, err = database.RestrictingDiscounts(tx, database.RestrictionProduct, product.ID)
+		if err != nil {
+			return err
+		}
+		if len(discounts) > 0 {
+			return errProductInDiscounts
+		}
+
+		if err := tx.Where("product_id = ?", product.ID).Find(&images).Error; err != nil {
+			return err
+		}
+		models := []interface{}{
+			&database.ProductImage{}, &database.ProductOption{}, &database.Variant{},
+			&database.PriceChange{}, &database.ScheduledPrice{},
+		}
+		for _, model := range models {
+			if err := tx.Unscoped().Where("product_id = ?", product.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Delete(&product).Error; err != nil {
+			return err
+		}
+		return database.EnqueueProductDeleted(tx, product)
+	})
+	if err != nil {
+		if errors.Is(err, errProductInOrders) {
+			c.JSON(http.StatusConflict, HTTPError{Message: "Product is referenced by orders and cannot be purged"})
+			return
+		}
+		if errors.Is(err, errProductInDiscounts) {
+			c.JSON(http.StatusConflict, HTTPError{Message: "Product is referenced by discounts and cannot be purged: " + strings.Join(discounts, ", ")})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to purge product"})
+		return
+	}
+	deleteStoredImages(c.Request.Context(), images...)
+	setAudit(c, "product.purge", "product", product.ID, product, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Product purged successfully"})
+}
@@ -0,0 +1,329 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+var (
+	errInvalidPromotion = errors.New("invalid promotion")
+	errPromotionApplied = errors.New("promotion has been applied to orders and cannot be deleted, deactivate it instead")
+)
+
+type PromotionTierInput struct {
+	MinSubtotal float64 `json:"min_subtotal" binding:"gte=0" example:"3000"`
+	Percent     float64 `json:"percent" binding:"gt=0,max=100" example:"10"`
+}
+
+type PromotionInput struct {
+	Name           string               `json:"name" binding:"required,max=255" example:"3 по цене 2"`
+	Description    string               `json:"description" example:"Третий чай в подарок"`
+	Type           string               `json:"type" binding:"required,oneof=buy_x_get_y tiered bundle" example:"buy_x_get_y"`
+	Priority       int                  `json:"priority" example:"10"`
+	Exclusive      bool                 `json:"exclusive" example:"false"`
+	Active         *bool                `json:"active" example:"true"`
+	StartsAt       *time.Time           `json:"starts_at" example:"2025-01-01T00:00:00Z"`
+	EndsAt         *time.Time           `json:"ends_at" example:"2025-12-31T23:59:59Z"`
+	BuyQuantity    int                  `json:"buy_quantity" binding:"gte=0" example:"2"`
+	GetQuantity    int                  `json:"get_quantity" binding:"gte=0" example:"1"`
+	Percent        float64              `json:"percent" binding:"gte=0,max=100" example:"100"`
+	Tiers          []PromotionTierInput `json:"tiers" binding:"dive"`
+	BundleQuantity int                  `json:"bundle_quantity" binding:"gte=0" example:"3"`
+	BundlePrice    float64              `json:"bundle_price" binding:"gte=0" example:"999"`
+	ProductIDs     []uint               `json:"product_ids" example:"1,2"`
+	CategoryIDs    []uint               `json:"category_ids" example:"3"`
+}
+
+type PromotionListQuery struct {
+	PaginationQuery
+	Active *bool `form:"active"`
+}
+
+// validatePromotionInput checks that the fields required by the promotion
+// type are set.
+func validatePromotionInput(input PromotionInput) error {
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", errInvalidPromotion)
+	}
+	switch input.Type {
+	case database.PromotionBuyXGetY:
+		if input.BuyQuantity < 1 || input.GetQuantity < 1 {
+			return fmt.Errorf("%w: buy_quantity and get_quantity must be at least 1", errInvalidPromotion)
+		}
+	case database.PromotionTiered:
+		if len(input.Tiers) == 0 {
+			return fmt.Errorf("%w: at least one tier is required", errInvalidPromotion)
+		}
+	case database.PromotionBundle:
+		if input.BundleQuantity < 2 {
+			return fmt.Errorf("%w: bundle_quantity must be at least 2", errInvalidPromotion)
+		}
+	}
+	return nil
+}
+
+// applyPromotionInput validates the input and copies it onto the promotion,
+// replacing its product and category restrictions.
+func applyPromotionInput(db *gorm.DB, promotion *database.Promotion, input PromotionInput) error {
+	if err := validatePromotionInput(input); err != nil {
+		return err
+	}
+	products, categories, err := loadRestrictions(db, input.ProductIDs, input.CategoryIDs)
+	if err != nil {
+		return err
+	}
+
+	promotion.Name = input.Name
+	promotion.Description = input.Description
+	promotion.Type = input.Type
+	promotion.Priority = input.Priority
+	promotion.Exclusive = input.Exclusive
+	if input.Active != nil {
+		promotion.Active = *input.Active
+	}
+	promotion.StartsAt = input.StartsAt
+	promotion.EndsAt = input.EndsAt
+
+	// Only the fields of the chosen type are kept.
+	promotion.BuyQuantity, promotion.GetQuantity, promotion.Percent = 0, 0, 0
+	promotion.Tiers = database.PromotionTiers{}
+	promotion.BundleQuantity, promotion.BundlePrice = 0, 0
+	switch input.Type {
+	case database.PromotionBuyXGetY:
+		promotion.BuyQuantity = input.BuyQuantity
+		promotion.GetQuantity = input.GetQuantity
+		promotion.Percent = input.Percent
+		if promotion.Percent == 0 {
+			promotion.Percent = 100
+		}
+	case database.PromotionTiered:
+		for _, tier := range input.Tiers {
+			promotion.Tiers = append(promotion.Tiers, database.PromotionTier{MinSubtotal: tier.MinSubtotal, Percent: tier.Percent})
+		}
+	case database.PromotionBundle:
+		promotion.BundleQuantity = input.BundleQuantity
+		promotion.BundlePrice = input.BundlePrice
+	}
+
+	promotion.Products = products
+	promotion.Categories = categories
+	return nil
+}
+
+// savePromotion stores the promotion together with its restrictions.
+func savePromotion(tx *gorm.DB, promotion *database.Promotion) error {
+	if err := tx.Omit("Products", "Categories").Save(promotion).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(promotion).Association("Products").Replace(promotion.Products); err != nil {
+		return err
+	}
+	return tx.Model(promotion).Association("Categories").Replace(promotion.Categories)
+}
+
+func respondPromotionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errInvalidPromotion), errors.Is(err, errRestrictedProducts), errors.Is(err, errRestrictedCategory):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save promotion"})
+	}
+}
+
+// @Summary      Получить список акций
+// @Description  Возвращает постраничный список акций в порядке их применения: по убыванию приоритета, затем по ID.
+// @Tags         Акции (Promotions)
+// @Produce      json
+// @Param        active     query     bool  false  "Только активные или только отключенные"
+// @Param        page       query     int   false  "Номер страницы"  default(1)
+// @Param        page_size  query     int   false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200        {object}  router.Page[database.Promotion]
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /promotions [get]
+func getPromotions(c *gin.Context) {
+	var query PromotionListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.Promotion{})
+	if query.Active != nil {
+		db = db.Where("active = ?", *query.Active)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch promotions"})
+		return
+	}
+	var promotions []database.Promotion
+	err := db.Preload("Products").Preload("Categories").
+		Order("priority DESC, id ASC").
+		Offset(query.offset()).Limit(query.PageSize).
+		Find(&promotions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch promotions"})
+		return
+	}
+	c.JSON(http.StatusOK, newPage(promotions, query.PaginationQuery, total))
+}
+
+// @Summary      Получить акцию
+// @Description  Возвращает акцию с ограничениями по товарам и категориям.
+// @Tags         Акции (Promotions)
+// @Produce      json
+// @Param        id   path      int  true  "ID акции"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Promotion
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Router       /promotions/{id} [get]
+func getPromotion(c *gin.Context) {
+	var promotion database.Promotion
+	if err := database.DB.Preload("Products").Preload("Categories").First(&promotion, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Promotion not found"})
+		return
+	}
+	c.JSON(http.StatusOK, promotion)
+}
+
+// @Summary      Создать акцию
+// @Description  Создает акцию, которая автоматически применяется при оформлении заказа. Типы акций:
+// @Description  buy_x_get_y — из каждых buy_quantity + get_quantity товаров get_quantity самых дешевых получают скидку percent (по умолчанию 100%, то есть бесплатно);
+// @Description  tiered — скидка percent наивысшего из уровней tiers, чей порог min_subtotal достигнут;
+// @Description  bundle — каждые bundle_quantity товаров (начиная с самых дорогих) продаются за bundle_price.
+// @Description  Акции применяются по убыванию priority, затем по ID, каждая к ценам после предыдущих.
+// @Description  Эксклюзивная (exclusive) акция применяется, только если до нее не сработала ни одна акция, и отменяет следующие за ней. Купон применяется после всех акций.
+// @Tags         Акции (Promotions)
+// @Accept       json
+// @Produce      json
+// @Param        promotion  body      router.PromotionInput  true  "Данные акции"
+// @Security     BearerAuth
+// @Success      201        {object}  database.Promotion
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /promotions [post]
+func createPromotion(c *gin.Context) {
+	var input PromotionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	promotion := database.Promotion{Active: true}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyPromotionInput(tx, &promotion, input); err != nil {
+			return err
+		}
+		return savePromotion(tx, &promotion)
+	})
+	if err != nil {
+		respondPromotionError(c, err)
+		return
+	}
+	setAudit(c, "promotion.create", "promotion", promotion.ID, nil, promotion)
+
+	c.JSON(http.StatusCreated, promotion)
+}
+
+// @Summary      Обновить акцию
+// @Description  Полностью заменяет условия акции. Уже оформленные заказы не пересчитываются.
+// @Tags         Акции (Promotions)
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int                    true  "ID акции"
+// @Param        promotion  body      router.PromotionInput  true  "Данные акции"
+// @Security     BearerAuth
+// @Success      200        {object}  database.Promotion
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      404        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /promotions/{id} [put]
+func updatePromotion(c *gin.Context) {
+	var promotion database.Promotion
+	if err := database.DB.Preload("Products").Preload("Categories").First(&promotion, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Promotion not found"})
+		return
+	}
+
+	var input PromotionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := promotion
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyPromotionInput(tx, &promotion, input); err != nil {
+			return err
+		}
+		return savePromotion(tx, &promotion)
+	})
+	if err != nil {
+		respondPromotionError(c, err)
+		return
+	}
+	setAudit(c, "promotion.update", "promotion", promotion.ID, before, promotion)
+
+	c.JSON(http.StatusOK, promotion)
+}
+
+// @Summary      Удалить акцию
+// @Description  Удаляет акцию, которая еще не применялась к заказам. Примененные акции можно только отключить.
+// @Tags         Акции (Promotions)
+// @Produce      json
+// @Param        id   path      int  true  "ID акции"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      409  {object}  router.HTTPError  "Акция уже применялась к заказам"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /promotions/{id} [delete]
+func deletePromotion(c *gin.Context) {
+	var promotion database.Promotion
+	if err := database.DB.First(&promotion, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Promotion not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var applied int64
+		if err := tx.Model(&database.OrderAdjustment{}).Where("promotion_id = ?", promotion.ID).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			return errPromotionApplied
+		}
+		if err := tx.Model(&promotion).Association("Products").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(&promotion).Association("Categories").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&promotion).Error
+	})
+	if err != nil {
+		if errors.Is(err, errPromotionApplied) {
+			c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete promotion"})
+		return
+	}
+	setAudit(c, "promotion.delete", "promotion", promotion.ID, promotion, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Promotion deleted successfully"})
+}
@@ -0,0 +1,170 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/payment"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+var errRefundProvider = errors.New("order was paid through another payment provider")
+
+type RefundItemInput struct {
+	OrderItemID uint     `json:"order_item_id" binding:"required" example:"1"`
+	Quantity    int      `json:"quantity" binding:"gte=0" example:"1"`
+	Amount      *float64 `json:"amount" binding:"omitempty,gte=0" example:"150"`
+}
+
+type RefundInput struct {
+	Items          []RefundItemInput `json:"items" binding:"dive"`
+	ShippingAmount float64           `json:"shipping_amount" binding:"gte=0" example:"0"`
+	Reason         string            `json:"reason" binding:"max=1000" example:"Товар поврежден при доставке"`
+}
+
+// issueRefund refunds the lines of the order through the provider that took
+// the payment, linking the refund to the return request if one is given.
+// The refund is committed as pending before the provider is asked to pay it
+// out, and completed afterwards, so money given back is always on record.
+func issueRefund(ctx context.Context, orderID uint, lines []database.RefundLine, shippingAmount float64, reason string, returnRequestID, actorID *uint) (database.Refund, error) {
+	var refund database.Refund
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// The order stays locked until the transaction ends, so refunds of
+		// the same order are planned one at a time.
+		var order database.Order
+		if err := database.LockOrder(tx, &order, orderID); err != nil {
+			return err
+		}
+		if err := database.CheckNoPendingRefund(tx, order.ID); err != nil {
+			return err
+		}
+		if returnRequestID != nil {
+			var request database.ReturnRequest
+			if err := tx.First(&request, *returnRequestID).Error; err != nil {
+				return err
+			}
+			if request.Status != database.ReturnStatusReceived {
+				return database.ErrReturnStatus
+			}
+		}
+		paid, err := database.SucceededPayment(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if paid.Provider != paymentProvider.Name() || paid.ExternalID == nil {
+			return errRefundProvider
+		}
+
+		refund, err = database.PlanRefund(order, lines, shippingAmount)
+		if err != nil {
+			return err
+		}
+		refund.PaymentID = paid.ID
+		refund.ReturnRequestID = returnRequestID
+		refund.Reason = reason
+		refund.ActorID = actorID
+		return database.SaveRefund(tx, &refund)
+	})
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	return refund, payment.SendRefund(ctx, database.DB, paymentProvider, &refund)
+}
+
+func respondRefundError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+	case errors.Is(err, database.ErrRefundItemNotFound), errors.Is(err, database.ErrRefundQuantity),
+		errors.Is(err, database.ErrRefundAmount), errors.Is(err, database.ErrRefundEmpty):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	case errors.Is(err, database.ErrOrderNotPaid), errors.Is(err, errRefundProvider), errors.Is(err, database.ErrRefundInProgress):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	case errors.Is(err, payment.ErrRefundFailed):
+		c.JSON(http.StatusBadGateway, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to refund order"})
+	}
+}
+
+// @Summary      Вернуть деньги за заказ
+// @Description  Возвращает деньги за позиции заказа и/или доставку через платежного провайдера, которым заказ был оплачен.
+// @Description  Для позиции указывается количество единиц; без amount возвращается уплаченная за них сумма (с учетом скидок и налога),
+// @Description  с amount — указанная сумма (частичный возврат, например компенсация), при этом quantity может быть 0.
+// @Description  Нельзя вернуть больше, чем было уплачено и еще не возвращено. Возвращенные суммы отражаются в RefundedTotal заказа
+// @Description  и RefundedQuantity/RefundedAmount позиций; при полном возврате заказ переходит в статус Refunded. Если после возврата
+// @Description  все оставшиеся единицы уже отправлены или доставлены, заказ переходит в статус Shipped или Delivered.
+// @Description  Повтор запроса с тем же заголовком Idempotency-Key не возвращает деньги повторно, а возвращает первый ответ.
+// @Description  Возврат сохраняется (статус pending) до обращения к провайдеру и завершается (completed) после выплаты; если магазин
+// @Description  не успел записать выплату, возврат повторно отправляется провайдеру в фоне без двойной выплаты. Пока возврат заказа
+// @Description  обрабатывается, новый возврат по этому заказу не создается.
+// @Tags         Возвраты (Returns)
+// @Accept       json
+// @Produce      json
+// @Param        id               path      int                 true   "ID заказа"
+// @Param        Idempotency-Key  header    string              false  "Уникальный ключ запроса для безопасных повторов"
+// @Param        refund           body      router.RefundInput  true   "Позиции и суммы возврата"
+// @Security     BearerAuth
+// @Success      201              {object}  database.Refund
+// @Failure      400              {object}  router.HTTPError  "Ошибка валидации, некорректный ID или сумма превышает доступную к возврату"
+// @Failure      403              {object}  router.HTTPError
+// @Failure      404              {object}  router.HTTPError  "Заказ не найден"
+// @Failure      409              {object}  router.HTTPError  "Заказ не оплачен, по нему уже выполняется возврат или запрос с тем же Idempotency-Key еще выполняется"
+// @Failure      422              {object}  router.HTTPError  "Idempotency-Key уже использован с другим запросом"
+// @Failure      502              {object}  router.HTTPError  "Платежный провайдер не выполнил возврат"
+// @Failure      500              {object}  router.HTTPError
+// @Router       /orders/{id}/refunds [post]
+func createRefund(c *gin.Context) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid order ID"})
+		return
+	}
+
+	var input RefundInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	lines := make([]database.RefundLine, len(input.Items))
+	for i, item := range input.Items {
+		lines[i] = database.RefundLine{OrderItemID: item.OrderItemID, Quantity: item.Quantity, Amount: item.Amount}
+	}
+
+	refund, err := issueRefund(c.Request.Context(), uint(orderID), lines, input.ShippingAmount, input.Reason, nil, currentActor(c))
+	if err != nil {
+		respondRefundError(c, err)
+		return
+	}
+	setAudit(c, "order.refund", "order", refund.OrderID, nil, refund)
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+// @Summary      Получить возвраты денег по заказу
+// @Description  Возвращает все возвраты денег по заказу с позициями.
+// @Tags         Возвраты (Returns)
+// @Produce      json
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {array}   database.Refund
+// @Failure      403  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /orders/{id}/refunds [get]
+func getRefunds(c *gin.Context) {
+	var refunds []database.Refund
+	err := database.DB.Preload("Items").
+		Where("order_id = ?", c.Param("id")).
+		Order("created_at ASC, id ASC").
+		Find(&refunds).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch refunds"})
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
@@ -0,0 +1,359 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+var errOrderNotDelivered = errors.New("only delivered orders can be returned")
+
+type ReturnItemInput struct {
+	OrderItemID uint `json:"order_item_id" binding:"required" example:"1"`
+	Quantity    int  `json:"quantity" binding:"required,gt=0" example:"1"`
+}
+
+type CreateReturnInput struct {
+	Items   []ReturnItemInput `json:"items" binding:"required,min=1,dive"`
+	Reason  string            `json:"reason" binding:"required,oneof=defective wrong_item not_as_described changed_mind other" example:"defective"`
+	Comment string            `json:"comment" binding:"max=2000" example:"Кружка пришла с трещиной"`
+}
+
+type ReturnDecisionInput struct {
+	Note string `json:"note" binding:"max=2000" example:"Возврат одобрен, отправьте товар по адресу склада"`
+}
+
+type RefundReturnInput struct {
+	ShippingAmount float64 `json:"shipping_amount" binding:"gte=0" example:"0"`
+}
+
+type ReturnListQuery struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=requested approved rejected received refunded"`
+}
+
+func respondReturnError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Return request not found"})
+	case errors.Is(err, database.ErrReturnItemNotFound), errors.Is(err, database.ErrReturnQuantity):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	case errors.Is(err, database.ErrReturnStatus), errors.Is(err, errOrderNotDelivered):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	default:
+		respondRefundError(c, err)
+	}
+}
+
+// @Summary      Оформить возврат товара
+// @Description  Создает заявку на возврат позиций доставленного заказа (статус Delivered) с указанием причины.
+// @Description  Нельзя вернуть больше единиц, чем было в заказе, с учетом других заявок, кроме отклоненных.
+// @Description  Заявка рассматривается магазином; после получения товара деньги возвращаются через платежного провайдера.
+// @Tags         Возвраты (Returns)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                       true  "ID заказа"
+// @Param        request  body      router.CreateReturnInput  true  "Позиции и причина возврата"
+// @Security     BearerAuth
+// @Success      201      {object}  database.ReturnRequest
+// @Failure      400      {object}  router.HTTPError  "Ошибка валидации или количество превышает доступное к возврату"
+// @Failure      401      {object}  router.HTTPError
+// @Failure      404      {object}  router.HTTPError  "Заказ не найден"
+// @Failure      409      {object}  router.HTTPError  "Заказ еще не доставлен"
+// @Failure      500      {object}  router.HTTPError
+// @Router       /orders/{id}/returns [post]
+func createReturnRequest(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var input CreateReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	request := database.ReturnRequest{
+		CustomerID: userID.(uint),
+		Status:     database.ReturnStatusRequested,
+		Reason:     input.Reason,
+		Comment:    input.Comment,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var order database.Order
+		if err := database.LockOrder(tx.Where("customer_id = ?", request.CustomerID), &order, c.Param("id")); err != nil {
+			return err
+		}
+		if order.Status != database.OrderStatusDelivered {
+			return errOrderNotDelivered
+		}
+
+		lines := make([]database.ReturnLine, len(input.Items))
+		for i, item := range input.Items {
+			lines[i] = database.ReturnLine{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+		}
+		items, err := database.PlanReturn(tx, order, lines)
+		if err != nil {
+			return err
+		}
+
+		request.OrderID = order.ID
+		request.Items = items
+		return tx.Create(&request).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+		return
+	}
+	if err != nil {
+		respondReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// @Summary      Получить заявки на возврат по заказу
+// @Description  Возвращает заявки на возврат по заказу аутентифицированного пользователя.
+// @Tags         Возвраты (Returns)
+// @Produce      json
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {array}   database.ReturnRequest
+// @Failure      401  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /orders/{id}/returns [get]
+func getOrderReturnRequests(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var requests []database.ReturnRequest
+	err := database.DB.Preload("Items").
+		Where("order_id = ? AND customer_id = ?", c.Param("id"), userID).
+		Order("created_at ASC, id ASC").
+		Find(&requests).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch return requests"})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// @Summary      Получить заявки на возврат
+// @Description  Возвращает постраничный список заявок на возврат всех покупателей, новые первыми, с фильтром по статусу.
+// @Tags         Возвраты (Returns)
+// @Produce      json
+// @Param        status     query     string  false  "Статус заявки"  Enums(requested, approved, rejected, received, refunded)
+// @Param        page       query     int     false  "Номер страницы"  default(1)
+// @Param        page_size  query     int     false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200        {object}  router.Page[database.ReturnRequest]
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /returns [get]
+func getReturnRequests(c *gin.Context) {
+	var query ReturnListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.ReturnRequest{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch return requests"})
+		return
+	}
+	var requests []database.ReturnRequest
+	err := db.Preload("Items").
+		Order("created_at DESC, id DESC").
+		Offset(query.offset()).Limit(query.PageSize).
+		Find(&requests).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch return requests"})
+		return
+	}
+	c.JSON(http.StatusOK, newPage(requests, query.PaginationQuery, total))
+}
+
+// @Summary      Получить заявку на возврат
+// @Description  Возвращает заявку на возврат с позициями.
+// @Tags         Возвраты (Returns)
+// @Produce      json
+// @Param        id   path      int  true  "ID заявки"
+// @Security     BearerAuth
+// @Success      200  {object}  database.ReturnRequest
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Router       /returns/{id} [get]
+func getReturnRequest(c *gin.Context) {
+	var request database.ReturnRequest
+	if err := database.DB.Preload("Items").First(&request, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Return request not found"})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// decideReturnRequest moves the request from requested to the status,
+// recording the note for the customer.
+func decideReturnRequest(c *gin.Context, status string) {
+	var input ReturnDecisionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var request database.ReturnRequest
+	if err := database.DB.Preload("Items").First(&request, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Return request not found"})
+		return
+	}
+
+	before := request
+	updates := map[string]interface{}{"resolution_note": input.Note}
+	if err := database.TransitionReturn(database.DB, &request, status, updates, database.ReturnStatusRequested); err != nil {
+		respondReturnError(c, err)
+		return
+	}
+	setAudit(c, "return."+status, "return_request", request.ID, before, request)
+
+	c.JSON(http.StatusOK, request)
+}
+
+// @Summary      Одобрить заявку на возврат
+// @Description  Одобряет заявку в статусе requested. В note можно указать инструкции для покупателя.
+// @Tags         Возвраты (Returns)
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                         true  "ID заявки"
+// @Param        decision  body      router.ReturnDecisionInput  true  "Комментарий магазина"
+// @Security     BearerAuth
+// @Success      200       {object}  database.ReturnRequest
+// @Failure      400       {object}  router.HTTPError
+// @Failure      403       {object}  router.HTTPError
+// @Failure      404       {object}  router.HTTPError
+// @Failure      409       {object}  router.HTTPError  "Заявка уже рассмотрена"
+// @Failure      500       {object}  router.HTTPError
+// @Router       /returns/{id}/approve [post]
+func approveReturnRequest(c *gin.Context) {
+	decideReturnRequest(c, database.ReturnStatusApproved)
+}
+
+// @Summary      Отклонить заявку на возврат
+// @Description  Отклоняет заявку в статусе requested. Позиции отклоненной заявки можно вернуть новой заявкой.
+// @Tags         Возвраты (Returns)
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                         true  "ID заявки"
+// @Param        decision  body      router.ReturnDecisionInput  true  "Причина отказа"
+// @Security     BearerAuth
+// @Success      200       {object}  database.ReturnRequest
+// @Failure      400       {object}  router.HTTPError
+// @Failure      403       {object}  router.HTTPError
+// @Failure      404       {object}  router.HTTPError
+// @Failure      409       {object}  router.HTTPError  "Заявка уже рассмотрена"
+// @Failure      500       {object}  router.HTTPError
+// @Router       /returns/{id}/reject [post]
+func rejectReturnRequest(c *gin.Context) {
+	decideReturnRequest(c, database.ReturnStatusRejected)
+}
+
+// @Summary      Принять возвращенный товар
+// @Description  Отмечает, что товар по одобренной заявке получен, и возвращает единицы вариантов товаров на склад.
+// @Tags         Возвраты (Returns)
+// @Produce      json
+// @Param        id   path      int  true  "ID заявки"
+// @Security     BearerAuth
+// @Success      200  {object}  database.ReturnRequest
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      409  {object}  router.HTTPError  "Заявка не одобрена или товар уже получен"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /returns/{id}/receive [post]
+func receiveReturnRequest(c *gin.Context) {
+	var request database.ReturnRequest
+	if err := database.DB.Preload("Items").First(&request, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Return request not found"})
+		return
+	}
+
+	before := request
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"received_at": time.Now()}
+		if err := database.TransitionReturn(tx, &request, database.ReturnStatusReceived, updates, database.ReturnStatusApproved); err != nil {
+			return err
+		}
+		return database.RestockReturn(tx, request)
+	})
+	if err != nil {
+		respondReturnError(c, err)
+		return
+	}
+	setAudit(c, "return.received", "return_request", request.ID, before, request)
+
+	c.JSON(http.StatusOK, request)
+}
+
+// @Summary      Вернуть деньги по заявке на возврат
+// @Description  Возвращает деньги за полученные по заявке позиции (уплаченную за них сумму) и, если указано, за доставку.
+// @Description  Доступно для заявок в статусе received; заявка переходит в статус refunded.
+// @Tags         Возвраты (Returns)
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                       true  "ID заявки"
+// @Param        refund  body      router.RefundReturnInput  true  "Сумма возврата за доставку"
+// @Security     BearerAuth
+// @Success      200     {object}  database.ReturnRequest
+// @Failure      400     {object}  router.HTTPError  "Ошибка валидации или сумма превышает доступную к возврату"
+// @Failure      403     {object}  router.HTTPError
+// @Failure      404     {object}  router.HTTPError
+// @Failure      409     {object}  router.HTTPError  "Товар по заявке не получен, заказ не оплачен или по нему уже выполняется возврат"
+// @Failure      502     {object}  router.HTTPError  "Платежный провайдер не выполнил возврат"
+// @Failure      500     {object}  router.HTTPError
+// @Router       /returns/{id}/refund [post]
+func refundReturnRequest(c *gin.Context) {
+	var input RefundReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var request database.ReturnRequest
+	if err := database.DB.Preload("Items").First(&request, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Return request not found"})
+		return
+	}
+	before := request
+	lines := make([]database.RefundLine, len(request.Items))
+	for i, item := range request.Items {
+		lines[i] = database.RefundLine{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+	}
+	reason := fmt.Sprintf("Return #%d", request.ID)
+	if _, err := issueRefund(c.Request.Context(), request.OrderID, lines, input.ShippingAmount, reason, &request.ID, currentActor(c)); err != nil {
+		respondReturnError(c, err)
+		return
+	}
+	if err := database.DB.Preload("Items").First(&request, request.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch return request"})
+		return
+	}
+	setAudit(c, "return.refunded", "return_request", request.ID, before, request)
+
+	c.JSON(http.StatusOK, request)
+}
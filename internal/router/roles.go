@@ -0,0 +1,188 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type AssignRoleInput struct {
+	Role string `json:"role" binding:"required" example:"catalog_manager"`
+}
+
+// @Summary      Получить список ролей
+// @Description  Возвращает все роли сотрудников вместе с их разрешениями.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   database.Role     "Список ролей"
+// @Failure      403  {object}  router.HTTPError  "Недостаточно прав"
+// @Failure      500  {object}  router.HTTPError  "Внутренняя ошибка сервера"
+// @Router       /roles [get]
+func getRoles(c *gin.Context) {
+	var roles []database.Role
+	if err := database.DB.Preload("Permissions").Order("id ASC").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// @Summary      Получить роли пользователя
+// @Description  Возвращает роли, назначенные пользователю.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Param        id   path      int  true  "ID пользователя"
+// @Security     BearerAuth
+// @Success      200  {array}   database.Role     "Роли пользователя"
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID пользователя"
+// @Failure      403  {object}  router.HTTPError  "Недостаточно прав"
+// @Failure      404  {object}  router.HTTPError  "Пользователь не найден"
+// @Router       /users/{id}/roles [get]
+func getUserRoles(c *gin.Context) {
+	targetUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid user ID"})
+		return
+	}
+
+	var user database.Customer
+	if err := database.DB.Preload("Roles.Permissions").First(&user, targetUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user.Roles)
+}
+
+// @Summary      Назначить роль пользователю
+// @Description  Назначает пользователю роль сотрудника (admin, catalog_manager, order_fulfiller, support).
+// @Tags         Администрирование (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                     true  "ID пользователя"
+// @Param        input  body      router.AssignRoleInput  true  "Назначаемая роль"
+// @Security     BearerAuth
+// @Success      200    {object}  router.SuccessMessage "Роль назначена"
+// @Failure      400    {object}  router.HTTPError      "Некорректные входные данные"
+// @Failure      403    {object}  router.HTTPError      "Недостаточно прав"
+// @Failure      404    {object}  router.HTTPError      "Пользователь или роль не найдены"
+// @Failure      409    {object}  router.HTTPError      "Роль уже назначена"
+// @Failure      500    {object}  router.HTTPError      "Внутренняя ошибка сервера"
+// @Router       /users/{id}/roles [post]
+func assignUserRole(c *gin.Context) {
+	targetUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid user ID"})
+		return
+	}
+
+	var input AssignRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var user database.Customer
+	if err := database.DB.First(&user, targetUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "User not found"})
+		return
+	}
+
+	hasRole, err := database.CustomerHasRole(database.DB, user.ID, input.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to assign role"})
+		return
+	}
+	if hasRole {
+		c.JSON(http.StatusConflict, HTTPError{Message: "User already has this role"})
+		return
+	}
+
+	if err := database.AssignRole(database.DB, user.ID, input.Role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, HTTPError{Message: "Role not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to assign role"})
+		return
+	}
+	setAudit(c, "role.assign", "customer", user.ID, nil, gin.H{"role": input.Role})
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Role assigned successfully"})
+}
+
+// @Summary      Отозвать роль у пользователя
+// @Description  Отзывает роль сотрудника. Отзыв роли admin понижает администратора до обычного пользователя.
+// @Description  Нельзя отозвать роль admin у самого себя и у последнего администратора.
+// @Tags         Администрирование (Admin)
+// @Produce      json
+// @Param        id    path      int     true  "ID пользователя"
+// @Param        role  path      string  true  "Название роли"
+// @Security     BearerAuth
+// @Success      200   {object}  router.SuccessMessage "Роль отозвана"
+// @Failure      400   {object}  router.HTTPError      "Некорректный ID пользователя"
+// @Failure      403   {object}  router.HTTPError      "Недостаточно прав или попытка понизить самого себя"
+// @Failure      404   {object}  router.HTTPError      "Пользователь не найден или роль не назначена"
+// @Failure      409   {object}  router.HTTPError      "Нельзя понизить последнего администратора"
+// @Failure      500   {object}  router.HTTPError      "Внутренняя ошибка сервера"
+// @Router       /users/{id}/roles/{role} [delete]
+func revokeUserRole(c *gin.Context) {
+	targetUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid user ID"})
+		return
+	}
+	roleName := c.Param("role")
+
+	var user database.Customer
+	if err := database.DB.First(&user, targetUserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "User not found"})
+		return
+	}
+
+	var role database.Role
+	if err := database.DB.Where("name = ?", roleName).First(&role).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Role not found"})
+		return
+	}
+
+	hasRole, err := database.CustomerHasRole(database.DB, user.ID, role.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to revoke role"})
+		return
+	}
+	if !hasRole {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "User does not have this role"})
+		return
+	}
+
+	if role.Name == database.RoleAdmin {
+		if currentUserID, _ := c.Get("userID"); currentUserID == user.ID {
+			c.JSON(http.StatusForbidden, HTTPError{Message: "You cannot demote yourself"})
+			return
+		}
+
+		admins, err := database.CountRoleMembers(database.DB, database.RoleAdmin)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to revoke role"})
+			return
+		}
+		if admins <= 1 {
+			c.JSON(http.StatusConflict, HTTPError{Message: "Cannot demote the last admin"})
+			return
+		}
+	}
+
+	if err := database.DB.Model(&user).Association("Roles").Delete(&role); err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to revoke role"})
+		return
+	}
+	setAudit(c, "role.revoke", "customer", user.ID, gin.H{"role": role.Name}, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Role revoked successfully"})
+}
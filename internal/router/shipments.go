@@ -0,0 +1,359 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"OnlineShop/internal/packingslip"
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ShipmentItemInput struct {
+	OrderItemID uint `json:"order_item_id" binding:"required" example:"1"`
+	Quantity    int  `json:"quantity" binding:"required,gt=0" example:"1"`
+}
+
+type CreateShipmentInput struct {
+	Items          []ShipmentItemInput `json:"items" binding:"dive"`
+	Carrier        string              `json:"carrier" binding:"max=100" example:"СДЭК"`
+	TrackingNumber string              `json:"tracking_number" binding:"max=100" example:"1234567890"`
+	TrackingURL    string              `json:"tracking_url" binding:"omitempty,url" example:"https://www.cdek.ru/ru/tracking?order_id=1234567890"`
+}
+
+type ShipShipmentInput struct {
+	Carrier        string `json:"carrier" binding:"max=100" example:"СДЭК"`
+	TrackingNumber string `json:"tracking_number" binding:"max=100" example:"1234567890"`
+	TrackingURL    string `json:"tracking_url" binding:"omitempty,url" example:"https://www.cdek.ru/ru/tracking?order_id=1234567890"`
+}
+
+// shopName is the name of the shop printed on packing slips, set from the
+// configuration.
+var shopName string
+
+func respondShipmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipment not found"})
+	case errors.Is(err, database.ErrShipmentItemNotFound), errors.Is(err, database.ErrShipmentQuantity):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	case errors.Is(err, database.ErrShipmentEmpty), errors.Is(err, database.ErrShipmentStatus),
+		errors.Is(err, database.ErrOrderNotShippable):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update shipment"})
+	}
+}
+
+// findShipment loads the shipment in the path with its items, writing the
+// error response if there is none.
+func findShipment(c *gin.Context) (database.Shipment, bool) {
+	var shipment database.Shipment
+	shipmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid shipment ID"})
+		return shipment, false
+	}
+	if err := database.DB.Preload("Items").First(&shipment, shipmentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipment not found"})
+		return shipment, false
+	}
+	return shipment, true
+}
+
+// @Summary      Создать отправление
+// @Description  Создает отправление (посылку) с позициями оплаченного заказа. Заказ можно разбить на несколько отправлений;
+// @Description  без items в отправление попадают все еще не отправленные единицы. Возвращенные покупателю единицы не отправляются.
+// @Description  Отправление создается в статусе pending — для него можно распечатать упаковочный лист — и отправляется через POST /shipments/{id}/ship.
+// @Tags         Доставка (Fulfilment)
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                         true  "ID заказа"
+// @Param        shipment  body      router.CreateShipmentInput  true  "Позиции, перевозчик и трек-номер"
+// @Security     BearerAuth
+// @Success      201       {object}  database.Shipment
+// @Failure      400       {object}  router.HTTPError  "Ошибка валидации или количество превышает не отправленное"
+// @Failure      403       {object}  router.HTTPError
+// @Failure      404       {object}  router.HTTPError  "Заказ не найден"
+// @Failure      409       {object}  router.HTTPError  "Заказ не оплачен или уже отправлен полностью"
+// @Failure      500       {object}  router.HTTPError
+// @Router       /orders/{id}/shipments [post]
+func createShipment(c *gin.Context) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid order ID"})
+		return
+	}
+
+	var input CreateShipmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	shipment := database.Shipment{
+		Status:         database.ShipmentStatusPending,
+		Carrier:        input.Carrier,
+		TrackingNumber: input.TrackingNumber,
+		TrackingURL:    input.TrackingURL,
+		ActorID:        currentActor(c),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var order database.Order
+		if err := database.LockOrder(tx, &order, orderID); err != nil {
+			return err
+		}
+
+		lines := make([]database.ShipmentLine, len(input.Items))
+		for i, item := range input.Items {
+			lines[i] = database.ShipmentLine{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+		}
+		items, err := database.PlanShipment(tx, order, lines)
+		if err != nil {
+			return err
+		}
+
+		shipment.OrderID = order.ID
+		shipment.Items = items
+		return tx.Create(&shipment).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+		return
+	}
+	if err != nil {
+		respondShipmentError(c, err)
+		return
+	}
+	setAudit(c, "shipment.create", "shipment", shipment.ID, nil, shipment)
+
+	c.JSON(http.StatusCreated, shipment)
+}
+
+// @Summary      Отправить отправление
+// @Description  Отмечает, что отправление передано перевозчику. Перевозчик и трек-номер можно указать здесь, если они не были
+// @Description  указаны при создании; пустые поля оставляют прежние значения.
+// @Description  Когда отправлены все единицы заказа, заказ переходит в статус Shipped. Покупателю отправляется письмо
+// @Description  с составом отправления и трек-номером.
+// @Tags         Доставка (Fulfilment)
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                       true  "ID отправления"
+// @Param        shipment  body      router.ShipShipmentInput  true  "Перевозчик и трек-номер"
+// @Security     BearerAuth
+// @Success      200       {object}  database.Shipment
+// @Failure      400       {object}  router.HTTPError
+// @Failure      403       {object}  router.HTTPError
+// @Failure      404       {object}  router.HTTPError  "Отправление не найдено"
+// @Failure      409       {object}  router.HTTPError  "Отправление уже отправлено"
+// @Failure      500       {object}  router.HTTPError
+// @Router       /shipments/{id}/ship [post]
+func shipShipment(c *gin.Context) {
+	var input ShipShipmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	shipment, ok := findShipment(c)
+	if !ok {
+		return
+	}
+
+	before := shipment
+	updates := map[string]interface{}{"shipped_at": time.Now()}
+	if input.Carrier != "" {
+		updates["carrier"] = input.Carrier
+	}
+	if input.TrackingNumber != "" {
+		updates["tracking_number"] = input.TrackingNumber
+	}
+	if input.TrackingURL != "" {
+		updates["tracking_url"] = input.TrackingURL
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := database.TransitionShipment(tx, &shipment, database.ShipmentStatusShipped, updates, database.ShipmentStatusPending)
+		if err != nil {
+			return err
+		}
+		if err := database.UpdateFulfilmentStatus(tx, shipment.OrderID, currentActor(c)); err != nil {
+			return err
+		}
+		return database.EnqueueOrderNotification(tx, database.NotificationOrderShipped, shipment.OrderID, &shipment.ID)
+	})
+	if err != nil {
+		respondShipmentError(c, err)
+		return
+	}
+	setAudit(c, "shipment.shipped", "shipment", shipment.ID, before, shipment)
+
+	c.JSON(http.StatusOK, shipment)
+}
+
+// @Summary      Отметить отправление доставленным
+// @Description  Отмечает, что покупатель получил отправление. Когда доставлены все отправления полностью отправленного заказа,
+// @Description  заказ переходит в статус Delivered, после чего по нему можно оформить возврат.
+// @Tags         Доставка (Fulfilment)
+// @Produce      json
+// @Param        id   path      int  true  "ID отправления"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Shipment
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Отправление не найдено"
+// @Failure      409  {object}  router.HTTPError  "Отправление еще не отправлено или уже доставлено"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipments/{id}/deliver [post]
+func deliverShipment(c *gin.Context) {
+	shipment, ok := findShipment(c)
+	if !ok {
+		return
+	}
+
+	before := shipment
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"delivered_at": time.Now()}
+		err := database.TransitionShipment(tx, &shipment, database.ShipmentStatusDelivered, updates, database.ShipmentStatusShipped)
+		if err != nil {
+			return err
+		}
+		return database.UpdateFulfilmentStatus(tx, shipment.OrderID, currentActor(c))
+	})
+	if err != nil {
+		respondShipmentError(c, err)
+		return
+	}
+	setAudit(c, "shipment.delivered", "shipment", shipment.ID, before, shipment)
+
+	c.JSON(http.StatusOK, shipment)
+}
+
+// @Summary      Отменить отправление
+// @Description  Удаляет еще не отправленное отправление; его единицы снова можно включить в другое отправление.
+// @Tags         Доставка (Fulfilment)
+// @Produce      json
+// @Param        id   path      int  true  "ID отправления"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Отправление не найдено"
+// @Failure      409  {object}  router.HTTPError  "Отправление уже отправлено"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipments/{id} [delete]
+func deleteShipment(c *gin.Context) {
+	shipment, ok := findShipment(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND status = ?", shipment.ID, database.ShipmentStatusPending).Delete(&database.Shipment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return database.ErrShipmentStatus
+		}
+		return tx.Where("shipment_id = ?", shipment.ID).Delete(&database.ShipmentItem{}).Error
+	})
+	if err != nil {
+		respondShipmentError(c, err)
+		return
+	}
+	setAudit(c, "shipment.delete", "shipment", shipment.ID, shipment, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Shipment deleted successfully"})
+}
+
+// @Summary      Получить упаковочный лист
+// @Description  Возвращает HTML-страницу для печати со списком позиций отправления и адресом получателя, без цен.
+// @Tags         Доставка (Fulfilment)
+// @Produce      html
+// @Param        id   path      int  true  "ID отправления"
+// @Security     BearerAuth
+// @Success      200  {string}  string  "HTML-страница упаковочного листа"
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Отправление не найдено"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipments/{id}/packing-slip [get]
+func getPackingSlip(c *gin.Context) {
+	shipment, ok := findShipment(c)
+	if !ok {
+		return
+	}
+
+	var order database.Order
+	err := database.DB.
+		Scopes(preloadOrderItems).
+		Preload("Items.Product.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		First(&order, shipment.OrderID).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch order"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := packingslip.RenderHTML(&buf, shopName, order, shipment); err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to render packing slip"})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// @Summary      Отследить заказ
+// @Description  Возвращает отправления заказа с позициями, статусами, перевозчиком и трек-номером для отслеживания посылок.
+// @Description  Покупателю доступны только его собственные заказы, сотрудникам с правом orders.view — любые.
+// @Tags         Доставка (Fulfilment)
+// @Produce      json
+// @Param        id   path      int  true  "ID заказа"
+// @Security     BearerAuth
+// @Success      200  {array}   database.Shipment
+// @Failure      400  {object}  router.HTTPError  "Некорректный ID заказа"
+// @Failure      401  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Заказ не найден"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /orders/{id}/shipments [get]
+func getOrderShipments(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid order ID"})
+		return
+	}
+
+	viewable, err := viewableOrders(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to check permissions"})
+		return
+	}
+
+	var order database.Order
+	err = database.DB.Scopes(viewable).
+		Preload("Shipments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Shipments.Items").
+		First(&order, orderID).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Order not found"})
+		return
+	}
+
+	shipments := order.Shipments
+	if shipments == nil {
+		shipments = []database.Shipment{}
+	}
+	c.JSON(http.StatusOK, shipments)
+}
@@ -0,0 +1,596 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	errShippingMethodNotFound = errors.New("shipping method not found")
+	errShippingZoneNotFound   = errors.New("shipping zone not found")
+	errShippingZoneNameTaken  = errors.New("shipping zone name is already used")
+	errShippingLocationTaken  = errors.New("location already belongs to another shipping zone")
+	errShippingWeightRange    = errors.New("max_weight must be greater than min_weight")
+)
+
+type ShippingMethodInput struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Курьер"`
+	Type        string `json:"type" binding:"required,oneof=courier pickup post" example:"courier"`
+	Description string `json:"description" example:"Доставка до двери"`
+	Active      *bool  `json:"active" example:"true"`
+	Position    int    `json:"position" example:"0"`
+}
+
+type ShippingLocationInput struct {
+	Country string `json:"country" binding:"required,len=2" example:"RU"`
+	Region  string `json:"region" binding:"max=255" example:"Москва"`
+}
+
+type ShippingZoneInput struct {
+	Name      string                  `json:"name" binding:"required,max=100" example:"Москва"`
+	Locations []ShippingLocationInput `json:"locations" binding:"required,min=1,dive"`
+}
+
+type ShippingRateInput struct {
+	MethodID   uint     `json:"method_id" binding:"required" example:"1"`
+	ZoneID     uint     `json:"zone_id" binding:"required" example:"1"`
+	MinWeight  float64  `json:"min_weight" binding:"gte=0" example:"0"`
+	MaxWeight  *float64 `json:"max_weight" binding:"omitempty,gt=0" example:"10"`
+	Price      float64  `json:"price" binding:"gte=0" example:"300"`
+	PricePerKg float64  `json:"price_per_kg" binding:"gte=0" example:"50"`
+	FreeFrom   *float64 `json:"free_from" binding:"omitempty,gte=0" example:"5000"`
+}
+
+type ShippingRateQuery struct {
+	MethodID uint `form:"method_id" example:"1"`
+	ZoneID   uint `form:"zone_id" example:"1"`
+}
+
+type ShippingQuoteInput struct {
+	Items             []CreateOrderItemInput `json:"items" binding:"required,min=1,dive"`
+	ShippingAddressID *uint                  `json:"shipping_address_id" example:"1"`
+	CouponCode        string                 `json:"coupon_code" binding:"max=64" example:"WELCOME10"`
+}
+
+func respondShippingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errShippingZoneNameTaken), errors.Is(err, errShippingLocationTaken):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	case errors.Is(err, errShippingMethodNotFound), errors.Is(err, errShippingZoneNotFound),
+		errors.Is(err, errShippingWeightRange):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save shipping settings"})
+	}
+}
+
+func applyShippingMethodInput(method *database.ShippingMethod, input ShippingMethodInput) {
+	method.Name = input.Name
+	method.Type = input.Type
+	method.Description = input.Description
+	method.Position = input.Position
+	if input.Active != nil {
+		method.Active = *input.Active
+	}
+}
+
+// @Summary      Получить способы доставки
+// @Description  Возвращает все способы доставки, включая неактивные, в порядке position.
+// @Tags         Доставка (Shipping)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   database.ShippingMethod
+// @Failure      403  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipping-methods [get]
+func getShippingMethods(c *gin.Context) {
+	var methods []database.ShippingMethod
+	if err := database.DB.Order("position ASC, id ASC").Find(&methods).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch shipping methods"})
+		return
+	}
+	c.JSON(http.StatusOK, methods)
+}
+
+// @Summary      Создать способ доставки
+// @Description  Создает способ доставки: курьер (courier), пункт выдачи (pickup) или почта (post).
+// @Description  Стоимость доставки задается тарифами способа в зонах доставки. Если active не указан, способ создается активным.
+// @Tags         Доставка (Shipping)
+// @Accept       json
+// @Produce      json
+// @Param        method  body      router.ShippingMethodInput  true  "Данные способа доставки"
+// @Security     BearerAuth
+// @Success      201     {object}  database.ShippingMethod
+// @Failure      400     {object}  router.HTTPError
+// @Failure      403     {object}  router.HTTPError
+// @Failure      500     {object}  router.HTTPError
+// @Router       /shipping-methods [post]
+func createShippingMethod(c *gin.Context) {
+	var input ShippingMethodInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	method := database.ShippingMethod{Active: true}
+	applyShippingMethodInput(&method, input)
+	if err := database.DB.Create(&method).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create shipping method"})
+		return
+	}
+	setAudit(c, "shipping_method.create", "shipping_method", method.ID, nil, method)
+
+	c.JSON(http.StatusCreated, method)
+}
+
+// @Summary      Обновить способ доставки
+// @Description  Изменяет способ доставки. Если active не указан, активность не меняется. Уже оформленные заказы не пересчитываются.
+// @Tags         Доставка (Shipping)
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                         true  "ID способа доставки"
+// @Param        method  body      router.ShippingMethodInput  true  "Данные способа доставки"
+// @Security     BearerAuth
+// @Success      200     {object}  database.ShippingMethod
+// @Failure      400     {object}  router.HTTPError
+// @Failure      403     {object}  router.HTTPError
+// @Failure      404     {object}  router.HTTPError
+// @Failure      500     {object}  router.HTTPError
+// @Router       /shipping-methods/{id} [put]
+func updateShippingMethod(c *gin.Context) {
+	var method database.ShippingMethod
+	if err := database.DB.First(&method, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipping method not found"})
+		return
+	}
+
+	var input ShippingMethodInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := method
+	applyShippingMethodInput(&method, input)
+	if err := database.DB.Save(&method).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update shipping method"})
+		return
+	}
+	setAudit(c, "shipping_method.update", "shipping_method", method.ID, before, method)
+
+	c.JSON(http.StatusOK, method)
+}
+
+// @Summary      Удалить способ доставки
+// @Description  Удаляет способ доставки вместе с его тарифами. В оформленных заказах сохраняется название способа.
+// @Tags         Доставка (Shipping)
+// @Produce      json
+// @Param        id   path      int  true  "ID способа доставки"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipping-methods/{id} [delete]
+func deleteShippingMethod(c *gin.Context) {
+	var method database.ShippingMethod
+	if err := database.DB.First(&method, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipping method not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("method_id = ?", method.ID).Delete(&database.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&method).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete shipping method"})
+		return
+	}
+	setAudit(c, "shipping_method.delete", "shipping_method", method.ID, method, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Shipping method deleted successfully"})
+}
+
+// saveShippingZone stores the zone and replaces its locations, checking that
+// the name and the locations are not used by another zone.
+func saveShippingZone(tx *gorm.DB, zone *database.ShippingZone, input ShippingZoneInput) error {
+	var count int64
+	if err := tx.Model(&database.ShippingZone{}).Where("name = ? AND id <> ?", input.Name, zone.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errShippingZoneNameTaken
+	}
+
+	zone.Name = input.Name
+	zone.Locations = nil
+	if err := tx.Omit("Locations").Save(zone).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("zone_id = ?", zone.ID).Delete(&database.ShippingZoneLocation{}).Error; err != nil {
+		return err
+	}
+
+	seen := make(map[database.ShippingZoneLocation]bool)
+	for _, locationInput := range input.Locations {
+		location := database.ShippingZoneLocation{
+			ZoneID:  zone.ID,
+			Country: strings.ToUpper(locationInput.Country),
+			Region:  database.NormalizeRegion(locationInput.Region),
+		}
+		if seen[location] {
+			continue
+		}
+		seen[location] = true
+
+		err := tx.Model(&database.ShippingZoneLocation{}).
+			Where("country = ? AND region = ?", location.Country, location.Region).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errShippingLocationTaken
+		}
+		if err := tx.Create(&location).Error; err != nil {
+			return err
+		}
+		zone.Locations = append(zone.Locations, location)
+	}
+	return nil
+}
+
+// @Summary      Получить зоны доставки
+// @Description  Возвращает все зоны доставки с их странами и регионами.
+// @Tags         Доставка (Shipping)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   database.ShippingZone
+// @Failure      403  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipping-zones [get]
+func getShippingZones(c *gin.Context) {
+	var zones []database.ShippingZone
+	if err := database.DB.Preload("Locations").Order("name ASC").Find(&zones).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch shipping zones"})
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+// @Summary      Создать зону доставки
+// @Description  Создает зону доставки из стран и регионов. Локация без региона охватывает всю страну; регион сравнивается
+// @Description  с регионом адреса без учета регистра, и зона региона имеет приоритет над зоной страны. Локация может входить только в одну зону.
+// @Tags         Доставка (Shipping)
+// @Accept       json
+// @Produce      json
+// @Param        zone  body      router.ShippingZoneInput  true  "Данные зоны доставки"
+// @Security     BearerAuth
+// @Success      201   {object}  database.ShippingZone
+// @Failure      400   {object}  router.HTTPError
+// @Failure      403   {object}  router.HTTPError
+// @Failure      409   {object}  router.HTTPError  "Название зоны занято или локация входит в другую зону"
+// @Failure      500   {object}  router.HTTPError
+// @Router       /shipping-zones [post]
+func createShippingZone(c *gin.Context) {
+	var input ShippingZoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var zone database.ShippingZone
+	if err := database.DB.Transaction(func(tx *gorm.DB) error { return saveShippingZone(tx, &zone, input) }); err != nil {
+		respondShippingError(c, err)
+		return
+	}
+	setAudit(c, "shipping_zone.create", "shipping_zone", zone.ID, nil, zone)
+
+	c.JSON(http.StatusCreated, zone)
+}
+
+// @Summary      Обновить зону доставки
+// @Description  Переименовывает зону доставки и заменяет список ее локаций.
+// @Tags         Доставка (Shipping)
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                       true  "ID зоны доставки"
+// @Param        zone  body      router.ShippingZoneInput  true  "Данные зоны доставки"
+// @Security     BearerAuth
+// @Success      200   {object}  database.ShippingZone
+// @Failure      400   {object}  router.HTTPError
+// @Failure      403   {object}  router.HTTPError
+// @Failure      404   {object}  router.HTTPError
+// @Failure      409   {object}  router.HTTPError  "Название зоны занято или локация входит в другую зону"
+// @Failure      500   {object}  router.HTTPError
+// @Router       /shipping-zones/{id} [put]
+func updateShippingZone(c *gin.Context) {
+	var zone database.ShippingZone
+	if err := database.DB.Preload("Locations").First(&zone, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipping zone not found"})
+		return
+	}
+
+	var input ShippingZoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := zone
+	if err := database.DB.Transaction(func(tx *gorm.DB) error { return saveShippingZone(tx, &zone, input) }); err != nil {
+		respondShippingError(c, err)
+		return
+	}
+	setAudit(c, "shipping_zone.update", "shipping_zone", zone.ID, before, zone)
+
+	c.JSON(http.StatusOK, zone)
+}
+
+// @Summary      Удалить зону доставки
+// @Description  Удаляет зону доставки вместе с ее локациями и тарифами.
+// @Tags         Доставка (Shipping)
+// @Produce      json
+// @Param        id   path      int  true  "ID зоны доставки"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipping-zones/{id} [delete]
+func deleteShippingZone(c *gin.Context) {
+	var zone database.ShippingZone
+	if err := database.DB.Preload("Locations").First(&zone, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipping zone not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("zone_id = ?", zone.ID).Delete(&database.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("zone_id = ?", zone.ID).Delete(&database.ShippingZoneLocation{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&zone).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete shipping zone"})
+		return
+	}
+	setAudit(c, "shipping_zone.delete", "shipping_zone", zone.ID, zone, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Shipping zone deleted successfully"})
+}
+
+// applyShippingRateInput copies the input onto the rate and checks that the
+// method and the zone exist.
+func applyShippingRateInput(db *gorm.DB, rate *database.ShippingRate, input ShippingRateInput) error {
+	if input.MaxWeight != nil && *input.MaxWeight <= input.MinWeight {
+		return errShippingWeightRange
+	}
+
+	var count int64
+	if err := db.Model(&database.ShippingMethod{}).Where("id = ?", input.MethodID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errShippingMethodNotFound
+	}
+	if err := db.Model(&database.ShippingZone{}).Where("id = ?", input.ZoneID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errShippingZoneNotFound
+	}
+
+	rate.MethodID = input.MethodID
+	rate.ZoneID = input.ZoneID
+	rate.MinWeight = input.MinWeight
+	rate.MaxWeight = input.MaxWeight
+	rate.Price = input.Price
+	rate.PricePerKg = input.PricePerKg
+	rate.FreeFrom = input.FreeFrom
+	return nil
+}
+
+// @Summary      Получить тарифы доставки
+// @Description  Возвращает тарифы доставки с фильтрами по способу и зоне.
+// @Tags         Доставка (Shipping)
+// @Produce      json
+// @Param        method_id  query     int  false  "ID способа доставки"
+// @Param        zone_id    query     int  false  "ID зоны доставки"
+// @Security     BearerAuth
+// @Success      200        {array}   database.ShippingRate
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /shipping-rates [get]
+func getShippingRates(c *gin.Context) {
+	var query ShippingRateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	db := database.DB.Order("method_id ASC, zone_id ASC, min_weight ASC")
+	if query.MethodID != 0 {
+		db = db.Where("method_id = ?", query.MethodID)
+	}
+	if query.ZoneID != 0 {
+		db = db.Where("zone_id = ?", query.ZoneID)
+	}
+
+	var rates []database.ShippingRate
+	if err := db.Find(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch shipping rates"})
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+// @Summary      Создать тариф доставки
+// @Description  Создает тариф способа доставки в зоне для заказов весом от min_weight (включительно) до max_weight (не включительно) кг.
+// @Description  Стоимость равна price плюс price_per_kg за каждый начатый килограмм сверх min_weight. Заказы на сумму от free_from доставляются бесплатно.
+// @Description  Если для веса заказа подходят несколько тарифов способа, применяется самый дешевый.
+// @Tags         Доставка (Shipping)
+// @Accept       json
+// @Produce      json
+// @Param        rate  body      router.ShippingRateInput  true  "Данные тарифа доставки"
+// @Security     BearerAuth
+// @Success      201   {object}  database.ShippingRate
+// @Failure      400   {object}  router.HTTPError  "Ошибка валидации, способ или зона доставки не найдены"
+// @Failure      403   {object}  router.HTTPError
+// @Failure      500   {object}  router.HTTPError
+// @Router       /shipping-rates [post]
+func createShippingRate(c *gin.Context) {
+	var input ShippingRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var rate database.ShippingRate
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyShippingRateInput(tx, &rate, input); err != nil {
+			return err
+		}
+		return tx.Create(&rate).Error
+	})
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+	setAudit(c, "shipping_rate.create", "shipping_rate", rate.ID, nil, rate)
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// @Summary      Обновить тариф доставки
+// @Description  Изменяет тариф доставки. Уже оформленные заказы не пересчитываются.
+// @Tags         Доставка (Shipping)
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                       true  "ID тарифа доставки"
+// @Param        rate  body      router.ShippingRateInput  true  "Данные тарифа доставки"
+// @Security     BearerAuth
+// @Success      200   {object}  database.ShippingRate
+// @Failure      400   {object}  router.HTTPError  "Ошибка валидации, способ или зона доставки не найдены"
+// @Failure      403   {object}  router.HTTPError
+// @Failure      404   {object}  router.HTTPError
+// @Failure      500   {object}  router.HTTPError
+// @Router       /shipping-rates/{id} [put]
+func updateShippingRate(c *gin.Context) {
+	var rate database.ShippingRate
+	if err := database.DB.First(&rate, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipping rate not found"})
+		return
+	}
+
+	var input ShippingRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := rate
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyShippingRateInput(tx, &rate, input); err != nil {
+			return err
+		}
+		return tx.Save(&rate).Error
+	})
+	if err != nil {
+		respondShippingError(c, err)
+		return
+	}
+	setAudit(c, "shipping_rate.update", "shipping_rate", rate.ID, before, rate)
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// @Summary      Удалить тариф доставки
+// @Description  Удаляет тариф доставки.
+// @Tags         Доставка (Shipping)
+// @Produce      json
+// @Param        id   path      int  true  "ID тарифа доставки"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /shipping-rates/{id} [delete]
+func deleteShippingRate(c *gin.Context) {
+	var rate database.ShippingRate
+	if err := database.DB.First(&rate, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Shipping rate not found"})
+		return
+	}
+	if err := database.DB.Delete(&rate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete shipping rate"})
+		return
+	}
+	setAudit(c, "shipping_rate.delete", "shipping_rate", rate.ID, rate, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Shipping rate deleted successfully"})
+}
+
+// @Summary      Рассчитать стоимость доставки
+// @Description  Возвращает способы доставки, доступные для корзины по адресу доставки (shipping_address_id или адрес по умолчанию),
+// @Description  и их стоимость с учетом веса товаров и суммы заказа после скидок. Пустой список означает, что доставка по адресу невозможна.
+// @Tags         Доставка (Shipping)
+// @Accept       json
+// @Produce      json
+// @Param        basket  body      router.ShippingQuoteInput  true  "Товары, адрес доставки и код купона"
+// @Security     BearerAuth
+// @Success      200     {array}   pricing.ShippingOption
+// @Failure      400     {object}  router.HTTPError  "Ошибка валидации входных данных, не указан адрес или купон недействителен"
+// @Failure      401     {object}  router.HTTPError
+// @Failure      404     {object}  router.HTTPError  "Один или несколько товаров или адрес не найдены"
+// @Failure      409     {object}  router.HTTPError  "Недостаточно товара на складе или исчерпан лимит использования купона"
+// @Failure      500     {object}  router.HTTPError
+// @Router       /shipping/quote [post]
+func quoteShipping(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var input ShippingQuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	shipping, err := resolveOrderAddress(database.DB, userID.(uint), input.ShippingAddressID, "is_default_shipping")
+	if err == nil && shipping == nil {
+		err = errAddressRequired
+	}
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+	snapshot := shipping.Snapshot()
+
+	lines, err := resolveOrderLines(database.DB, input.Items, false)
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+	quote, _, err := quoteOrder(database.DB, userID.(uint), lines, input.CouponCode, &snapshot, nil, time.Now())
+	if err != nil {
+		respondOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote.ShippingOptions)
+}
@@ -0,0 +1,380 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+var (
+	errTaxClassNotFound  = errors.New("tax class not found")
+	errTaxClassNameTaken = errors.New("tax class name is already used")
+	errTaxClassInUse     = errors.New("tax class is assigned to products")
+	errTaxRateExists     = errors.New("a rate for this tax class and location already exists")
+)
+
+type TaxClassInput struct {
+	Name      string `json:"name" binding:"required,max=100" example:"НДС 20%"`
+	IsDefault bool   `json:"is_default" example:"true"`
+}
+
+type TaxRateInput struct {
+	TaxClassID uint    `json:"tax_class_id" binding:"required" example:"1"`
+	Country    string  `json:"country" binding:"required,len=2" example:"RU"`
+	Region     string  `json:"region" binding:"max=255" example:""`
+	Name       string  `json:"name" binding:"required,max=100" example:"НДС"`
+	Rate       float64 `json:"rate" binding:"gte=0,max=100" example:"20"`
+}
+
+type TaxRateQuery struct {
+	TaxClassID uint   `form:"tax_class_id" example:"1"`
+	Country    string `form:"country" example:"RU"`
+}
+
+// checkTaxClassExists returns errTaxClassNotFound unless id is nil or refers
+// to an existing tax class.
+func checkTaxClassExists(db *gorm.DB, id *uint) error {
+	if id == nil {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&database.TaxClass{}).Where("id = ?", *id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errTaxClassNotFound
+	}
+	return nil
+}
+
+// saveTaxClass stores the class, making sure only one class is the default.
+func saveTaxClass(tx *gorm.DB, class *database.TaxClass) error {
+	var count int64
+	if err := tx.Model(&database.TaxClass{}).Where("name = ? AND id <> ?", class.Name, class.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errTaxClassNameTaken
+	}
+	if err := tx.Save(class).Error; err != nil {
+		return err
+	}
+	if !class.IsDefault {
+		return nil
+	}
+	return tx.Model(&database.TaxClass{}).Where("id <> ?", class.ID).Update("is_default", false).Error
+}
+
+func respondTaxError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errTaxClassNameTaken), errors.Is(err, errTaxClassInUse), errors.Is(err, errTaxRateExists):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	case errors.Is(err, errTaxClassNotFound):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save tax settings"})
+	}
+}
+
+// @Summary      Получить налоговые классы
+// @Description  Возвращает все налоговые классы. Товары без налогового класса облагаются по классу по умолчанию.
+// @Tags         Налоги (Taxes)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   database.TaxClass
+// @Failure      403  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /tax-classes [get]
+func getTaxClasses(c *gin.Context) {
+	var classes []database.TaxClass
+	if err := database.DB.Order("name ASC").Find(&classes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch tax classes"})
+		return
+	}
+	c.JSON(http.StatusOK, classes)
+}
+
+// @Summary      Создать налоговый класс
+// @Description  Создает налоговый класс. Если is_default установлен, класс становится классом по умолчанию вместо текущего.
+// @Tags         Налоги (Taxes)
+// @Accept       json
+// @Produce      json
+// @Param        class  body      router.TaxClassInput  true  "Данные налогового класса"
+// @Security     BearerAuth
+// @Success      201    {object}  database.TaxClass
+// @Failure      400    {object}  router.HTTPError
+// @Failure      403    {object}  router.HTTPError
+// @Failure      409    {object}  router.HTTPError  "Класс с таким названием уже существует"
+// @Failure      500    {object}  router.HTTPError
+// @Router       /tax-classes [post]
+func createTaxClass(c *gin.Context) {
+	var input TaxClassInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	class := database.TaxClass{Name: input.Name, IsDefault: input.IsDefault}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error { return saveTaxClass(tx, &class) }); err != nil {
+		respondTaxError(c, err)
+		return
+	}
+	setAudit(c, "tax_class.create", "tax_class", class.ID, nil, class)
+
+	c.JSON(http.StatusCreated, class)
+}
+
+// @Summary      Обновить налоговый класс
+// @Description  Переименовывает налоговый класс или делает его классом по умолчанию.
+// @Tags         Налоги (Taxes)
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                   true  "ID налогового класса"
+// @Param        class  body      router.TaxClassInput  true  "Данные налогового класса"
+// @Security     BearerAuth
+// @Success      200    {object}  database.TaxClass
+// @Failure      400    {object}  router.HTTPError
+// @Failure      403    {object}  router.HTTPError
+// @Failure      404    {object}  router.HTTPError
+// @Failure      409    {object}  router.HTTPError  "Класс с таким названием уже существует"
+// @Failure      500    {object}  router.HTTPError
+// @Router       /tax-classes/{id} [put]
+func updateTaxClass(c *gin.Context) {
+	var class database.TaxClass
+	if err := database.DB.First(&class, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Tax class not found"})
+		return
+	}
+
+	var input TaxClassInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := class
+	class.Name = input.Name
+	class.IsDefault = input.IsDefault
+	if err := database.DB.Transaction(func(tx *gorm.DB) error { return saveTaxClass(tx, &class) }); err != nil {
+		respondTaxError(c, err)
+		return
+	}
+	setAudit(c, "tax_class.update", "tax_class", class.ID, before, class)
+
+	c.JSON(http.StatusOK, class)
+}
+
+// @Summary      Удалить налоговый класс
+// @Description  Удаляет налоговый класс вместе с его ставками. Класс, назначенный товарам (включая товары в корзине), удалить нельзя.
+// @Tags         Налоги (Taxes)
+// @Produce      json
+// @Param        id   path      int  true  "ID налогового класса"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      409  {object}  router.HTTPError  "Класс назначен товарам"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /tax-classes/{id} [delete]
+func deleteTaxClass(c *gin.Context) {
+	var class database.TaxClass
+	if err := database.DB.First(&class, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Tax class not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var products int64
+		if err := tx.Unscoped().Model(&database.Product{}).Where("tax_class_id = ?", class.ID).Count(&products).Error; err != nil {
+			return err
+		}
+		if products > 0 {
+			return errTaxClassInUse
+		}
+		if err := tx.Where("tax_class_id = ?", class.ID).Delete(&database.TaxRate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&class).Error
+	})
+	if err != nil {
+		if errors.Is(err, errTaxClassInUse) {
+			c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete tax class"})
+		return
+	}
+	setAudit(c, "tax_class.delete", "tax_class", class.ID, class, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Tax class deleted successfully"})
+}
+
+// @Summary      Получить налоговые ставки
+// @Description  Возвращает налоговые ставки с фильтрами по классу и стране.
+// @Tags         Налоги (Taxes)
+// @Produce      json
+// @Param        tax_class_id  query     int     false  "ID налогового класса"
+// @Param        country       query     string  false  "Код страны (ISO 3166-1 alpha-2)"
+// @Security     BearerAuth
+// @Success      200           {array}   database.TaxRate
+// @Failure      400           {object}  router.HTTPError
+// @Failure      403           {object}  router.HTTPError
+// @Failure      500           {object}  router.HTTPError
+// @Router       /tax-rates [get]
+func getTaxRates(c *gin.Context) {
+	var query TaxRateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	db := database.DB.Order("tax_class_id ASC, country ASC, region ASC")
+	if query.TaxClassID != 0 {
+		db = db.Where("tax_class_id = ?", query.TaxClassID)
+	}
+	if query.Country != "" {
+		db = db.Where("country = ?", strings.ToUpper(query.Country))
+	}
+
+	var rates []database.TaxRate
+	if err := db.Find(&rates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch tax rates"})
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+// applyTaxRateInput copies the input onto the rate and checks that the class
+// exists and has no other rate for the same location.
+func applyTaxRateInput(db *gorm.DB, rate *database.TaxRate, input TaxRateInput) error {
+	if err := checkTaxClassExists(db, &input.TaxClassID); err != nil {
+		return err
+	}
+	rate.TaxClassID = input.TaxClassID
+	rate.Country = strings.ToUpper(input.Country)
+	rate.Region = database.NormalizeRegion(input.Region)
+	rate.Name = input.Name
+	rate.Rate = input.Rate
+
+	var count int64
+	err := db.Model(&database.TaxRate{}).
+		Where("tax_class_id = ? AND country = ? AND region = ? AND id <> ?", rate.TaxClassID, rate.Country, rate.Region, rate.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errTaxRateExists
+	}
+	return nil
+}
+
+// @Summary      Создать налоговую ставку
+// @Description  Создает ставку налогового класса для страны или, если указан region, для региона страны. Ставка региона имеет приоритет над ставкой страны.
+// @Description  Регион сравнивается с регионом адреса доставки без учета регистра.
+// @Tags         Налоги (Taxes)
+// @Accept       json
+// @Produce      json
+// @Param        rate  body      router.TaxRateInput  true  "Данные налоговой ставки"
+// @Security     BearerAuth
+// @Success      201   {object}  database.TaxRate
+// @Failure      400   {object}  router.HTTPError
+// @Failure      403   {object}  router.HTTPError
+// @Failure      409   {object}  router.HTTPError  "Ставка для этого класса и региона уже существует"
+// @Failure      500   {object}  router.HTTPError
+// @Router       /tax-rates [post]
+func createTaxRate(c *gin.Context) {
+	var input TaxRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var rate database.TaxRate
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyTaxRateInput(tx, &rate, input); err != nil {
+			return err
+		}
+		return tx.Create(&rate).Error
+	})
+	if err != nil {
+		respondTaxError(c, err)
+		return
+	}
+	setAudit(c, "tax_rate.create", "tax_rate", rate.ID, nil, rate)
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// @Summary      Обновить налоговую ставку
+// @Description  Изменяет налоговую ставку. Уже оформленные заказы не пересчитываются.
+// @Tags         Налоги (Taxes)
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                  true  "ID налоговой ставки"
+// @Param        rate  body      router.TaxRateInput  true  "Данные налоговой ставки"
+// @Security     BearerAuth
+// @Success      200   {object}  database.TaxRate
+// @Failure      400   {object}  router.HTTPError
+// @Failure      403   {object}  router.HTTPError
+// @Failure      404   {object}  router.HTTPError
+// @Failure      409   {object}  router.HTTPError  "Ставка для этого класса и региона уже существует"
+// @Failure      500   {object}  router.HTTPError
+// @Router       /tax-rates/{id} [put]
+func updateTaxRate(c *gin.Context) {
+	var rate database.TaxRate
+	if err := database.DB.First(&rate, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Tax rate not found"})
+		return
+	}
+
+	var input TaxRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := rate
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyTaxRateInput(tx, &rate, input); err != nil {
+			return err
+		}
+		return tx.Save(&rate).Error
+	})
+	if err != nil {
+		respondTaxError(c, err)
+		return
+	}
+	setAudit(c, "tax_rate.update", "tax_rate", rate.ID, before, rate)
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// @Summary      Удалить налоговую ставку
+// @Description  Удаляет налоговую ставку. Товары этого класса в этом регионе больше не облагаются налогом, если нет ставки для всей страны.
+// @Tags         Налоги (Taxes)
+// @Produce      json
+// @Param        id   path      int  true  "ID налоговой ставки"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /tax-rates/{id} [delete]
+func deleteTaxRate(c *gin.Context) {
+	var rate database.TaxRate
+	if err := database.DB.First(&rate, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Tax rate not found"})
+		return
+	}
+	if err := database.DB.Delete(&rate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete tax rate"})
+		return
+	}
+	setAudit(c, "tax_rate.delete", "tax_rate", rate.ID, rate, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Tax rate deleted successfully"})
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,18 +15,24 @@ type LoginInput struct {
 	Email    string `json:"email" binding:"required,email" example:"Test@gmail.com"`
 	Password string `json:"password" binding:"required,min=8"`
 }
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID uint     `json:"user_id"`
+	Roles  []string `json:"roles"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, role string) (string, error) {
+func GenerateJWT(userID uint, roles []string) (string, error) {
 	expirationTime := time.Now().Add(15 * time.Minute)
 
 	claims := &Claims{
 		UserID: userID,
-		Role:   role,
+		Roles:  roles,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -58,23 +65,46 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		var user database.Customer
+		if err := database.DB.Select("id", "disabled_at").First(&user, claims.UserID).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, HTTPError{Message: "invalid token"})
+			c.Abort()
+			return
+		}
+
+		if user.DisabledAt != nil {
+			c.JSON(http.StatusForbidden, HTTPError{Message: "account is disabled"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
-		c.Set("role", claims.Role)
+		c.Set("roles", claims.Roles)
 		c.Next()
 	}
 }
 
-func AdminMiddleware() gin.HandlerFunc {
+// RequirePermission allows the request only if one of the user's roles grants
+// the permission. Roles are checked against the database rather than the
+// token, so revoking a role takes effect immediately.
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("role")
+		userID, exists := c.Get("userID")
 		if !exists {
-			c.JSON(http.StatusForbidden, HTTPError{Message: "Access denied: role not found in token"})
+			c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := database.CustomerHasPermission(database.DB, userID.(uint), permission)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to check permissions"})
 			c.Abort()
 			return
 		}
 
-		if userRole.(string) != "admin" {
-			c.JSON(http.StatusForbidden, HTTPError{Message: "Access denied: requires admin privileges"})
+		if !allowed {
+			c.JSON(http.StatusForbidden, HTTPError{Message: "Access denied: requires " + permission + " permission"})
 			c.Abort()
 			return
 		}
@@ -84,7 +114,7 @@ func AdminMiddleware() gin.HandlerFunc {
 }
 
 // @Summary      Регистрация нового пользователя
-// @Description  Создает новый аккаунт пользователя с email и паролем.
+// @Description  Создает новый аккаунт пользователя с email и паролем. Пользователю отправляется приветственное письмо.
 // @Tags         Пользователи (Auth)
 // @Accept       json
 // @Produce      json
@@ -119,10 +149,25 @@ func registerUser(c *gin.Context) {
 		RegistrationDate: time.Now(),
 	}
 
-	if result := database.DB.Create(&newUser); result.Error != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+		err := database.EnqueueNotification(tx, database.NotificationEvent{
+			Type:       database.NotificationAccountRegistered,
+			CustomerID: newUser.ID,
+		})
+		if err != nil {
+			return err
+		}
+		return database.EnqueueWebhookEvent(tx, database.WebhookEventCustomerRegistered, newUser)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, HTTPError{Message: "failed to create user"})
 		return
 	}
+	setAudit(c, "auth.register", "customer", newUser.ID, nil, newUser)
+	setAuditActor(c, newUser.ID)
 
 	c.JSON(http.StatusCreated, newUser)
 }
@@ -136,6 +181,7 @@ func registerUser(c *gin.Context) {
 // @Success      200    {object}  object{token=string}  "JWT токен"
 // @Failure      400    {object}  router.HTTPError
 // @Failure      401    {object}  router.HTTPError
+// @Failure      403    {object}  router.HTTPError      "Аккаунт заблокирован"
 // @Router       /users/login [post]
 func loginUser(c *gin.Context) {
 	var input LoginInput
@@ -145,22 +191,38 @@ func loginUser(c *gin.Context) {
 	}
 
 	var user database.Customer
-	if err := database.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+	if err := database.DB.Preload("Roles").Where("email = ?", input.Email).First(&user).Error; err != nil {
+		setAudit(c, "auth.login_failed", "customer", "", nil, gin.H{"email": input.Email})
 		c.JSON(http.StatusUnauthorized, HTTPError{Message: "invalid email or password"})
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		setAudit(c, "auth.login_failed", "customer", user.ID, nil, gin.H{"email": input.Email})
 		c.JSON(http.StatusUnauthorized, HTTPError{Message: "invalid email or password"})
 		return
 	}
 
-	token, err := GenerateJWT(user.ID, user.Role)
+	if user.DisabledAt != nil {
+		setAudit(c, "auth.login_disabled", "customer", user.ID, nil, nil)
+		setAuditActor(c, user.ID)
+		c.JSON(http.StatusForbidden, HTTPError{Message: "account is disabled"})
+		return
+	}
+
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+
+	token, err := GenerateJWT(user.ID, roles)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, HTTPError{Message: "could not generate token"})
 		return
 	}
 
+	setAudit(c, "auth.login", "customer", user.ID, nil, nil)
+	setAuditActor(c, user.ID)
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
 
@@ -183,7 +245,7 @@ func SayHello(c *gin.Context) {
 	id := userID.(uint)
 
 	var user database.Customer
-	if err := database.DB.First(&user, id).Error; err != nil {
+	if err := database.DB.Preload("Roles").First(&user, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, HTTPError{Message: "user not found"})
 		return
 	}
@@ -191,8 +253,69 @@ func SayHello(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// @Summary      Сменить пароль
+// @Description  Меняет пароль текущего пользователя после проверки текущего пароля. На email пользователя отправляется уведомление о смене пароля.
+// @Tags         Пользователи (Auth)
+// @Accept       json
+// @Produce      json
+// @Param        input  body      router.ChangePasswordInput  true  "Текущий и новый пароль"
+// @Security     BearerAuth
+// @Success      200    {object}  router.SuccessMessage
+// @Failure      400    {object}  router.HTTPError  "Ошибка валидации входных данных"
+// @Failure      401    {object}  router.HTTPError  "Неверный текущий пароль"
+// @Failure      500    {object}  router.HTTPError
+// @Router       /users/me/password [put]
+func changePassword(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "user ID not found in context"})
+		return
+	}
+
+	var input ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	var user database.Customer
+	if err := database.DB.First(&user, userID.(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "user not found"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.CurrentPassword)); err != nil {
+		setAudit(c, "auth.password_change_failed", "customer", user.ID, nil, nil)
+		c.JSON(http.StatusUnauthorized, HTTPError{Message: "invalid current password"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "failed to hash password"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_hash", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		return database.EnqueueNotification(tx, database.NotificationEvent{
+			Type:       database.NotificationPasswordChanged,
+			CustomerID: user.ID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "failed to change password"})
+		return
+	}
+	setAudit(c, "auth.password_change", "customer", user.ID, nil, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Password changed successfully"})
+}
+
 // @Summary      Повысить пользователя до администратора
-// @Description  Позволяет администратору назначить другого пользователя администратором.
+// @Description  Позволяет администратору назначить другого пользователя администратором. Эквивалентно назначению роли admin.
 // @Tags         Администрирование (Admin)
 // @Produce      json
 // @Param        id   path      int  true  "ID пользователя, которого нужно повысить"
@@ -217,15 +340,21 @@ func promoteUserToAdmin(c *gin.Context) {
 		return
 	}
 
-	if user.Role == "admin" {
+	isAdmin, err := database.CustomerHasRole(database.DB, user.ID, database.RoleAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to promote user"})
+		return
+	}
+	if isAdmin {
 		c.JSON(http.StatusConflict, HTTPError{Message: "User is already an admin"})
 		return
 	}
 
-	if err := database.DB.Model(&user).Update("role", "admin").Error; err != nil {
+	if err := database.AssignRole(database.DB, user.ID, database.RoleAdmin); err != nil {
 		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to promote user"})
 		return
 	}
+	setAudit(c, "role.assign", "customer", user.ID, nil, gin.H{"role": database.RoleAdmin})
 
 	c.JSON(http.StatusOK, SuccessMessage{Message: "User successfully promoted to admin"})
 }
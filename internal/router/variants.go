@@ -0,0 +1,375 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	errInvalidVariantOptions = errors.New("invalid variant options")
+	errDuplicateVariant      = errors.New("a variant with the same options already exists")
+)
+
+type ProductOptionInput struct {
+	Name   string   `json:"name" binding:"required,max=100" example:"Размер"`
+	Values []string `json:"values" binding:"required,min=1,dive,required" example:"S,M,L"`
+}
+
+type ProductOptionsInput struct {
+	Options []ProductOptionInput `json:"options" binding:"dive"`
+}
+
+type VariantInput struct {
+	SKU     string            `json:"sku" binding:"max=64" example:"TSHIRT-RED-M"`
+	Price   *float64          `json:"price" binding:"omitempty,gte=0" example:"1290"`
+	Stock   int               `json:"stock" binding:"gte=0" example:"10"`
+	Options map[string]string `json:"options" example:"Размер:M,Цвет:Красный"`
+}
+
+// validateVariantOptions checks that values sets every option of the product
+// to one of its allowed values and sets nothing else.
+func validateVariantOptions(options []database.ProductOption, values map[string]string) error {
+	if len(values) != len(options) {
+		return fmt.Errorf("%w: expected values for %d option(s), got %d", errInvalidVariantOptions, len(options), len(values))
+	}
+	for _, option := range options {
+		value, ok := values[option.Name]
+		if !ok {
+			return fmt.Errorf("%w: missing value for option %q", errInvalidVariantOptions, option.Name)
+		}
+		allowed := false
+		for _, candidate := range option.Values {
+			if candidate == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %q is not a value of option %q", errInvalidVariantOptions, value, option.Name)
+		}
+	}
+	return nil
+}
+
+// variantKey identifies a combination of option values regardless of map order.
+func variantKey(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key+"="+values[key])
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x00")
+}
+
+// checkVariants validates all variants of the product against its options
+// and rejects two variants with the same combination of values.
+func checkVariants(options []database.ProductOption, variants []database.Variant) error {
+	seen := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		if err := validateVariantOptions(options, variant.Options); err != nil {
+			return err
+		}
+		key := variantKey(variant.Options)
+		if seen[key] {
+			return errDuplicateVariant
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// saveVariant validates the variant against the product's options and its
+// other variants, then stores it.
+func saveVariant(tx *gorm.DB, variant *database.Variant) error {
+	var options []database.ProductOption
+	if err := tx.Where("product_id = ?", variant.ProductID).Find(&options).Error; err != nil {
+		return err
+	}
+
+	var others []database.Variant
+	if err := tx.Where("product_id = ? AND id <> ?", variant.ProductID, variant.ID).Find(&others).Error; err != nil {
+		return err
+	}
+
+	if err := checkVariants(options, append(others, *variant)); err != nil {
+		return err
+	}
+
+	if variant.SKU != nil {
+		var count int64
+		err := tx.Unscoped().Model(&database.Variant{}).
+			Where("sku = ? AND id <> ?", *variant.SKU, variant.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errSKUTaken
+		}
+	}
+
+	return tx.Save(variant).Error
+}
+
+// preloadVariants loads the options and variants of products in display order.
+func preloadVariants(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") })
+}
+
+func respondVariantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errInvalidVariantOptions):
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+	case errors.Is(err, errDuplicateVariant), errors.Is(err, errSKUTaken):
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to save variant"})
+	}
+}
+
+func (input VariantInput) apply(variant *database.Variant) {
+	variant.SKU = nil
+	if input.SKU != "" {
+		sku := input.SKU
+		variant.SKU = &sku
+	}
+	variant.Price = input.Price
+	variant.Stock = input.Stock
+	variant.Options = input.Options
+	if variant.Options == nil {
+		variant.Options = database.StringMap{}
+	}
+}
+
+// @Summary      Получить варианты товара
+// @Description  Возвращает опции (например, размер и цвет) и варианты опубликованного товара с ценой и остатком.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id   path      int  true  "ID Товара"
+// @Success      200  {object}  database.Product  "Товар с опциями и вариантами"
+// @Failure      404  {object}  router.HTTPError
+// @Router       /products/{id}/variants [get]
+func getProductVariants(c *gin.Context) {
+	var product database.Product
+	err := database.DB.
+		Scopes(preloadVariants, preloadImages).
+		Where("status = ?", database.ProductStatusPublished).
+		First(&product, c.Param("id")).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// @Summary      Задать опции товара
+// @Description  Полностью заменяет список опций товара. Запрос отклоняется, если существующие варианты перестанут им соответствовать.
+// @Tags         Товары (Products)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                         true  "ID Товара"
+// @Param        options  body      router.ProductOptionsInput  true  "Опции товара"
+// @Security     BearerAuth
+// @Success      200      {array}   database.ProductOption
+// @Failure      400      {object}  router.HTTPError  "Некорректные опции или варианты им не соответствуют"
+// @Failure      403      {object}  router.HTTPError
+// @Failure      404      {object}  router.HTTPError
+// @Failure      409      {object}  router.HTTPError  "Варианты совпадают после изменения опций"
+// @Failure      500      {object}  router.HTTPError
+// @Router       /products/{id}/options [put]
+func setProductOptions(c *gin.Context) {
+	var product database.Product
+	if err := database.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+
+	var input ProductOptionsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	options := make([]database.ProductOption, 0, len(input.Options))
+	names := make(map[string]bool, len(input.Options))
+	for i, optionInput := range input.Options {
+		if names[optionInput.Name] {
+			c.JSON(http.StatusBadRequest, HTTPError{Message: "Duplicate option name: " + optionInput.Name})
+			return
+		}
+		names[optionInput.Name] = true
+		options = append(options, database.ProductOption{
+			ProductID: product.ID,
+			Name:      optionInput.Name,
+			Position:  i,
+			Values:    optionInput.Values,
+		})
+	}
+
+	var before []database.ProductOption
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Find(&before).Error; err != nil {
+			return err
+		}
+
+		var variants []database.Variant
+		if err := tx.Where("product_id = ?", product.ID).Find(&variants).Error; err != nil {
+			return err
+		}
+		if err := checkVariants(options, variants); err != nil {
+			return err
+		}
+
+		if err := tx.Where("product_id = ?", product.ID).Delete(&database.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if len(options) > 0 {
+			if err := tx.Create(&options).Error; err != nil {
+				return err
+			}
+		}
+		return database.EnqueueProductUpdated(tx, product.ID)
+	})
+	if err != nil {
+		respondVariantError(c, err)
+		return
+	}
+	setAudit(c, "product.options", "product", product.ID, before, options)
+
+	c.JSON(http.StatusOK, options)
+}
+
+// @Summary      Добавить вариант товара
+// @Description  Создает вариант товара с собственными SKU, ценой и остатком. Значения опций должны соответствовать опциям товара.
+// @Tags         Товары (Products)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                  true  "ID Товара"
+// @Param        variant  body      router.VariantInput  true  "Данные варианта"
+// @Security     BearerAuth
+// @Success      201      {object}  database.Variant
+// @Failure      400      {object}  router.HTTPError  "Некорректные данные или опции"
+// @Failure      403      {object}  router.HTTPError
+// @Failure      404      {object}  router.HTTPError
+// @Failure      409      {object}  router.HTTPError  "SKU занят или вариант с такими опциями уже существует"
+// @Failure      500      {object}  router.HTTPError
+// @Router       /products/{id}/variants [post]
+func createVariant(c *gin.Context) {
+	var product database.Product
+	if err := database.DB.First(&product, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Product not found"})
+		return
+	}
+
+	var input VariantInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	variant := database.Variant{ProductID: product.ID}
+	input.apply(&variant)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveVariant(tx, &variant); err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, variant.ProductID)
+	})
+	if err != nil {
+		respondVariantError(c, err)
+		return
+	}
+	setAudit(c, "variant.create", "variant", variant.ID, nil, variant)
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+// @Summary      Обновить вариант товара
+// @Description  Полностью обновляет SKU, цену, остаток и опции варианта.
+// @Tags         Товары (Products)
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int                  true  "ID Товара"
+// @Param        variantId  path      int                  true  "ID Варианта"
+// @Param        variant    body      router.VariantInput  true  "Данные варианта"
+// @Security     BearerAuth
+// @Success      200        {object}  database.Variant
+// @Failure      400        {object}  router.HTTPError  "Некорректные данные или опции"
+// @Failure      403        {object}  router.HTTPError
+// @Failure      404        {object}  router.HTTPError
+// @Failure      409        {object}  router.HTTPError  "SKU занят или вариант с такими опциями уже существует"
+// @Failure      500        {object}  router.HTTPError
+// @Router       /products/{id}/variants/{variantId} [put]
+func updateVariant(c *gin.Context) {
+	var variant database.Variant
+	if err := database.DB.Where("product_id = ?", c.Param("id")).First(&variant, c.Param("variantId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Variant not found"})
+		return
+	}
+
+	var input VariantInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := variant
+	input.apply(&variant)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveVariant(tx, &variant); err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, variant.ProductID)
+	})
+	if err != nil {
+		respondVariantError(c, err)
+		return
+	}
+	setAudit(c, "variant.update", "variant", variant.ID, before, variant)
+
+	c.JSON(http.StatusOK, variant)
+}
+
+// @Summary      Удалить вариант товара
+// @Description  Удаляет вариант из продажи. Вариант остается доступен в истории заказов.
+// @Tags         Товары (Products)
+// @Produce      json
+// @Param        id         path      int  true  "ID Товара"
+// @Param        variantId  path      int  true  "ID Варианта"
+// @Security     BearerAuth
+// @Success      200        {object}  router.SuccessMessage
+// @Failure      403        {object}  router.HTTPError
+// @Failure      404        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /products/{id}/variants/{variantId} [delete]
+func deleteVariant(c *gin.Context) {
+	var variant database.Variant
+	if err := database.DB.Where("product_id = ?", c.Param("id")).First(&variant, c.Param("variantId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Variant not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&variant).Error; err != nil {
+			return err
+		}
+		return database.EnqueueProductUpdated(tx, variant.ProductID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete variant"})
+		return
+	}
+	setAudit(c, "variant.delete", "variant", variant.ID, variant, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Variant deleted successfully"})
+}
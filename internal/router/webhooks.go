@@ -0,0 +1,363 @@
+package router
+
+import (
+	"OnlineShop/internal/database"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type WebhookInput struct {
+	URL         string   `json:"url" binding:"required,url,max=2048" example:"https://crm.example.com/hooks/shop"`
+	Description string   `json:"description" binding:"max=255" example:"CRM"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=order.created order.status_changed product.updated product.deleted customer.registered" example:"order.created,order.status_changed"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=255" example:"b5d1c1f0e6a34a1f9c3e0d2b7a8f6e41"`
+}
+
+type WebhookDeliveryListQuery struct {
+	PaginationQuery
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded failed" example:"failed"`
+	Event  string `form:"event" example:"order.created"`
+}
+
+// WebhookWithSecret is a webhook along with its signing secret, which is only
+// shown when the webhook is created.
+type WebhookWithSecret struct {
+	database.Webhook
+	Secret string `json:"secret" example:"b5d1c1f0e6a34a1f9c3e0d2b7a8f6e41"`
+}
+
+// findWebhook loads the webhook in the path, writing the error response if
+// there is none.
+func findWebhook(c *gin.Context) (database.Webhook, bool) {
+	var webhook database.Webhook
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid webhook ID"})
+		return webhook, false
+	}
+	if err := database.DB.First(&webhook, webhookID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Webhook not found"})
+		return webhook, false
+	}
+	return webhook, true
+}
+
+// @Summary      Получить вебхуки
+// @Description  Возвращает все вебхуки интеграций с событиями, на которые они подписаны, и состоянием: автоматически отключенный
+// @Description  вебхук содержит DisabledAt и причину отключения.
+// @Tags         Вебхуки (Webhooks)
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   database.Webhook
+// @Failure      403  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /webhooks [get]
+func getWebhooks(c *gin.Context) {
+	var webhooks []database.Webhook
+	if err := database.DB.Order("id ASC").Find(&webhooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary      Получить вебхук
+// @Tags         Вебхуки (Webhooks)
+// @Produce      json
+// @Param        id   path      int  true  "ID вебхука"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Webhook
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Router       /webhooks/{id} [get]
+func getWebhook(c *gin.Context) {
+	webhook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary      Создать вебхук
+// @Description  Подписывает URL интеграции на события: order.created, order.status_changed, product.updated, product.deleted,
+// @Description  customer.registered. О каждом событии отправляется POST с JSON {id, event, created_at, data}, где data — заказ, товар
+// @Description  или покупатель в том же виде, что и в API; для product.deleted — {id, sku} окончательно удаленного товара. Перемещение
+// @Description  товара в корзину и восстановление из нее приходят как product.updated с заполненным или пустым DeletedAt.
+// @Description  Запрос подписан: X-Webhook-Signature: sha256=<hex> — HMAC-SHA256 секретом вебхука от строки
+// @Description  "<X-Webhook-Timestamp>.<тело запроса>". Ответ не 2xx считается ошибкой: доставка повторяется с экспоненциально
+// @Description  растущей паузой (не более суток) до WEBHOOK_MAX_ATTEMPTS раз, после WEBHOOK_DISABLE_AFTER ошибок подряд вебхук отключается.
+// @Description  Если secret не указан, он генерируется. Секрет возвращается только в ответе на создание.
+// @Tags         Вебхуки (Webhooks)
+// @Accept       json
+// @Produce      json
+// @Param        webhook  body      router.WebhookInput  true  "Данные вебхука"
+// @Security     BearerAuth
+// @Success      201      {object}  router.WebhookWithSecret
+// @Failure      400      {object}  router.HTTPError
+// @Failure      403      {object}  router.HTTPError
+// @Failure      500      {object}  router.HTTPError
+// @Router       /webhooks [post]
+func createWebhook(c *gin.Context) {
+	var input WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	webhook := database.Webhook{
+		URL:         input.URL,
+		Description: input.Description,
+		Events:      database.StringList(input.Events),
+		Secret:      input.Secret,
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = database.NewWebhookSecret()
+	}
+	if err := database.DB.Create(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to create webhook"})
+		return
+	}
+	setAudit(c, "webhook.create", "webhook", webhook.ID, nil, webhook)
+
+	c.JSON(http.StatusCreated, WebhookWithSecret{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// @Summary      Обновить вебхук
+// @Description  Меняет URL, описание и события вебхука. Если указан secret, следующие запросы подписываются новым секретом.
+// @Tags         Вебхуки (Webhooks)
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                  true  "ID вебхука"
+// @Param        webhook  body      router.WebhookInput  true  "Данные вебхука"
+// @Security     BearerAuth
+// @Success      200      {object}  database.Webhook
+// @Failure      400      {object}  router.HTTPError
+// @Failure      403      {object}  router.HTTPError
+// @Failure      404      {object}  router.HTTPError
+// @Failure      500      {object}  router.HTTPError
+// @Router       /webhooks/{id} [put]
+func updateWebhook(c *gin.Context) {
+	webhook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	var input WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+
+	before := webhook
+	webhook.URL = input.URL
+	webhook.Description = input.Description
+	webhook.Events = database.StringList(input.Events)
+	if input.Secret != "" {
+		webhook.Secret = input.Secret
+	}
+	if err := database.DB.Save(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update webhook"})
+		return
+	}
+	setAudit(c, "webhook.update", "webhook", webhook.ID, before, webhook)
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary      Удалить вебхук
+// @Description  Удаляет вебхук вместе с журналом его доставок. Неотправленные события ему больше не доставляются.
+// @Tags         Вебхуки (Webhooks)
+// @Produce      json
+// @Param        id   path      int  true  "ID вебхука"
+// @Security     BearerAuth
+// @Success      200  {object}  router.SuccessMessage
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /webhooks/{id} [delete]
+func deleteWebhook(c *gin.Context) {
+	webhook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&database.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&webhook).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to delete webhook"})
+		return
+	}
+	setAudit(c, "webhook.delete", "webhook", webhook.ID, webhook, nil)
+
+	c.JSON(http.StatusOK, SuccessMessage{Message: "Webhook deleted successfully"})
+}
+
+// @Summary      Включить вебхук
+// @Description  Включает отключенный вебхук и сбрасывает счетчик ошибок. Накопившиеся за время отключения доставки отправляются.
+// @Tags         Вебхуки (Webhooks)
+// @Produce      json
+// @Param        id   path      int  true  "ID вебхука"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Webhook
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /webhooks/{id}/enable [post]
+func enableWebhook(c *gin.Context) {
+	setWebhookDisabled(c, false)
+}
+
+// @Summary      Отключить вебхук
+// @Description  Приостанавливает доставку событий вебхуку. События продолжают накапливаться и будут отправлены после включения.
+// @Tags         Вебхуки (Webhooks)
+// @Produce      json
+// @Param        id   path      int  true  "ID вебхука"
+// @Security     BearerAuth
+// @Success      200  {object}  database.Webhook
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError
+// @Failure      500  {object}  router.HTTPError
+// @Router       /webhooks/{id}/disable [post]
+func disableWebhook(c *gin.Context) {
+	setWebhookDisabled(c, true)
+}
+
+func setWebhookDisabled(c *gin.Context, disabled bool) {
+	webhook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	if (webhook.DisabledAt != nil) == disabled {
+		c.JSON(http.StatusOK, webhook)
+		return
+	}
+
+	before := webhook
+	action := "webhook.enable"
+	webhook.DisabledAt = nil
+	webhook.DisabledReason = ""
+	webhook.ConsecutiveFailures = 0
+	if disabled {
+		action = "webhook.disable"
+		now := time.Now()
+		webhook.DisabledAt = &now
+		webhook.DisabledReason = "disabled manually"
+		webhook.ConsecutiveFailures = before.ConsecutiveFailures
+	}
+	err := database.DB.Model(&webhook).Select("disabled_at", "disabled_reason", "consecutive_failures").Updates(&webhook).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to update webhook"})
+		return
+	}
+	setAudit(c, action, "webhook", webhook.ID, before, webhook)
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary      Журнал доставок вебхука
+// @Description  Возвращает постраничный журнал доставок вебхука, новые первыми: тело события, число попыток, код и начало ответа,
+// @Description  последнюю ошибку и время следующей попытки.
+// @Tags         Вебхуки (Webhooks)
+// @Produce      json
+// @Param        id         path      int     true   "ID вебхука"
+// @Param        status     query     string  false  "Статус доставки"  Enums(pending, succeeded, failed)
+// @Param        event      query     string  false  "Событие"
+// @Param        page       query     int     false  "Номер страницы"  default(1)
+// @Param        page_size  query     int     false  "Размер страницы"  default(20)
+// @Security     BearerAuth
+// @Success      200        {object}  router.Page[database.WebhookDelivery]
+// @Failure      400        {object}  router.HTTPError
+// @Failure      403        {object}  router.HTTPError
+// @Failure      404        {object}  router.HTTPError
+// @Failure      500        {object}  router.HTTPError
+// @Router       /webhooks/{id}/deliveries [get]
+func getWebhookDeliveries(c *gin.Context) {
+	webhook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	var query WebhookDeliveryListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: err.Error()})
+		return
+	}
+	query.normalize()
+
+	db := database.DB.Model(&database.WebhookDelivery{}).Where("webhook_id = ?", webhook.ID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Event != "" {
+		db = db.Where("event = ?", query.Event)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch webhook deliveries"})
+		return
+	}
+	var deliveries []database.WebhookDelivery
+	err := db.Order("created_at DESC, id DESC").
+		Offset(query.offset()).Limit(query.PageSize).
+		Find(&deliveries).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to fetch webhook deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, newPage(deliveries, query.PaginationQuery, total))
+}
+
+// @Summary      Повторить доставку
+// @Description  Ставит событие доставки в очередь на повторную отправку тому же вебхуку. Создается новая доставка с тем же телом
+// @Description  и id события (по нему получатель может распознать повтор); исходная запись журнала не меняется.
+// @Tags         Вебхуки (Webhooks)
+// @Produce      json
+// @Param        id   path      int  true  "ID доставки"
+// @Security     BearerAuth
+// @Success      201  {object}  database.WebhookDelivery
+// @Failure      400  {object}  router.HTTPError
+// @Failure      403  {object}  router.HTTPError
+// @Failure      404  {object}  router.HTTPError  "Доставка не найдена"
+// @Failure      409  {object}  router.HTTPError  "Вебхук отключен"
+// @Failure      500  {object}  router.HTTPError
+// @Router       /webhook-deliveries/{id}/replay [post]
+func replayWebhookDelivery(c *gin.Context) {
+	deliveryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, HTTPError{Message: "Invalid delivery ID"})
+		return
+	}
+
+	var delivery database.WebhookDelivery
+	if err := database.DB.First(&delivery, deliveryID).Error; err != nil {
+		c.JSON(http.StatusNotFound, HTTPError{Message: "Delivery not found"})
+		return
+	}
+
+	replay, err := database.ReplayWebhookDelivery(database.DB, delivery)
+	if errors.Is(err, database.ErrWebhookDisabled) {
+		c.JSON(http.StatusConflict, HTTPError{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, HTTPError{Message: "Failed to replay delivery"})
+		return
+	}
+	setAudit(c, "webhook.replay", "webhook_delivery", replay.ID, nil, gin.H{"replay_of": delivery.ID, "event_id": delivery.EventID})
+
+	c.JSON(http.StatusCreated, replay)
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalURLPrefix is the path the router serves local files from when no
+// STORAGE_BASE_URL is configured.
+const LocalURLPrefix = "/uploads"
+
+// Local stores files in a directory on the server's disk.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocal(dir, baseURL string) (*Local, error) {
+	if baseURL == "" {
+		baseURL = LocalURLPrefix
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{dir: dir, baseURL: baseURL}, nil
+}
+
+// Dir returns the directory the files are stored in.
+func (l *Local) Dir() string {
+	return l.dir
+}
+
+// path maps the key into the storage directory. Cleaning the key as an
+// absolute path first keeps ".." from escaping it.
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put writes the file to a temporary name and renames it into place, so a
+// failed upload never leaves a truncated file behind.
+func (l *Local) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	target := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	err := os.Remove(l.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *Local) URL(key string) string {
+	return joinURL(l.baseURL, key)
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"OnlineShop/config"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Storage keeps uploaded files under slash-separated keys and knows the public
+// URL each file is served from.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// New creates the storage selected by STORAGE_DRIVER: "local" keeps files on
+// disk, "s3" sends them to an S3-compatible service such as MinIO.
+func New(ctx context.Context, cfg *config.Config) (Storage, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		return NewLocal(cfg.StorageLocalDir, cfg.StorageBaseURL)
+	case "s3":
+		return NewS3(ctx, S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+			BaseURL:   cfg.StorageBaseURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+func joinURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + key
+}
@@ -0,0 +1,207 @@
+// Package webhook delivers shop events to the webhooks of integrators. Events
+// queue deliveries in their own transaction (see database.EnqueueWebhookEvent);
+// the Dispatcher sends them as signed POST requests, retries failed ones with
+// exponential backoff and disables webhooks that keep failing.
+package webhook
+
+import (
+	"OnlineShop/internal/database"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of webhook requests. The delivery header carries the ID of the
+// delivery in the log; the ID of the event, shared by replays, is in the body.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	// retryDelay is the wait before the second attempt; every further
+	// attempt waits twice as long as the previous one, up to maxRetryDelay.
+	retryDelay    = 30 * time.Second
+	maxRetryDelay = 24 * time.Hour
+	// maxResponseBody is how much of a response is kept in the delivery log.
+	maxResponseBody = 4096
+	batchSize       = 50
+)
+
+// Sign returns the signature of a webhook request: the hex HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed with the secret of the webhook.
+// Receivers compute the same to check that the request came from the shop,
+// and reject old timestamps to protect against replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Options tune how deliveries are retried. A delivery is tried MaxAttempts
+// times; a webhook is disabled after DisableAfter failed attempts in a row,
+// whatever deliveries they belonged to.
+type Options struct {
+	Timeout      time.Duration
+	MaxAttempts  int
+	DisableAfter int
+}
+
+// Dispatcher sends due webhook deliveries.
+type Dispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+	opts   Options
+}
+
+func NewDispatcher(db *gorm.DB, opts Options) *Dispatcher {
+	return &Dispatcher{
+		db: db,
+		client: &http.Client{
+			Timeout: opts.Timeout,
+			// A redirect is a misconfigured endpoint, not a delivery.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		opts: opts,
+	}
+}
+
+// Deliver sends the deliveries that are due and returns how many succeeded.
+func (d *Dispatcher) Deliver(ctx context.Context, now time.Time) (int, error) {
+	db := d.db.WithContext(ctx)
+	due, err := database.DueWebhookDeliveries(db, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[uint]*database.Webhook)
+	succeeded := 0
+	for _, delivery := range due {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook = &database.Webhook{}
+			if err := db.First(webhook, delivery.WebhookID).Error; err != nil {
+				return succeeded, err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		// The webhook may have been disabled by an earlier delivery of the
+		// batch.
+		if webhook.DisabledAt != nil {
+			continue
+		}
+
+		status, body, err := d.send(ctx, *webhook, delivery, now)
+		if ctx.Err() != nil {
+			return succeeded, ctx.Err()
+		}
+		if err := d.record(db, webhook, delivery, status, body, err, now); err != nil {
+			return succeeded, err
+		}
+		if err == nil {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
+
+// send posts the payload of the delivery to the webhook. It returns the
+// response status and the start of the response body; any response but 2xx
+// is an error.
+func (d *Dispatcher) send(ctx context.Context, webhook database.Webhook, delivery database.WebhookDelivery, now time.Time) (*int, string, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OnlineShop-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &resp.StatusCode, string(responseBody), fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+	return &resp.StatusCode, string(responseBody), nil
+}
+
+// record stores the outcome of an attempt in the delivery log and keeps count
+// of the failures of the webhook, disabling it when they reach DisableAfter.
+func (d *Dispatcher) record(db *gorm.DB, webhook *database.Webhook, delivery database.WebhookDelivery, status *int, body string, sendErr error, now time.Time) error {
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":        attempts,
+		"response_status": status,
+		"response_body":   body,
+	}
+	webhookUpdates := map[string]interface{}{}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = database.WebhookDeliverySucceeded
+		updates["delivered_at"] = now
+		updates["last_error"] = ""
+		webhook.ConsecutiveFailures = 0
+	case attempts >= d.opts.MaxAttempts:
+		updates["status"] = database.WebhookDeliveryFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(backoff(attempts))
+		updates["last_error"] = sendErr.Error()
+	}
+	if sendErr != nil {
+		webhook.ConsecutiveFailures++
+		if webhook.ConsecutiveFailures >= d.opts.DisableAfter {
+			log.Printf("Webhook %d disabled after %d failed deliveries", webhook.ID, webhook.ConsecutiveFailures)
+			webhook.DisabledAt = &now
+			webhook.DisabledReason = fmt.Sprintf("%d consecutive failed deliveries", webhook.ConsecutiveFailures)
+			webhookUpdates["disabled_at"] = now
+			webhookUpdates["disabled_reason"] = webhook.DisabledReason
+		}
+	}
+	webhookUpdates["consecutive_failures"] = webhook.ConsecutiveFailures
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&database.Webhook{}).Where("id = ?", webhook.ID).UpdateColumns(webhookUpdates).Error
+	})
+}
+
+// backoff returns the wait after the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
@@ -3,7 +3,15 @@ package main
 import (
 	"OnlineShop/config"
 	"OnlineShop/internal/database"
+	"OnlineShop/internal/mail"
+	"OnlineShop/internal/notify"
+	"OnlineShop/internal/payment"
 	"OnlineShop/internal/router"
+	"OnlineShop/internal/scheduler"
+	"OnlineShop/internal/webhook"
+	"context"
+	"log"
+	"time"
 
 	_ "OnlineShop/docs"
 	swaggerFiles "github.com/swaggo/files"
@@ -25,10 +33,71 @@ func main() {
 	cfg := config.Load()
 
 	database.InitDB(cfg)
-	
+
+	database.SeedRoles(database.DB)
+
 	database.CreateInitialAdmin(database.DB, cfg)
 
-	r := router.SetupRouter(cfg)
+	mailer, err := mail.New(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize mailer:", err)
+	}
+	notifier := notify.New(database.DB, mailer, cfg.MailFrom, notify.Shop{
+		Name:     cfg.ShopName,
+		URL:      cfg.ShopURL,
+		Currency: cfg.ShopCurrency,
+	})
+
+	paymentProvider, err := payment.New(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize payment provider:", err)
+	}
+
+	dispatcher := webhook.NewDispatcher(database.DB, webhook.Options{
+		Timeout:      cfg.WebhookTimeout,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		DisableAfter: cfg.WebhookDisableAfter,
+	})
+
+	scheduler.Start(context.Background(), scheduler.Job{
+		Name:     "scheduled prices",
+		Interval: cfg.PriceSchedulerInterval,
+		Run: func(ctx context.Context) error {
+			_, err := database.ApplyScheduledPrices(database.DB.WithContext(ctx), time.Now())
+			return err
+		},
+	}, scheduler.Job{
+		Name:     "expired idempotency keys",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			_, err := database.DeleteExpiredIdempotencyKeys(database.DB.WithContext(ctx), time.Now())
+			return err
+		},
+	}, scheduler.Job{
+		Name:     "pending refunds",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			// Refunds still being sent by a request are left alone.
+			_, err := payment.RetryRefunds(ctx, database.DB, paymentProvider, time.Now().Add(-5*time.Minute))
+			return err
+		},
+	}, scheduler.Job{
+		Name:     "notifications",
+		Interval: cfg.NotificationInterval,
+		Run: func(ctx context.Context) error {
+			_, err := notifier.Deliver(ctx, time.Now())
+			return err
+		},
+	}, scheduler.Job{
+		Name:     "webhooks",
+		Interval: cfg.WebhookInterval,
+		Run: func(ctx context.Context) error {
+			_, err := dispatcher.Deliver(ctx, time.Now())
+			return err
+		},
+	})
+
+	r := router.SetupRouter(cfg, paymentProvider)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 